@@ -9,9 +9,12 @@ import (
 	"time"
 
 	"github.com/synergyvets/platform/internal/auth"
+	"github.com/synergyvets/platform/internal/clientip"
 	"github.com/synergyvets/platform/internal/config"
 	"github.com/synergyvets/platform/internal/db"
 	"github.com/synergyvets/platform/internal/logging"
+	"github.com/synergyvets/platform/internal/mailer"
+	"github.com/synergyvets/platform/internal/oidc"
 	"github.com/synergyvets/platform/internal/public/jobs"
 	"github.com/synergyvets/platform/internal/server"
 	"github.com/synergyvets/platform/internal/store"
@@ -38,14 +41,26 @@ func main() {
 
 	store := store.New(dbConn)
 	authLogger := logger.With().Str("module", "auth").Logger()
-	authService := auth.NewService(store, authLogger, cfg.AuthConfig())
-	authHandler := auth.NewHandler(authService)
+	mailLogger := logger.With().Str("module", "mailer").Logger()
+	mail, err := mailer.New(cfg.MailerConfig(), mailLogger)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("mailer configuration failed")
+	}
+	identityProviders := make([]*oidc.Provider, 0, len(cfg.OIDCProviders))
+	for _, providerCfg := range cfg.OIDCProviders {
+		identityProviders = append(identityProviders, oidc.NewProvider(providerCfg, nil))
+	}
+	authService := auth.NewService(store, authLogger, cfg.AuthConfig()).
+		WithMailer(mail).
+		WithOIDCProviders(identityProviders...)
+	authHandler := auth.NewHandler(authService).WithCookies(cfg.AuthCookieConfig())
 	publicJobsService := jobs.NewService(store)
 	publicJobsHandler := jobs.NewHandler(publicJobsService)
 	srv := server.New(server.Config{
 		Addr:           cfg.HTTPAddr,
 		AllowedOrigins: cfg.AllowedOrigins,
 		Logger:         logger,
+		ClientIP:       clientip.NewResolver(cfg.TrustedProxies, cfg.TrustedProxyHeader),
 		AuthHandler:    authHandler,
 		PublicJobs:     publicJobsHandler,
 	})
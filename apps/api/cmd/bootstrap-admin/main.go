@@ -0,0 +1,60 @@
+// Command bootstrap-admin creates the first staff admin account. Further staff are invited
+// from the staff portal. The password is read from BOOTSTRAP_ADMIN_PASSWORD so it does not
+// end up in shell history or the process list.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/synergyvets/platform/internal/auth"
+	"github.com/synergyvets/platform/internal/config"
+	appdb "github.com/synergyvets/platform/internal/db"
+	"github.com/synergyvets/platform/internal/logging"
+	"github.com/synergyvets/platform/internal/store"
+)
+
+func main() {
+	email := flag.String("email", "", "email address of the first staff admin")
+	flag.Parse()
+
+	cfg := config.Load()
+	logger := logging.New(cfg.LoggingConfig()).With().Str("component", "bootstrap-admin").Logger()
+
+	password := os.Getenv("BOOTSTRAP_ADMIN_PASSWORD")
+	if *email == "" || password == "" {
+		logger.Error().Msg("usage: BOOTSTRAP_ADMIN_PASSWORD=... bootstrap-admin -email admin@example.com")
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	db, err := appdb.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		logger.Error().Err(err).Msg("database connection failed")
+		os.Exit(1)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			logger.Warn().Err(err).Msg("error closing database")
+		}
+	}()
+
+	service := auth.NewService(store.New(db), logger, cfg.AuthConfig())
+	user, err := service.BootstrapAdmin(ctx, *email, password)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrAdminExists):
+			logger.Error().Msg("a staff admin already exists; invite further staff from the staff portal")
+		default:
+			logger.Error().Err(err).Msg("failed to create staff admin")
+		}
+		os.Exit(1)
+	}
+
+	logger.Info().Str("user_id", user.ID.String()).Str("email", user.Email).Msg("staff admin created")
+}
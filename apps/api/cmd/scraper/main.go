@@ -14,6 +14,7 @@ import (
 
 	"github.com/synergyvets/platform/internal/config"
 	"github.com/synergyvets/platform/internal/db"
+	"github.com/synergyvets/platform/internal/geo"
 	"github.com/synergyvets/platform/internal/queries"
 )
 
@@ -30,6 +31,11 @@ func main() {
 
 	q := queries.New(database)
 
+	// Locations stored before geocoding, or whose names the gazetteer has since learned.
+	if err := geocodeLocations(ctx, q); err != nil {
+		log.Printf("Failed to geocode locations: %v", err)
+	}
+
 	// Scrape pages
 	// Loop until we find no jobs on a page or hit a safety limit
 	for i := 1; i <= 50; i++ {
@@ -174,11 +180,17 @@ func scrapePage(ctx context.Context, db *sql.DB, q *queries.Queries, url string)
 		}
 
 		// Insert Location
+		var latitude, longitude sql.NullFloat64
+		if place, ok := placeLocation(locationName); ok {
+			latitude = sql.NullFloat64{Float64: place.Latitude, Valid: true}
+			longitude = sql.NullFloat64{Float64: place.Longitude, Valid: true}
+		}
+
 		var locationID int64
 		err = db.QueryRowContext(ctx, `
-			INSERT INTO job_locations (country, city) 
-			VALUES ($1, $2) 
-			RETURNING id`, "United Kingdom", locationName).Scan(&locationID)
+			INSERT INTO job_locations (country, city, latitude, longitude) 
+			VALUES ($1, $2, $3, $4) 
+			RETURNING id`, "United Kingdom", locationName, latitude, longitude).Scan(&locationID)
 
 		if err != nil {
 			log.Printf("Failed to insert location: %v", err)
@@ -211,6 +223,57 @@ func scrapePage(ctx context.Context, db *sql.DB, q *queries.Queries, url string)
 	return len(matches), nil
 }
 
+// geocodeLocations fills in coordinates for job locations the gazetteer can place.
+func geocodeLocations(ctx context.Context, q *queries.Queries) error {
+	locations, err := q.ListJobLocationsMissingCoordinates(ctx)
+	if err != nil {
+		return err
+	}
+
+	geocoded := 0
+	for _, location := range locations {
+		place, ok := lookupLocation(location)
+		if !ok {
+			continue
+		}
+		if err := q.SetJobLocationCoordinates(ctx, queries.SetJobLocationCoordinatesParams{
+			Latitude:  place.Latitude,
+			Longitude: place.Longitude,
+			ID:        location.ID,
+		}); err != nil {
+			return err
+		}
+		geocoded++
+	}
+
+	log.Printf("Geocoded %d of %d locations without coordinates", geocoded, len(locations))
+	return nil
+}
+
+// lookupLocation tries the most specific part of a location first.
+func lookupLocation(location queries.ListJobLocationsMissingCoordinatesRow) (geo.Place, bool) {
+	for _, name := range []sql.NullString{location.City, location.Region} {
+		if !name.Valid {
+			continue
+		}
+		if place, ok := placeLocation(name.String); ok {
+			return place, true
+		}
+	}
+	return geo.Place{}, false
+}
+
+// placeLocation looks a location name up in the gazetteer, skipping postcode areas: a postcode
+// only resolves to the centre of its area, which can be tens of kilometres from the job, and a
+// location without coordinates is better than one that radius searches misplace.
+func placeLocation(name string) (geo.Place, bool) {
+	place, ok := geo.UK().Lookup(name)
+	if !ok || place.Kind == geo.KindPostcodeArea {
+		return geo.Place{}, false
+	}
+	return place, true
+}
+
 type JobDetails struct {
 	Description string
 	SalaryMin   int32
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Refresh tokens rotate within a family; replaying a consumed token revokes the whole family.
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS family_id UUID;
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS consumed_at TIMESTAMPTZ;
+
+UPDATE user_sessions SET family_id = id WHERE family_id IS NULL;
+
+ALTER TABLE user_sessions ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_family_id ON user_sessions(family_id);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_refresh_token_hash ON user_sessions(refresh_token_hash);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_sessions_refresh_token_hash;
+DROP INDEX IF EXISTS idx_user_sessions_family_id;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS consumed_at;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS family_id;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Replace the coarse staff/admin roles with the roles from the access model.
+UPDATE users SET role = 'staff_editor' WHERE role = 'staff';
+UPDATE users SET role = 'staff_admin' WHERE role = 'admin';
+
+ALTER TABLE users
+    ADD CONSTRAINT users_role_check CHECK (role IN ('seeker', 'staff_editor', 'staff_admin'));
+
+-- +goose Down
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+
+UPDATE users SET role = 'admin' WHERE role = 'staff_admin';
+UPDATE users SET role = 'staff' WHERE role = 'staff_editor';
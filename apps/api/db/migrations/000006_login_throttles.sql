@@ -0,0 +1,13 @@
+-- +goose Up
+-- Failed login counters shared across API replicas, keyed per account (email) and per client IP.
+CREATE TABLE IF NOT EXISTS login_throttles (
+    scope TEXT NOT NULL,
+    key TEXT NOT NULL,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ,
+    PRIMARY KEY (scope, key)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS login_throttles;
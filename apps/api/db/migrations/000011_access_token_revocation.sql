@@ -0,0 +1,25 @@
+-- +goose Up
+-- Access tokens are stateless JWTs, so revocation is checked against an in-memory cache built
+-- from these tables. Tokens issued before users.tokens_valid_after are rejected, and individual
+-- tokens are denylisted by jti until they would have expired anyway.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tokens_valid_after TIMESTAMPTZ;
+
+-- The jti of the access token issued alongside each refresh token, so signing out a session can
+-- revoke its outstanding access tokens.
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS access_token_jti UUID;
+
+CREATE TABLE IF NOT EXISTS revoked_access_tokens (
+    jti UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_revoked_access_tokens_expires_at ON revoked_access_tokens(expires_at);
+CREATE INDEX IF NOT EXISTS idx_users_tokens_valid_after ON users(tokens_valid_after) WHERE tokens_valid_after IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_tokens_valid_after;
+DROP TABLE IF EXISTS revoked_access_tokens;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS access_token_jti;
+ALTER TABLE users DROP COLUMN IF EXISTS tokens_valid_after;
@@ -0,0 +1,42 @@
+-- +goose Up
+-- Staff accounts are only created through invitations: an admin invites an address with a
+-- pre-assigned role and the recipient sets a password when accepting. Only a hash of the
+-- emailed token is stored.
+CREATE TABLE IF NOT EXISTS staff_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email CITEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('staff_editor', 'staff_admin')),
+    token_hash TEXT NOT NULL UNIQUE,
+    -- NULL once the inviter is deleted.
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    accepted_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- At most one open invitation per address; inviting again revokes the previous one.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_staff_invitations_open_email
+    ON staff_invitations(email)
+    WHERE accepted_at IS NULL AND revoked_at IS NULL;
+
+-- Append-only record of privileged actions. Actor and target are plain identifiers rather than
+-- foreign keys so the history survives the users involved being deleted.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    actor_id UUID,
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id UUID,
+    metadata JSONB NOT NULL DEFAULT '{}',
+    ip INET,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_target ON audit_events(target_type, target_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS staff_invitations;
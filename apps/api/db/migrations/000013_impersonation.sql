@@ -0,0 +1,24 @@
+-- +goose Up
+-- Administrators can view the product as a candidate with a short-lived, read-only access
+-- token. Each impersonation is recorded with its start and stop times; ended_at stays NULL when
+-- the token simply expired. Actor and target are plain identifiers, like audit_events, so the
+-- record outlives the accounts.
+CREATE TABLE IF NOT EXISTS impersonation_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    actor_id UUID NOT NULL,
+    target_user_id UUID NOT NULL,
+    reason TEXT NOT NULL,
+    -- jti of the issued access token, denylisted when the impersonation is stopped early.
+    jti UUID NOT NULL UNIQUE,
+    ip INET,
+    user_agent TEXT,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_impersonation_sessions_started_at ON impersonation_sessions(started_at);
+CREATE INDEX IF NOT EXISTS idx_impersonation_sessions_target_user_id ON impersonation_sessions(target_user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS impersonation_sessions;
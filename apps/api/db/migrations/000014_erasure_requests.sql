@@ -0,0 +1,39 @@
+-- +goose Up
+-- Erasing a candidate deletes their users row. Applications are kept without an owner, with
+-- their free-text fields cleared, so per-job application counts and status history survive.
+ALTER TABLE job_applications ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE job_applications DROP CONSTRAINT IF EXISTS job_applications_user_id_fkey;
+ALTER TABLE job_applications
+    ADD CONSTRAINT job_applications_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
+
+-- Right-to-erasure requests awaiting staff review. due_at is the statutory deadline for
+-- answering the request. user_id is a plain identifier so the record of a completed erasure
+-- outlives the account it removed.
+CREATE TABLE IF NOT EXISTS erasure_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'rejected')),
+    reason TEXT,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    due_at TIMESTAMPTZ NOT NULL,
+    reviewed_by UUID,
+    review_note TEXT,
+    reviewed_at TIMESTAMPTZ
+);
+
+-- At most one pending request per user.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_erasure_requests_pending_user_id
+    ON erasure_requests(user_id)
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_erasure_requests_status_due_at ON erasure_requests(status, due_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS erasure_requests;
+
+DELETE FROM job_applications WHERE user_id IS NULL;
+ALTER TABLE job_applications DROP CONSTRAINT IF EXISTS job_applications_user_id_fkey;
+ALTER TABLE job_applications
+    ADD CONSTRAINT job_applications_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE job_applications ALTER COLUMN user_id SET NOT NULL;
@@ -0,0 +1,53 @@
+-- +goose Up
+-- Weighted full-text document for public job search: title (A) > summary (B) > description (C)
+-- > location (D). The location lives in job_locations, so the column is kept up to date by
+-- triggers rather than being a generated column.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION jobs_search_vector_refresh() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', COALESCE(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(NEW.summary, '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(NEW.description, '')), 'C') ||
+        setweight(to_tsvector('english', COALESCE((
+            SELECT concat_ws(' ', jl.city, jl.region, jl.country)
+            FROM job_locations jl
+            WHERE jl.id = NEW.location_id
+        ), '')), 'D');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER jobs_search_vector_refresh
+    BEFORE INSERT OR UPDATE OF title, summary, description, location_id ON jobs
+    FOR EACH ROW EXECUTE FUNCTION jobs_search_vector_refresh();
+
+-- Renaming a location re-indexes the jobs at it; listing location_id in the SET clause is
+-- enough to fire the jobs trigger.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION job_locations_search_vector_refresh() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE jobs SET location_id = location_id WHERE location_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER job_locations_search_vector_refresh
+    AFTER UPDATE OF country, region, city ON job_locations
+    FOR EACH ROW EXECUTE FUNCTION job_locations_search_vector_refresh();
+
+UPDATE jobs SET title = title;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_search_vector ON jobs USING GIN (search_vector);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_search_vector;
+DROP TRIGGER IF EXISTS job_locations_search_vector_refresh ON job_locations;
+DROP FUNCTION IF EXISTS job_locations_search_vector_refresh();
+DROP TRIGGER IF EXISTS jobs_search_vector_refresh ON jobs;
+DROP FUNCTION IF EXISTS jobs_search_vector_refresh();
+ALTER TABLE jobs DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Authentication methods used when a session family was signed in, carried through refresh
+-- token rotation so access tokens only claim a second factor the family actually proved.
+-- Existing sessions are treated as password-only, so MFA users sign in again to regain "otp".
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS amr TEXT[] NOT NULL DEFAULT '{pwd}';
+
+-- +goose Down
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS amr;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Published jobs in the default newest-first order of ListPublishedJobs. The first expression
+-- matches its sort_posted_at.
+CREATE INDEX IF NOT EXISTS idx_jobs_published_newest
+    ON jobs ((COALESCE(posted_at, '-infinity'::timestamptz)), created_at, id)
+    WHERE status = 'published';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_published_newest;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Authentication methods of the first factor an MFA challenge follows, so the session it
+-- completes records how the user actually signed in rather than assuming a password.
+ALTER TABLE mfa_challenges ADD COLUMN IF NOT EXISTS amr TEXT[] NOT NULL DEFAULT '{pwd}';
+
+-- +goose Down
+ALTER TABLE mfa_challenges DROP COLUMN IF EXISTS amr;
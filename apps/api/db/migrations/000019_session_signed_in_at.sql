@@ -0,0 +1,19 @@
+-- +goose Up
+-- When a session family was signed in, carried through refresh token rotation. It used to be
+-- read from the family's oldest row, which kept every consumed row alive; storing it lets
+-- rotation prune consumed rows whose refresh token has expired.
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS signed_in_at TIMESTAMPTZ;
+
+UPDATE user_sessions s
+SET signed_in_at = (
+    SELECT MIN(f.created_at)
+    FROM user_sessions f
+    WHERE f.family_id = s.family_id
+)
+WHERE signed_in_at IS NULL;
+
+ALTER TABLE user_sessions ALTER COLUMN signed_in_at SET DEFAULT NOW();
+ALTER TABLE user_sessions ALTER COLUMN signed_in_at SET NOT NULL;
+
+-- +goose Down
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS signed_in_at;
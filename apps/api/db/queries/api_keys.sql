@@ -0,0 +1,35 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+    name,
+    prefix,
+    secret_hash,
+    scopes,
+    created_by,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+RETURNING id, name, prefix, secret_hash, scopes, created_by, expires_at, last_used_at, revoked_at, created_at;
+
+-- name: GetAPIKeyByPrefix :one
+SELECT id, name, prefix, secret_hash, scopes, created_by, expires_at, last_used_at, revoked_at, created_at
+FROM api_keys
+WHERE prefix = $1
+LIMIT 1;
+
+-- name: ListAPIKeys :many
+SELECT id, name, prefix, secret_hash, scopes, created_by, expires_at, last_used_at, revoked_at, created_at
+FROM api_keys
+ORDER BY created_at DESC;
+
+-- name: RevokeAPIKey :execrows
+UPDATE api_keys
+SET revoked_at = NOW()
+WHERE id = $1
+  AND revoked_at IS NULL;
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = sqlc.arg(used_at)
+WHERE id = sqlc.arg(id)
+  AND (last_used_at IS NULL OR last_used_at < sqlc.arg(stale_before));
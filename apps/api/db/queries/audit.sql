@@ -0,0 +1,13 @@
+-- Audit trail queries
+
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (
+    actor_id,
+    action,
+    target_type,
+    target_id,
+    metadata,
+    ip
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+);
@@ -6,15 +6,23 @@ INSERT INTO user_sessions (
     refresh_token_hash,
     user_agent,
     ip,
-    expires_at
+    expires_at,
+    family_id,
+    access_token_jti,
+    amr,
+    signed_in_at
 ) VALUES (
     $1,
     $2,
     $3,
     $4,
-    $5
+    $5,
+    $6,
+    $7,
+    $8,
+    $9
 )
-RETURNING id, user_id, refresh_token_hash, user_agent, ip, expires_at, created_at;
+RETURNING id, user_id, refresh_token_hash, user_agent, ip, expires_at, created_at, family_id, consumed_at, access_token_jti, amr, signed_in_at;
 
 -- name: DeleteUserSession :exec
 DELETE FROM user_sessions
@@ -25,7 +33,7 @@ DELETE FROM user_sessions
 WHERE user_id = $1;
 
 -- name: GetUserSessionByHash :one
-SELECT id, user_id, refresh_token_hash, user_agent, ip, expires_at, created_at
+SELECT id, user_id, refresh_token_hash, user_agent, ip, expires_at, created_at, family_id, consumed_at, access_token_jti, amr, signed_in_at
 FROM user_sessions
 WHERE refresh_token_hash = $1
 LIMIT 1;
@@ -33,3 +41,159 @@ LIMIT 1;
 -- name: DeleteUserSessionByHash :exec
 DELETE FROM user_sessions
 WHERE refresh_token_hash = $1;
+
+-- name: ConsumeUserSession :execrows
+UPDATE user_sessions
+SET consumed_at = NOW()
+WHERE id = $1
+  AND consumed_at IS NULL;
+
+-- name: DeleteUserSessionsByFamilyID :exec
+DELETE FROM user_sessions
+WHERE family_id = $1;
+
+-- name: DeleteExpiredConsumedUserSessions :exec
+-- Consumed rows are kept so replaying a rotated token is detected; once the token has expired
+-- they are no longer needed.
+DELETE FROM user_sessions
+WHERE family_id = sqlc.arg(family_id)
+  AND consumed_at IS NOT NULL
+  AND expires_at < sqlc.arg(now);
+
+-- name: CreateEmailVerificationToken :one
+INSERT INTO email_verification_tokens (
+    user_id,
+    email,
+    token_hash,
+    expires_at
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING id, user_id, email, token_hash, expires_at, consumed_at, created_at;
+
+-- name: GetEmailVerificationTokenByHash :one
+SELECT id, user_id, email, token_hash, expires_at, consumed_at, created_at
+FROM email_verification_tokens
+WHERE token_hash = $1
+LIMIT 1;
+
+-- name: ConsumeEmailVerificationToken :execrows
+UPDATE email_verification_tokens
+SET consumed_at = NOW()
+WHERE id = $1
+  AND consumed_at IS NULL;
+
+-- name: GetPendingEmailChange :one
+SELECT t.email
+FROM email_verification_tokens t
+JOIN users u ON u.id = t.user_id
+WHERE t.user_id = sqlc.arg(user_id)
+  AND t.consumed_at IS NULL
+  AND t.expires_at > sqlc.arg(now)
+  AND t.email <> u.email
+ORDER BY t.created_at DESC
+LIMIT 1;
+
+-- name: DeletePendingEmailVerificationTokens :exec
+DELETE FROM email_verification_tokens
+WHERE user_id = $1
+  AND consumed_at IS NULL;
+
+-- name: CreatePasswordResetToken :one
+INSERT INTO password_reset_tokens (
+    user_id,
+    token_hash,
+    expires_at
+) VALUES (
+    $1,
+    $2,
+    $3
+)
+RETURNING id, user_id, token_hash, expires_at, consumed_at, created_at;
+
+-- name: GetPasswordResetTokenByHash :one
+SELECT id, user_id, token_hash, expires_at, consumed_at, created_at
+FROM password_reset_tokens
+WHERE token_hash = $1
+LIMIT 1;
+
+-- name: ConsumePasswordResetToken :execrows
+UPDATE password_reset_tokens
+SET consumed_at = NOW()
+WHERE id = $1
+  AND consumed_at IS NULL;
+
+-- name: DeletePendingPasswordResetTokens :exec
+DELETE FROM password_reset_tokens
+WHERE user_id = $1
+  AND consumed_at IS NULL;
+
+-- name: ListActiveUserSessions :many
+SELECT
+    s.id,
+    s.family_id,
+    s.user_agent,
+    s.ip,
+    s.expires_at,
+    s.created_at AS last_used_at,
+    s.signed_in_at
+FROM user_sessions s
+WHERE s.user_id = $1
+  AND s.consumed_at IS NULL
+  AND s.expires_at > NOW()
+ORDER BY s.created_at DESC;
+
+-- name: GetUserSessionFamilySignedInAt :one
+-- Every row of a family carries the family's sign-in time.
+SELECT signed_in_at
+FROM user_sessions
+WHERE family_id = sqlc.arg(family_id)
+  AND user_id = sqlc.arg(user_id)
+LIMIT 1;
+
+-- name: DeleteUserSessionFamilyForUser :execrows
+DELETE FROM user_sessions
+WHERE family_id = sqlc.arg(family_id)
+  AND user_id = sqlc.arg(user_id);
+
+-- name: DeleteOtherUserSessions :exec
+DELETE FROM user_sessions
+WHERE user_id = sqlc.arg(user_id)
+  AND family_id <> sqlc.arg(keep_family_id);
+
+-- name: CreateMagicLinkToken :one
+INSERT INTO magic_link_tokens (
+    user_id,
+    token_hash,
+    user_agent_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING id, user_id, token_hash, user_agent_hash, expires_at, consumed_at, created_at;
+
+-- name: GetMagicLinkTokenByHash :one
+SELECT id, user_id, token_hash, user_agent_hash, expires_at, consumed_at, created_at
+FROM magic_link_tokens
+WHERE token_hash = $1
+LIMIT 1;
+
+-- name: ConsumeMagicLinkToken :execrows
+UPDATE magic_link_tokens
+SET consumed_at = NOW()
+WHERE id = $1
+  AND consumed_at IS NULL;
+
+-- name: CountMagicLinkTokensSince :one
+SELECT COUNT(*)
+FROM magic_link_tokens
+WHERE user_id = sqlc.arg(user_id)
+  AND created_at >= sqlc.arg(since);
+
+-- name: DeletePendingMagicLinkTokens :exec
+DELETE FROM magic_link_tokens
+WHERE user_id = $1
+  AND consumed_at IS NULL;
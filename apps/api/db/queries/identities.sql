@@ -0,0 +1,44 @@
+-- name: CreateOIDCAuthRequest :exec
+INSERT INTO oidc_auth_requests (
+    provider,
+    state_hash,
+    nonce,
+    code_verifier,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: TakeOIDCAuthRequest :one
+DELETE FROM oidc_auth_requests
+WHERE state_hash = $1
+RETURNING id, provider, state_hash, nonce, code_verifier, expires_at, created_at;
+
+-- name: DeleteExpiredOIDCAuthRequests :exec
+DELETE FROM oidc_auth_requests
+WHERE expires_at < $1;
+
+-- name: GetUserIdentity :one
+SELECT id, user_id, provider, subject, email, last_login_at, created_at
+FROM user_identities
+WHERE provider = $1
+  AND subject = $2
+LIMIT 1;
+
+-- name: CreateUserIdentity :one
+INSERT INTO user_identities (
+    user_id,
+    provider,
+    subject,
+    email,
+    last_login_at
+) VALUES (
+    $1, $2, $3, $4, NOW()
+)
+RETURNING id, user_id, provider, subject, email, last_login_at, created_at;
+
+-- name: TouchUserIdentity :exec
+UPDATE user_identities
+SET email = $2,
+    last_login_at = NOW()
+WHERE id = $1;
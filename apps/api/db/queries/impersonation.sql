@@ -0,0 +1,28 @@
+-- Impersonation queries
+
+-- name: CreateImpersonationSession :one
+INSERT INTO impersonation_sessions (
+    actor_id,
+    target_user_id,
+    reason,
+    jti,
+    ip,
+    user_agent,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING id, actor_id, target_user_id, reason, jti, ip, user_agent, started_at, expires_at, ended_at;
+
+-- name: EndImpersonationSession :one
+UPDATE impersonation_sessions
+SET ended_at = NOW()
+WHERE id = $1
+  AND ended_at IS NULL
+  AND expires_at > NOW()
+RETURNING id, actor_id, target_user_id, reason, jti, ip, user_agent, started_at, expires_at, ended_at;
+
+-- name: ListImpersonationSessions :many
+SELECT id, actor_id, target_user_id, reason, jti, ip, user_agent, started_at, expires_at, ended_at
+FROM impersonation_sessions
+ORDER BY started_at DESC;
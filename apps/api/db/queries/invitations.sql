@@ -0,0 +1,46 @@
+-- Staff invitation queries
+
+-- name: AcceptStaffInvitation :execrows
+UPDATE staff_invitations
+SET accepted_at = NOW(),
+    accepted_user_id = sqlc.arg(accepted_user_id)
+WHERE id = sqlc.arg(id)
+  AND accepted_at IS NULL
+  AND revoked_at IS NULL;
+
+-- name: CreateStaffInvitation :one
+INSERT INTO staff_invitations (
+    email,
+    role,
+    token_hash,
+    invited_by,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING id, email, role, token_hash, invited_by, expires_at, accepted_at, accepted_user_id, revoked_at, created_at;
+
+-- name: GetStaffInvitationByHash :one
+SELECT id, email, role, token_hash, invited_by, expires_at, accepted_at, accepted_user_id, revoked_at, created_at
+FROM staff_invitations
+WHERE token_hash = $1
+LIMIT 1;
+
+-- name: ListStaffInvitations :many
+SELECT id, email, role, token_hash, invited_by, expires_at, accepted_at, accepted_user_id, revoked_at, created_at
+FROM staff_invitations
+ORDER BY created_at DESC;
+
+-- name: RevokeOpenStaffInvitations :exec
+UPDATE staff_invitations
+SET revoked_at = NOW()
+WHERE email = $1
+  AND accepted_at IS NULL
+  AND revoked_at IS NULL;
+
+-- name: RevokeStaffInvitation :execrows
+UPDATE staff_invitations
+SET revoked_at = NOW()
+WHERE id = $1
+  AND accepted_at IS NULL
+  AND revoked_at IS NULL;
@@ -35,38 +35,233 @@ INSERT INTO jobs (
 RETURNING *;
 
 -- name: ListPublishedJobs :many
+-- Jobs are ordered by (sort_value, posted_at, created_at, id), all descending, so a page can
+-- start after (or, with backward set, before) the cursor row. sort_value depends on the sort:
+-- the search relevance, the salary (negated when ascending), the negated distance from the
+-- near point, or 0 when sorting by date; jobs without a salary or location sort last at
+-- -infinity. CountPublishedJobs counts the same matches when a total is asked for.
+WITH search AS (
+    SELECT websearch_to_tsquery('english', sqlc.narg('search')::text) AS query
+), matches AS (
+    SELECT
+        j.id,
+        j.title,
+        j.slug,
+        j.summary,
+        j.description,
+        j.location_id,
+        j.contract_type,
+        j.work_pattern,
+        j.salary_min,
+        j.salary_max,
+        j.currency,
+        j.status,
+        j.source,
+        j.source_ref,
+        j.posted_at,
+        j.expires_at,
+        j.created_at,
+        j.updated_at,
+        jl.country,
+        jl.region,
+        jl.city,
+        d.distance_km,
+        COALESCE(CASE sqlc.arg('sort')::text
+            WHEN 'relevance' THEN COALESCE(ts_rank_cd(j.search_vector, s.query), 0)::float8
+            WHEN 'salary_desc' THEN COALESCE(j.salary_max, j.salary_min)::float8
+            WHEN 'salary_asc' THEN -COALESCE(j.salary_min, j.salary_max)::float8
+            WHEN 'distance' THEN -d.distance_km
+            ELSE 0
+        END, '-infinity'::float8) AS sort_value,
+        COALESCE(j.posted_at, '-infinity'::timestamptz) AS sort_posted_at
+    FROM jobs j
+    LEFT JOIN job_locations jl ON jl.id = j.location_id
+    -- Great-circle distance in km by the haversine formula; NULL without a near point or
+    -- without coordinates for the job's location.
+    LEFT JOIN LATERAL (
+        SELECT 2 * 6371 * asin(LEAST(1, sqrt(
+            power(sin(radians(jl.latitude::float8 - sqlc.narg('near_latitude')::float8) / 2), 2)
+            + cos(radians(sqlc.narg('near_latitude')::float8)) * cos(radians(jl.latitude::float8))
+            * power(sin(radians(jl.longitude::float8 - sqlc.narg('near_longitude')::float8) / 2), 2)
+        ))) AS distance_km
+    ) d ON true
+    CROSS JOIN search s
+    WHERE j.status = 'published'
+      AND (
+            s.query IS NULL
+            OR numnode(s.query) = 0
+            OR j.search_vector @@ s.query
+        )
+      AND (
+            sqlc.narg('countries')::text[] IS NULL
+            OR EXISTS (
+                SELECT 1
+                FROM unnest(sqlc.narg('countries')::text[]) AS value
+                WHERE jl.country ILIKE '%' || value || '%'
+            )
+        )
+      AND (
+            sqlc.narg('regions')::text[] IS NULL
+            OR EXISTS (
+                SELECT 1
+                FROM unnest(sqlc.narg('regions')::text[]) AS value
+                WHERE jl.region ILIKE '%' || value || '%'
+                OR jl.city ILIKE '%' || value || '%'
+            )
+        )
+      AND (
+            sqlc.narg('contract_types')::text[] IS NULL
+            OR j.contract_type = ANY(sqlc.narg('contract_types')::text[])
+        )
+      AND (
+            sqlc.narg('categories')::text[] IS NULL
+            OR EXISTS (
+                SELECT 1
+                FROM unnest(sqlc.narg('categories')::text[]) AS cat
+                WHERE (
+                    cat = 'Vet' AND (j.title ILIKE '%Vet%' OR j.title ILIKE '%Surgeon%') AND j.title NOT ILIKE '%Nurse%'
+                ) OR (
+                    cat = 'Nurse' AND (j.title ILIKE '%Nurse%' OR j.title ILIKE '%RVN%' OR j.title ILIKE '%SVN%')
+                )
+            )
+        )
+      AND (
+            sqlc.narg('work_patterns')::text[] IS NULL
+            OR j.work_pattern = ANY(sqlc.narg('work_patterns')::text[])
+        )
+      -- Jobs without a currency are imported UK listings quoted in pounds.
+      AND (
+            sqlc.narg('currency')::text IS NULL
+            OR COALESCE(j.currency, 'GBP') = sqlc.narg('currency')::text
+        )
+      -- Salary bands match when they overlap the requested range.
+      AND (
+            sqlc.narg('salary_min')::integer IS NULL
+            OR COALESCE(j.salary_max, j.salary_min) >= sqlc.narg('salary_min')::integer
+        )
+      AND (
+            sqlc.narg('salary_max')::integer IS NULL
+            OR COALESCE(j.salary_min, j.salary_max) <= sqlc.narg('salary_max')::integer
+        )
+      AND (
+            sqlc.narg('posted_after')::timestamptz IS NULL
+            OR j.posted_at >= sqlc.narg('posted_after')::timestamptz
+        )
+      AND (
+            sqlc.narg('radius_km')::float8 IS NULL
+            OR d.distance_km <= sqlc.narg('radius_km')::float8
+        )
+)
 SELECT
-    j.id,
-    j.title,
-    j.slug,
-    j.summary,
-    j.description,
-    j.location_id,
-    j.contract_type,
-    j.work_pattern,
-    j.salary_min,
-    j.salary_max,
-    j.currency,
-    j.status,
-    j.source,
-    j.source_ref,
-    j.posted_at,
-    j.expires_at,
-    j.created_at,
-    j.updated_at,
-    jl.country,
-    jl.region,
-    jl.city,
-    COUNT(*) OVER() AS total_count
+    m.id,
+    m.title,
+    m.slug,
+    m.summary,
+    m.description,
+    m.location_id,
+    m.contract_type,
+    m.work_pattern,
+    m.salary_min,
+    m.salary_max,
+    m.currency,
+    m.status,
+    m.source,
+    m.source_ref,
+    m.posted_at,
+    m.expires_at,
+    m.created_at,
+    m.updated_at,
+    m.country,
+    m.region,
+    m.city,
+    -- Matches are delimited by the private-use characters U+E000 and U+E001, stripped from the
+    -- text beforehand, and the caller swaps them for tags after HTML-escaping the headline.
+    ts_headline('english', translate(m.title, E'\uE000\uE001', ''), s.query,
+        E'HighlightAll=true, StartSel=\uE000, StopSel=\uE001') AS title_highlight,
+    ts_headline('english', translate(concat_ws(' ', m.summary, regexp_replace(m.description, '<[^>]*>', ' ', 'g')), E'\uE000\uE001', ''), s.query,
+        E'StartSel=\uE000, StopSel=\uE001, MaxFragments=2, MaxWords=30, MinWords=10, FragmentDelimiter=" … "') AS snippet,
+    m.distance_km,
+    m.sort_value
+FROM matches m
+CROSS JOIN search s
+WHERE sqlc.narg('cursor_id')::uuid IS NULL
+    -- Newest first leaves out sort_value, which is constant for that sort, so the comparison
+    -- follows the column order of idx_jobs_published_newest.
+    OR (
+        sqlc.arg('sort')::text = 'newest'
+        AND (
+            (
+                (m.sort_posted_at, m.created_at, m.id) < (
+                    COALESCE(sqlc.narg('cursor_posted_at')::timestamptz, '-infinity'::timestamptz),
+                    sqlc.narg('cursor_created_at')::timestamptz,
+                    sqlc.narg('cursor_id')::uuid
+                ) AND NOT sqlc.arg('backward')::bool
+            )
+            OR (
+                (m.sort_posted_at, m.created_at, m.id) > (
+                    COALESCE(sqlc.narg('cursor_posted_at')::timestamptz, '-infinity'::timestamptz),
+                    sqlc.narg('cursor_created_at')::timestamptz,
+                    sqlc.narg('cursor_id')::uuid
+                ) AND sqlc.arg('backward')::bool
+            )
+        )
+    )
+    OR (
+        sqlc.arg('sort')::text <> 'newest'
+        AND (
+            (
+                (m.sort_value, m.sort_posted_at, m.created_at, m.id) < (
+                    COALESCE(sqlc.narg('cursor_sort_value')::float8, '-infinity'::float8),
+                    COALESCE(sqlc.narg('cursor_posted_at')::timestamptz, '-infinity'::timestamptz),
+                    sqlc.narg('cursor_created_at')::timestamptz,
+                    sqlc.narg('cursor_id')::uuid
+                ) AND NOT sqlc.arg('backward')::bool
+            )
+            OR (
+                (m.sort_value, m.sort_posted_at, m.created_at, m.id) > (
+                    COALESCE(sqlc.narg('cursor_sort_value')::float8, '-infinity'::float8),
+                    COALESCE(sqlc.narg('cursor_posted_at')::timestamptz, '-infinity'::timestamptz),
+                    sqlc.narg('cursor_created_at')::timestamptz,
+                    sqlc.narg('cursor_id')::uuid
+                ) AND sqlc.arg('backward')::bool
+            )
+        )
+    )
+ORDER BY
+    CASE WHEN sqlc.arg('backward')::bool THEN m.sort_value END ASC,
+    CASE WHEN sqlc.arg('backward')::bool THEN m.sort_posted_at END ASC,
+    CASE WHEN sqlc.arg('backward')::bool THEN m.created_at END ASC,
+    CASE WHEN sqlc.arg('backward')::bool THEN m.id END ASC,
+    m.sort_value DESC,
+    m.sort_posted_at DESC,
+    m.created_at DESC,
+    m.id DESC
+LIMIT sqlc.arg('limit_rows')::int OFFSET sqlc.arg('offset_rows')::int;
+
+-- name: CountPublishedJobs :one
+-- Counts the jobs ListPublishedJobs pages through for the same filters. It is a separate query
+-- so pages that skip the total do not pay for visiting every match.
+WITH search AS (
+    SELECT websearch_to_tsquery('english', sqlc.narg('search')::text) AS query
+)
+SELECT COUNT(*)
 FROM jobs j
 LEFT JOIN job_locations jl ON jl.id = j.location_id
+-- Great-circle distance in km by the haversine formula; NULL without a near point or
+-- without coordinates for the job's location.
+LEFT JOIN LATERAL (
+    SELECT 2 * 6371 * asin(LEAST(1, sqrt(
+        power(sin(radians(jl.latitude::float8 - sqlc.narg('near_latitude')::float8) / 2), 2)
+        + cos(radians(sqlc.narg('near_latitude')::float8)) * cos(radians(jl.latitude::float8))
+        * power(sin(radians(jl.longitude::float8 - sqlc.narg('near_longitude')::float8) / 2), 2)
+    ))) AS distance_km
+) d ON true
+CROSS JOIN search s
 WHERE j.status = 'published'
   AND (
-        sqlc.narg('search')::text IS NULL
-        OR j.title ILIKE '%' || sqlc.narg('search')::text || '%'
-        OR j.summary ILIKE '%' || sqlc.narg('search')::text || '%'
-        OR jl.city ILIKE '%' || sqlc.narg('search')::text || '%'
-        OR jl.country ILIKE '%' || sqlc.narg('search')::text || '%'
+        s.query IS NULL
+        OR numnode(s.query) = 0
+        OR j.search_vector @@ s.query
     )
   AND (
         sqlc.narg('countries')::text[] IS NULL
@@ -101,11 +296,35 @@ WHERE j.status = 'published'
             )
         )
     )
-ORDER BY j.posted_at DESC NULLS LAST, j.created_at DESC
-LIMIT sqlc.arg('limit_rows')::int OFFSET sqlc.arg('offset_rows')::int;
+  AND (
+        sqlc.narg('work_patterns')::text[] IS NULL
+        OR j.work_pattern = ANY(sqlc.narg('work_patterns')::text[])
+    )
+  -- Jobs without a currency are imported UK listings quoted in pounds.
+  AND (
+        sqlc.narg('currency')::text IS NULL
+        OR COALESCE(j.currency, 'GBP') = sqlc.narg('currency')::text
+    )
+  -- Salary bands match when they overlap the requested range.
+  AND (
+        sqlc.narg('salary_min')::integer IS NULL
+        OR COALESCE(j.salary_max, j.salary_min) >= sqlc.narg('salary_min')::integer
+    )
+  AND (
+        sqlc.narg('salary_max')::integer IS NULL
+        OR COALESCE(j.salary_min, j.salary_max) <= sqlc.narg('salary_max')::integer
+    )
+  AND (
+        sqlc.narg('posted_after')::timestamptz IS NULL
+        OR j.posted_at >= sqlc.narg('posted_after')::timestamptz
+    )
+  AND (
+        sqlc.narg('radius_km')::float8 IS NULL
+        OR d.distance_km <= sqlc.narg('radius_km')::float8
+    );
 
 -- name: GetJobBySlug :one
-SELECT j.*,
+SELECT j.id, j.title, j.slug, j.summary, j.description, j.location_id, j.contract_type, j.work_pattern, j.salary_min, j.salary_max, j.currency, j.status, j.source, j.source_ref, j.posted_at, j.expires_at, j.created_at, j.updated_at,
        jl.country,
        jl.region,
        jl.city
@@ -116,7 +335,7 @@ WHERE j.slug = sqlc.arg(slug)
 LIMIT 1;
 
 -- name: GetJobById :one
-SELECT j.*,
+SELECT j.id, j.title, j.slug, j.summary, j.description, j.location_id, j.contract_type, j.work_pattern, j.salary_min, j.salary_max, j.currency, j.status, j.source, j.source_ref, j.posted_at, j.expires_at, j.created_at, j.updated_at,
        jl.country,
        jl.region,
        jl.city
@@ -125,3 +344,15 @@ LEFT JOIN job_locations jl ON jl.id = j.location_id
 WHERE j.id = sqlc.arg(id)
     AND j.status = 'published'
 LIMIT 1;
+
+-- name: ListJobLocationsMissingCoordinates :many
+SELECT id, country, region, city
+FROM job_locations
+WHERE latitude IS NULL OR longitude IS NULL
+ORDER BY id;
+
+-- name: SetJobLocationCoordinates :exec
+UPDATE job_locations
+SET latitude = sqlc.arg('latitude')::float8,
+    longitude = sqlc.arg('longitude')::float8
+WHERE id = sqlc.arg('id');
@@ -0,0 +1,95 @@
+-- name: GetUserMFA :one
+SELECT user_id, totp_secret, confirmed_at, last_used_step, created_at, updated_at
+FROM user_mfa
+WHERE user_id = $1
+LIMIT 1;
+
+-- name: UpsertPendingUserMFA :one
+INSERT INTO user_mfa (
+    user_id,
+    totp_secret
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET totp_secret = EXCLUDED.totp_secret,
+    confirmed_at = NULL,
+    last_used_step = 0,
+    updated_at = NOW()
+RETURNING user_id, totp_secret, confirmed_at, last_used_step, created_at, updated_at;
+
+-- name: ConfirmUserMFA :exec
+UPDATE user_mfa
+SET confirmed_at = sqlc.arg(confirmed_at),
+    last_used_step = sqlc.arg(last_used_step),
+    updated_at = NOW()
+WHERE user_id = sqlc.arg(user_id);
+
+-- name: AdvanceUserMFAStep :execrows
+UPDATE user_mfa
+SET last_used_step = sqlc.arg(step),
+    updated_at = NOW()
+WHERE user_id = sqlc.arg(user_id)
+  AND last_used_step < sqlc.arg(step);
+
+-- name: DeleteUserMFA :exec
+DELETE FROM user_mfa
+WHERE user_id = $1;
+
+-- name: CreateMFARecoveryCode :exec
+INSERT INTO mfa_recovery_codes (
+    user_id,
+    code_hash
+) VALUES (
+    $1, $2
+);
+
+-- name: UseMFARecoveryCode :execrows
+UPDATE mfa_recovery_codes
+SET used_at = NOW()
+WHERE user_id = $1
+  AND code_hash = $2
+  AND used_at IS NULL;
+
+-- name: CountUnusedMFARecoveryCodes :one
+SELECT COUNT(*)
+FROM mfa_recovery_codes
+WHERE user_id = $1
+  AND used_at IS NULL;
+
+-- name: DeleteMFARecoveryCodes :exec
+DELETE FROM mfa_recovery_codes
+WHERE user_id = $1;
+
+-- name: CreateMFAChallenge :one
+INSERT INTO mfa_challenges (
+    user_id,
+    token_hash,
+    expires_at,
+    amr
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING id, user_id, token_hash, attempts, expires_at, consumed_at, created_at, amr;
+
+-- name: GetMFAChallengeByHash :one
+SELECT id, user_id, token_hash, attempts, expires_at, consumed_at, created_at, amr
+FROM mfa_challenges
+WHERE token_hash = $1
+LIMIT 1;
+
+-- name: IncrementMFAChallengeAttempts :one
+UPDATE mfa_challenges
+SET attempts = attempts + 1
+WHERE id = $1
+RETURNING attempts;
+
+-- name: ConsumeMFAChallenge :execrows
+UPDATE mfa_challenges
+SET consumed_at = NOW()
+WHERE id = $1
+  AND consumed_at IS NULL;
+
+-- name: DeleteMFAChallengesByUserID :exec
+DELETE FROM mfa_challenges
+WHERE user_id = $1;
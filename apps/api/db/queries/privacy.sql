@@ -0,0 +1,111 @@
+-- Data export and right-to-erasure queries
+
+-- name: AnonymiseUserApplications :exec
+UPDATE job_applications
+SET cover_letter = NULL,
+    metadata = NULL,
+    updated_at = NOW()
+WHERE user_id = $1;
+
+-- name: CreateErasureRequest :one
+INSERT INTO erasure_requests (
+    user_id,
+    reason,
+    due_at
+) VALUES (
+    $1, $2, $3
+)
+RETURNING id, user_id, status, reason, requested_at, due_at, reviewed_by, review_note, reviewed_at;
+
+-- name: ExportApplicationEvents :one
+SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]'::json)
+FROM (
+    SELECT e.id, e.application_id, e.status_from, e.status_to, e.comment, e.created_at
+    FROM application_events e
+    JOIN job_applications a ON a.id = e.application_id
+    WHERE a.user_id = $1
+) t;
+
+-- name: ExportErasureRequests :one
+SELECT COALESCE(json_agg(t ORDER BY t.requested_at), '[]'::json)
+FROM (
+    SELECT id, status, reason, requested_at, due_at, review_note, reviewed_at
+    FROM erasure_requests
+    WHERE user_id = $1
+) t;
+
+-- name: ExportJobApplications :one
+SELECT COALESCE(json_agg(t ORDER BY t.submitted_at), '[]'::json)
+FROM (
+    SELECT a.id, a.job_id, j.title AS job_title, a.status, a.cover_letter, a.metadata, a.submitted_at, a.updated_at
+    FROM job_applications a
+    JOIN jobs j ON j.id = a.job_id
+    WHERE a.user_id = $1
+) t;
+
+-- name: ExportUser :one
+SELECT COALESCE(json_agg(t), '[]'::json)
+FROM (
+    SELECT id, email, role, status, last_login_at, created_at, updated_at
+    FROM users
+    WHERE id = $1
+) t;
+
+-- name: ExportUserIdentities :one
+SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]'::json)
+FROM (
+    SELECT id, provider, subject, email, last_login_at, created_at
+    FROM user_identities
+    WHERE user_id = $1
+) t;
+
+-- name: ExportUserProfile :one
+SELECT COALESCE(json_agg(p), '[]'::json)
+FROM user_profiles p
+WHERE p.user_id = $1;
+
+-- name: ExportUserSessions :one
+SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]'::json)
+FROM (
+    SELECT id, user_agent, ip, created_at, expires_at, consumed_at
+    FROM user_sessions
+    WHERE user_id = $1
+) t;
+
+-- name: GetErasureRequest :one
+SELECT id, user_id, status, reason, requested_at, due_at, reviewed_by, review_note, reviewed_at
+FROM erasure_requests
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetLatestErasureRequest :one
+SELECT id, user_id, status, reason, requested_at, due_at, reviewed_by, review_note, reviewed_at
+FROM erasure_requests
+WHERE user_id = $1
+ORDER BY requested_at DESC
+LIMIT 1;
+
+-- name: ListErasureRequests :many
+SELECT id, user_id, status, reason, requested_at, due_at, reviewed_by, review_note, reviewed_at
+FROM erasure_requests
+WHERE sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text
+ORDER BY due_at ASC;
+
+-- name: RedactUserApplicationEvents :exec
+UPDATE application_events
+SET comment = NULL
+WHERE application_id IN (
+    SELECT id
+    FROM job_applications
+    WHERE user_id = $1
+);
+
+-- name: ReviewErasureRequest :one
+UPDATE erasure_requests
+SET status = $2,
+    reviewed_by = $3,
+    review_note = $4,
+    reviewed_at = NOW()
+WHERE id = $1
+  AND status = 'pending'
+RETURNING id, user_id, status, reason, requested_at, due_at, reviewed_by, review_note, reviewed_at;
@@ -0,0 +1,48 @@
+-- Access token revocation queries
+
+-- name: RevokeAccessToken :exec
+INSERT INTO revoked_access_tokens (jti, user_id, expires_at)
+VALUES (sqlc.arg(jti), sqlc.arg(user_id), sqlc.arg(expires_at))
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: RevokeFamilyAccessTokens :exec
+INSERT INTO revoked_access_tokens (jti, user_id, expires_at)
+SELECT access_token_jti, user_id, sqlc.arg(expires_at)
+FROM user_sessions
+WHERE family_id = sqlc.arg(family_id)
+  AND access_token_jti IS NOT NULL
+  AND created_at > sqlc.arg(issued_after)
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: RevokeOtherFamilyAccessTokens :exec
+INSERT INTO revoked_access_tokens (jti, user_id, expires_at)
+SELECT access_token_jti, user_id, sqlc.arg(expires_at)
+FROM user_sessions
+WHERE user_id = sqlc.arg(user_id)
+  AND family_id <> sqlc.arg(keep_family_id)
+  AND access_token_jti IS NOT NULL
+  AND created_at > sqlc.arg(issued_after)
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: RevokeUserAccessTokens :exec
+INSERT INTO revoked_access_tokens (jti, user_id, expires_at)
+SELECT access_token_jti, user_id, sqlc.arg(expires_at)
+FROM user_sessions
+WHERE user_id = sqlc.arg(user_id)
+  AND access_token_jti IS NOT NULL
+  AND created_at > sqlc.arg(issued_after)
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: ListActiveAccessTokenRevocations :many
+SELECT jti, expires_at
+FROM revoked_access_tokens
+WHERE expires_at > sqlc.arg(now);
+
+-- name: ListUserTokenCutoffs :many
+SELECT id, tokens_valid_after
+FROM users
+WHERE tokens_valid_after > sqlc.arg(since);
+
+-- name: DeleteExpiredAccessTokenRevocations :exec
+DELETE FROM revoked_access_tokens
+WHERE expires_at <= sqlc.arg(now);
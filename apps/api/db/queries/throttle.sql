@@ -0,0 +1,37 @@
+-- name: GetLoginThrottle :one
+SELECT scope, key, failures, last_failure_at, locked_until
+FROM login_throttles
+WHERE scope = sqlc.arg(scope)
+  AND key = sqlc.arg(key)
+LIMIT 1;
+
+-- name: RecordLoginFailure :one
+INSERT INTO login_throttles (
+    scope,
+    key,
+    failures,
+    last_failure_at
+) VALUES (
+    sqlc.arg(scope),
+    sqlc.arg(key),
+    1,
+    sqlc.arg(failed_at)
+)
+ON CONFLICT (scope, key) DO UPDATE
+SET failures = CASE
+        WHEN login_throttles.last_failure_at < sqlc.arg(reset_before) THEN 1
+        ELSE login_throttles.failures + 1
+    END,
+    last_failure_at = EXCLUDED.last_failure_at
+RETURNING scope, key, failures, last_failure_at, locked_until;
+
+-- name: SetLoginThrottleLock :exec
+UPDATE login_throttles
+SET locked_until = sqlc.arg(locked_until)
+WHERE scope = sqlc.arg(scope)
+  AND key = sqlc.arg(key);
+
+-- name: DeleteLoginThrottle :exec
+DELETE FROM login_throttles
+WHERE scope = sqlc.arg(scope)
+  AND key = sqlc.arg(key);
@@ -19,7 +19,7 @@ WHERE email = sqlc.arg(email)
 LIMIT 1;
 
 -- name: GetUserByID :one
-SELECT id, email, password_hash, role, status, last_login_at, created_at, updated_at
+SELECT id, email, password_hash, role, status, last_login_at, created_at, updated_at, tokens_valid_after
 FROM users
 WHERE id = sqlc.arg(id)
 LIMIT 1;
@@ -29,3 +29,61 @@ UPDATE users
 SET last_login_at = NOW(),
     updated_at = NOW()
 WHERE id = sqlc.arg(id);
+
+-- name: UpdateUserStatus :one
+UPDATE users
+SET status = sqlc.arg(status),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateUserPassword :exec
+UPDATE users
+SET password_hash = sqlc.arg(password_hash),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id);
+
+-- name: SetUserTokensValidAfter :exec
+UPDATE users
+SET tokens_valid_after = GREATEST(COALESCE(tokens_valid_after, sqlc.arg(valid_after)), sqlc.arg(valid_after))
+WHERE id = sqlc.arg(id);
+
+-- name: UpdateUserEmail :one
+UPDATE users
+SET email = sqlc.arg(email),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CountUsersByRole :one
+SELECT COUNT(*)
+FROM users
+WHERE role = sqlc.arg(role);
+
+-- name: ListUsers :many
+SELECT
+    id,
+    email,
+    role,
+    status,
+    last_login_at,
+    created_at,
+    updated_at,
+    COUNT(*) OVER() AS total_count
+FROM users
+WHERE (sqlc.narg('search')::text IS NULL OR email ILIKE '%' || sqlc.narg('search')::text || '%')
+  AND (sqlc.narg('role')::text IS NULL OR role = sqlc.narg('role')::text)
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status')::text)
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg('limit_rows')::int OFFSET sqlc.arg('offset_rows')::int;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = sqlc.arg(role),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = sqlc.arg(id);
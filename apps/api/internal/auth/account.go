@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+const statusClosed = "closed"
+
+var (
+	// ErrInvalidCurrentPassword indicates the current password supplied to confirm an account
+	// change was wrong.
+	ErrInvalidCurrentPassword = errors.New("current password is incorrect")
+	// ErrEmailUnchanged indicates the requested address is already the account's email.
+	ErrEmailUnchanged = errors.New("new email matches the current email")
+	// ErrReauthenticationRequired indicates a passwordless account must sign in again before
+	// the change, since there is no password to confirm it with.
+	ErrReauthenticationRequired = errors.New("sign in again to make this change")
+)
+
+// Account is the caller's own view of their user record.
+type Account struct {
+	ID          uuid.UUID
+	Email       string
+	Role        string
+	Status      string
+	HasPassword bool
+	// PendingEmail is an address awaiting verification before it replaces Email.
+	PendingEmail string
+	LastLoginAt  *time.Time
+	CreatedAt    time.Time
+}
+
+// ChangePasswordInput holds the payload for changing a known password.
+type ChangePasswordInput struct {
+	CurrentPassword string
+	NewPassword     string
+}
+
+// ChangeEmailInput holds the payload for requesting an email change. CurrentPassword is only
+// checked for accounts that have a password; passwordless accounts must have signed in recently.
+type ChangeEmailInput struct {
+	NewEmail        string
+	CurrentPassword string
+}
+
+// Account returns the user's own account details.
+func (s *Service) Account(ctx context.Context, userID uuid.UUID) (Account, error) {
+	q := s.store.Queries()
+	user, err := q.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Account{}, ErrUserNotFound
+		}
+		return Account{}, err
+	}
+
+	account := Account{
+		ID:          user.ID,
+		Email:       user.Email,
+		Role:        user.Role,
+		Status:      user.Status,
+		HasPassword: user.PasswordHash != "",
+		CreatedAt:   user.CreatedAt,
+	}
+	if user.LastLoginAt.Valid {
+		lastLogin := user.LastLoginAt.Time
+		account.LastLoginAt = &lastLogin
+	}
+
+	pending, err := q.GetPendingEmailChange(ctx, queries.GetPendingEmailChangeParams{UserID: userID, Now: s.now()})
+	switch {
+	case err == nil:
+		account.PendingEmail = pending
+	case !errors.Is(err, sql.ErrNoRows):
+		return Account{}, err
+	}
+
+	return account, nil
+}
+
+// ChangePassword replaces the user's password after checking the current one, and signs out
+// every other session.
+func (s *Service) ChangePassword(ctx context.Context, userID, currentSessionID uuid.UUID, input ChangePasswordInput) error {
+	password, err := normalizePassword(input.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.WithTx(ctx, func(q *queries.Queries) error {
+		user, err := q.GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		if err := verifyPassword(user.PasswordHash, input.CurrentPassword); err != nil {
+			return ErrInvalidCurrentPassword
+		}
+
+		hash, err := hashPassword(password, s.config.Password)
+		if err != nil {
+			return err
+		}
+		if err := q.UpdateUserPassword(ctx, queries.UpdateUserPasswordParams{ID: user.ID, PasswordHash: hash}); err != nil {
+			return err
+		}
+		if err := q.DeletePendingPasswordResetTokens(ctx, user.ID); err != nil {
+			return err
+		}
+
+		if err := s.revokeOtherAccessTokens(ctx, q, user.ID, currentSessionID); err != nil {
+			return err
+		}
+		return q.DeleteOtherUserSessions(ctx, queries.DeleteOtherUserSessionsParams{
+			UserID:       user.ID,
+			KeepFamilyID: currentSessionID,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.revocations.markStale()
+	s.logger.Info().Str("event", "password_changed").Str("user_id", userID.String()).Msg("password changed")
+	return nil
+}
+
+// RequestEmailChange emails a verification link to the new address. The account keeps its
+// current email until the link is followed, see VerifyEmail. A passwordless account proves it
+// is still in the owner's hands by the current session having signed in within ReauthWindow.
+func (s *Service) RequestEmailChange(ctx context.Context, userID, currentSessionID uuid.UUID, input ChangeEmailInput) error {
+	email, err := normalizeEmail(input.NewEmail)
+	if err != nil {
+		return err
+	}
+
+	var verificationToken string
+	err = s.store.WithTx(ctx, func(q *queries.Queries) error {
+		user, err := q.GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		if user.PasswordHash != "" {
+			if err := verifyPassword(user.PasswordHash, input.CurrentPassword); err != nil {
+				return ErrInvalidCurrentPassword
+			}
+		} else if err := s.requireRecentSignIn(ctx, q, user.ID, currentSessionID); err != nil {
+			return err
+		}
+
+		if strings.EqualFold(user.Email, email) {
+			return ErrEmailUnchanged
+		}
+		if _, err := q.GetUserByEmail(ctx, email); err == nil {
+			return ErrEmailInUse
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		// Only the latest requested address can be confirmed.
+		if err := q.DeletePendingEmailVerificationTokens(ctx, user.ID); err != nil {
+			return err
+		}
+
+		user.Email = email
+		verificationToken, err = s.createVerificationToken(ctx, q, user)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.sendEmailChangeVerification(ctx, email, verificationToken)
+	return nil
+}
+
+// requireRecentSignIn checks that the session family signed in, rather than refreshed, within
+// ReauthWindow.
+func (s *Service) requireRecentSignIn(ctx context.Context, q *queries.Queries, userID, sessionID uuid.UUID) error {
+	signedInAt, err := q.GetUserSessionFamilySignedInAt(ctx, queries.GetUserSessionFamilySignedInAtParams{
+		FamilyID: sessionID,
+		UserID:   userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrReauthenticationRequired
+		}
+		return err
+	}
+	if s.now().Sub(signedInAt) > s.config.ReauthWindow {
+		return ErrReauthenticationRequired
+	}
+	return nil
+}
+
+// CloseAccount deactivates the user's account and signs them out everywhere. Accounts with a
+// password must confirm it; passwordless accounts need a current session that signed in within
+// ReauthWindow.
+func (s *Service) CloseAccount(ctx context.Context, userID, currentSessionID uuid.UUID, currentPassword string) error {
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		user, err := q.GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		if user.PasswordHash != "" {
+			if err := verifyPassword(user.PasswordHash, currentPassword); err != nil {
+				return ErrInvalidCurrentPassword
+			}
+		} else if err := s.requireRecentSignIn(ctx, q, user.ID, currentSessionID); err != nil {
+			return err
+		}
+
+		if _, err := q.UpdateUserStatus(ctx, queries.UpdateUserStatusParams{ID: user.ID, Status: statusClosed}); err != nil {
+			return err
+		}
+		return s.revokeAllSessions(ctx, q, user.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidatePrincipal(ctx, userID)
+	s.revocations.markStale()
+	s.logger.Info().Str("event", "account_closed").Str("user_id", userID.String()).Msg("account closed by user")
+	return nil
+}
@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type changeEmailRequest struct {
+	Email           string `json:"email"`
+	CurrentPassword string `json:"current_password"`
+}
+
+type closeAccountRequest struct {
+	CurrentPassword string `json:"current_password"`
+}
+
+type accountResponse struct {
+	ID           string  `json:"id"`
+	Email        string  `json:"email"`
+	Role         string  `json:"role"`
+	Status       string  `json:"status"`
+	HasPassword  bool    `json:"has_password"`
+	PendingEmail string  `json:"pending_email,omitempty"`
+	LastLoginAt  *string `json:"last_login_at"`
+	CreatedAt    string  `json:"created_at"`
+	// ImpersonatedBy is the administrator acting as the user, so clients can show a banner.
+	ImpersonatedBy *string `json:"impersonated_by,omitempty"`
+}
+
+func (h *Handler) handleGetAccount(w http.ResponseWriter, r *http.Request) {
+	user, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	account, err := h.service.Account(r.Context(), user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to load account")
+		}
+		return
+	}
+
+	resp := accountResponse{
+		ID:           account.ID.String(),
+		Email:        account.Email,
+		Role:         account.Role,
+		Status:       account.Status,
+		HasPassword:  account.HasPassword,
+		PendingEmail: account.PendingEmail,
+		CreatedAt:    account.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if account.LastLoginAt != nil {
+		lastLogin := account.LastLoginAt.UTC().Format(time.RFC3339)
+		resp.LastLoginAt = &lastLogin
+	}
+	if user.IsImpersonated() {
+		impersonatedBy := user.ImpersonatorID.String()
+		resp.ImpersonatedBy = &impersonatedBy
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) handleChangePassword(w http.ResponseWriter, r *http.Request) {
+	user, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req changePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	err := h.service.ChangePassword(r.Context(), user.ID, user.SessionID, ChangePasswordInput{
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrWeakPassword):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrInvalidCurrentPassword):
+			writeError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, ErrUserNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to change password")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleChangeEmail(w http.ResponseWriter, r *http.Request) {
+	user, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req changeEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	err := h.service.RequestEmailChange(r.Context(), user.ID, user.SessionID, ChangeEmailInput{
+		NewEmail:        req.Email,
+		CurrentPassword: req.CurrentPassword,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidEmail), errors.Is(err, ErrEmailUnchanged):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrInvalidCurrentPassword), errors.Is(err, ErrReauthenticationRequired):
+			writeError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, ErrEmailInUse):
+			writeError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, ErrUserNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to change email")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Handler) handleCloseAccount(w http.ResponseWriter, r *http.Request) {
+	user, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req closeAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if err := h.service.CloseAccount(r.Context(), user.ID, user.SessionID, req.CurrentPassword); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidCurrentPassword), errors.Is(err, ErrReauthenticationRequired):
+			writeError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, ErrUserNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to close account")
+		}
+		return
+	}
+
+	h.clearSessionCookies(w)
+	w.WriteHeader(http.StatusNoContent)
+}
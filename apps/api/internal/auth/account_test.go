@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+func TestRequestEmailChangePasswordlessNeedsRecentSignIn(t *testing.T) {
+	s, clock := newTestService(t, Config{ReauthWindow: 10 * time.Minute})
+	ctx := context.Background()
+	user := createTestUser(t, s, RoleSeeker)
+	signedIn, session := signInPasswordless(t, s, user)
+
+	requestChange := func(sessionID uuid.UUID) error {
+		return s.RequestEmailChange(ctx, user.ID, sessionID, ChangeEmailInput{
+			NewEmail: fmt.Sprintf("new-%s@example.test", uuid.NewString()),
+		})
+	}
+
+	if err := requestChange(session.FamilyID); err != nil {
+		t.Fatalf("change right after sign-in: %v", err)
+	}
+
+	// Refreshing keeps the session alive but does not count as signing in again.
+	clock.Advance(9 * time.Minute)
+	if _, err := s.Refresh(ctx, signedIn.RefreshToken, SessionMetadata{}); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	clock.Advance(2 * time.Minute)
+
+	tests := []struct {
+		name      string
+		sessionID uuid.UUID
+	}{
+		{name: "stale session", sessionID: session.FamilyID},
+		{name: "unknown session", sessionID: uuid.New()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := requestChange(tt.sessionID); !errors.Is(err, ErrReauthenticationRequired) {
+				t.Fatalf("got %v, want %v", err, ErrReauthenticationRequired)
+			}
+		})
+	}
+}
+
+func TestCloseAccountPasswordlessNeedsRecentSignIn(t *testing.T) {
+	s, clock := newTestService(t, Config{ReauthWindow: 10 * time.Minute})
+	ctx := context.Background()
+	user := createTestUser(t, s, RoleSeeker)
+	signedIn, session := signInPasswordless(t, s, user)
+
+	clock.Advance(9 * time.Minute)
+	if _, err := s.Refresh(ctx, signedIn.RefreshToken, SessionMetadata{}); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	clock.Advance(2 * time.Minute)
+
+	if err := s.CloseAccount(ctx, user.ID, session.FamilyID, ""); !errors.Is(err, ErrReauthenticationRequired) {
+		t.Fatalf("stale session: got %v, want %v", err, ErrReauthenticationRequired)
+	}
+	reloaded, err := s.store.Queries().GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("reload user: %v", err)
+	}
+	if reloaded.Status == statusClosed {
+		t.Fatal("account closed by a stale session")
+	}
+
+	_, fresh := signInPasswordless(t, s, user)
+	if err := s.CloseAccount(ctx, user.ID, fresh.FamilyID, ""); err != nil {
+		t.Fatalf("close right after sign-in: %v", err)
+	}
+}
+
+// signInPasswordless removes user's password, as for accounts created through OIDC or magic
+// links, and starts a new session for it.
+func signInPasswordless(t *testing.T, s *Service, user queries.User) (AuthResult, queries.UserSession) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := s.store.Queries().UpdateUserPassword(ctx, queries.UpdateUserPasswordParams{ID: user.ID}); err != nil {
+		t.Fatalf("clear password: %v", err)
+	}
+	user.PasswordHash = ""
+
+	var signedIn AuthResult
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		var err error
+		signedIn, err = s.issueTokens(ctx, q, user, SessionMetadata{}, []string{amrEmail})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("sign in: %v", err)
+	}
+	session, err := s.store.Queries().GetUserSessionByHash(ctx, hashRefreshToken(signedIn.RefreshToken))
+	if err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	return signedIn, session
+}
@@ -0,0 +1,397 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+const statusSuspended = "suspended"
+
+// knownStatuses lists every value users.status takes.
+var knownStatuses = []string{statusActive, statusPendingVerification, statusSuspended, statusClosed}
+
+var (
+	// ErrInvalidRole indicates the role is not one of the known roles.
+	ErrInvalidRole = errors.New("unknown role")
+	// ErrInvalidStatus indicates the status filter is not one of the known statuses.
+	ErrInvalidStatus = errors.New("unknown status")
+	// ErrSelfManagement indicates an administrator tried to change their own role or status.
+	ErrSelfManagement = errors.New("administrators cannot change their own role or status")
+	// ErrStatusTransition indicates the user's current status does not allow the change.
+	ErrStatusTransition = errors.New("user status does not allow this change")
+)
+
+// ManagedUser is a user record as seen by administrators.
+type ManagedUser struct {
+	ID          uuid.UUID
+	Email       string
+	Role        string
+	Status      string
+	LastLoginAt sql.NullTime
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ListUsersParams controls pagination and filtering of the user list. Search matches part of
+// the email address.
+type ListUsersParams struct {
+	Page     int
+	PageSize int
+	Search   string
+	Role     string
+	Status   string
+}
+
+// ListUsersResult is one page of users.
+type ListUsersResult struct {
+	Users    []ManagedUser
+	Page     int
+	PageSize int
+	Total    int64
+	HasMore  bool
+}
+
+// ListUsers returns users, newest first, matching the filters.
+func (s *Service) ListUsers(ctx context.Context, params ListUsersParams) (ListUsersResult, error) {
+	page := params.Page
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize := params.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	role := strings.ToLower(strings.TrimSpace(params.Role))
+	if role != "" && !ValidRole(role) {
+		return ListUsersResult{}, ErrInvalidRole
+	}
+	status := strings.ToLower(strings.TrimSpace(params.Status))
+	if status != "" && !slices.Contains(knownStatuses, status) {
+		return ListUsersResult{}, ErrInvalidStatus
+	}
+
+	rows, err := s.store.Queries().ListUsers(ctx, queries.ListUsersParams{
+		Search:     nullString(strings.TrimSpace(params.Search)),
+		Role:       nullString(role),
+		Status:     nullString(status),
+		OffsetRows: int32((page - 1) * pageSize),
+		LimitRows:  int32(pageSize),
+	})
+	if err != nil {
+		return ListUsersResult{}, err
+	}
+
+	result := ListUsersResult{
+		Users:    make([]ManagedUser, 0, len(rows)),
+		Page:     page,
+		PageSize: pageSize,
+	}
+	for _, row := range rows {
+		result.Total = row.TotalCount
+		result.Users = append(result.Users, ManagedUser{
+			ID:          row.ID,
+			Email:       row.Email,
+			Role:        row.Role,
+			Status:      row.Status,
+			LastLoginAt: row.LastLoginAt,
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+		})
+	}
+	result.HasMore = int64(page*pageSize) < result.Total
+	return result, nil
+}
+
+// ManagedUser returns a single user for administrators.
+func (s *Service) ManagedUser(ctx context.Context, userID uuid.UUID) (ManagedUser, error) {
+	user, err := s.store.Queries().GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ManagedUser{}, ErrUserNotFound
+		}
+		return ManagedUser{}, err
+	}
+	return toManagedUser(user), nil
+}
+
+// ChangeUserRole assigns a new role. Administrators cannot change their own role, nor grant or
+// take away permissions they do not hold themselves.
+func (s *Service) ChangeUserRole(ctx context.Context, actor UserContext, userID uuid.UUID, role string) (ManagedUser, error) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if !ValidRole(role) {
+		return ManagedUser{}, ErrInvalidRole
+	}
+	if err := checkManagedTarget(actor, userID); err != nil {
+		return ManagedUser{}, err
+	}
+	if !actor.Can(PermissionsForRole(role)...) {
+		return ManagedUser{}, ErrForbidden
+	}
+
+	var updated queries.User
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		user, err := getManagedUser(ctx, q, userID)
+		if err != nil {
+			return err
+		}
+		if !actor.Can(PermissionsForRole(user.Role)...) {
+			return ErrForbidden
+		}
+		if user.Role == role {
+			updated = user
+			return nil
+		}
+
+		updated, err = q.UpdateUserRole(ctx, queries.UpdateUserRoleParams{ID: user.ID, Role: role})
+		if err != nil {
+			return err
+		}
+
+		return s.recordAudit(ctx, q, auditEntry{
+			ActorID:    actor.ID,
+			Action:     auditUserRoleChanged,
+			TargetType: auditTargetUser,
+			TargetID:   user.ID,
+			Metadata:   map[string]any{"from": user.Role, "to": role},
+		})
+	})
+	if err != nil {
+		return ManagedUser{}, err
+	}
+
+	s.invalidatePrincipal(ctx, userID)
+	s.logger.Info().
+		Str("event", "user_role_changed").
+		Str("user_id", userID.String()).
+		Str("actor_id", actor.ID.String()).
+		Str("role", role).
+		Msg("user role changed")
+	return toManagedUser(updated), nil
+}
+
+// SuspendUser blocks an active or pending account from signing in and signs it out everywhere.
+func (s *Service) SuspendUser(ctx context.Context, actor UserContext, userID uuid.UUID, reason string) (ManagedUser, error) {
+	if err := checkManagedTarget(actor, userID); err != nil {
+		return ManagedUser{}, err
+	}
+
+	var updated queries.User
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		user, err := getManagedUser(ctx, q, userID)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(user.Status) {
+		case statusActive, statusPendingVerification:
+		default:
+			return ErrStatusTransition
+		}
+
+		updated, err = q.UpdateUserStatus(ctx, queries.UpdateUserStatusParams{ID: user.ID, Status: statusSuspended})
+		if err != nil {
+			return err
+		}
+		if err := s.revokeAllSessions(ctx, q, user.ID); err != nil {
+			return err
+		}
+
+		metadata := map[string]any{"previous_status": user.Status}
+		if reason = strings.TrimSpace(reason); reason != "" {
+			metadata["reason"] = reason
+		}
+		return s.recordAudit(ctx, q, auditEntry{
+			ActorID:    actor.ID,
+			Action:     auditUserSuspended,
+			TargetType: auditTargetUser,
+			TargetID:   user.ID,
+			Metadata:   metadata,
+		})
+	})
+	if err != nil {
+		return ManagedUser{}, err
+	}
+
+	s.invalidatePrincipal(ctx, userID)
+	s.revocations.markStale()
+	s.logger.Info().Str("event", "user_suspended").Str("user_id", userID.String()).Str("actor_id", actor.ID.String()).Msg("user suspended")
+	return toManagedUser(updated), nil
+}
+
+// ReactivateUser lifts a suspension. The account becomes active, since an administrator has
+// vouched for it.
+func (s *Service) ReactivateUser(ctx context.Context, actor UserContext, userID uuid.UUID) (ManagedUser, error) {
+	if err := checkManagedTarget(actor, userID); err != nil {
+		return ManagedUser{}, err
+	}
+
+	var updated queries.User
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		user, err := getManagedUser(ctx, q, userID)
+		if err != nil {
+			return err
+		}
+		if strings.ToLower(user.Status) != statusSuspended {
+			return ErrStatusTransition
+		}
+
+		updated, err = q.UpdateUserStatus(ctx, queries.UpdateUserStatusParams{ID: user.ID, Status: statusActive})
+		if err != nil {
+			return err
+		}
+
+		return s.recordAudit(ctx, q, auditEntry{
+			ActorID:    actor.ID,
+			Action:     auditUserReactivated,
+			TargetType: auditTargetUser,
+			TargetID:   user.ID,
+		})
+	})
+	if err != nil {
+		return ManagedUser{}, err
+	}
+
+	s.invalidatePrincipal(ctx, userID)
+	s.logger.Info().Str("event", "user_reactivated").Str("user_id", userID.String()).Str("actor_id", actor.ID.String()).Msg("user reactivated")
+	return toManagedUser(updated), nil
+}
+
+// ForcePasswordReset discards the user's password, signs them out everywhere and emails a reset
+// link. Until the link is used the account can only sign in without a password.
+func (s *Service) ForcePasswordReset(ctx context.Context, actor UserContext, userID uuid.UUID) error {
+	if actor.IsAPIKey() {
+		return ErrHumanUserRequired
+	}
+
+	var recipient, resetToken string
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		user, err := getManagedUser(ctx, q, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := q.UpdateUserPassword(ctx, queries.UpdateUserPasswordParams{ID: user.ID, PasswordHash: ""}); err != nil {
+			return err
+		}
+		if err := q.DeletePendingPasswordResetTokens(ctx, user.ID); err != nil {
+			return err
+		}
+
+		token, hashed, expiresAt, err := generateOpaqueToken(s.config.PasswordResetTokenTTL, s.now())
+		if err != nil {
+			return err
+		}
+		if _, err := q.CreatePasswordResetToken(ctx, queries.CreatePasswordResetTokenParams{
+			UserID:    user.ID,
+			TokenHash: hashed,
+			ExpiresAt: expiresAt,
+		}); err != nil {
+			return err
+		}
+
+		if err := s.revokeAllSessions(ctx, q, user.ID); err != nil {
+			return err
+		}
+
+		recipient = user.Email
+		resetToken = token
+		return s.recordAudit(ctx, q, auditEntry{
+			ActorID:    actor.ID,
+			Action:     auditUserPasswordResetForced,
+			TargetType: auditTargetUser,
+			TargetID:   user.ID,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.revocations.markStale()
+	s.logger.Info().Str("event", "password_reset_forced").Str("user_id", userID.String()).Str("actor_id", actor.ID.String()).Msg("password reset forced")
+	s.sendPasswordResetEmail(ctx, recipient, resetToken)
+	return nil
+}
+
+// RevokeUserSessions signs a user out everywhere on an administrator's behalf.
+func (s *Service) RevokeUserSessions(ctx context.Context, actor UserContext, userID uuid.UUID) error {
+	if actor.IsAPIKey() {
+		return ErrHumanUserRequired
+	}
+
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		user, err := getManagedUser(ctx, q, userID)
+		if err != nil {
+			return err
+		}
+		if err := s.revokeAllSessions(ctx, q, user.ID); err != nil {
+			return err
+		}
+
+		return s.recordAudit(ctx, q, auditEntry{
+			ActorID:    actor.ID,
+			Action:     auditUserSessionsRevoked,
+			TargetType: auditTargetUser,
+			TargetID:   user.ID,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.revocations.markStale()
+	s.logger.Info().Str("event", "user_sessions_revoked").Str("user_id", userID.String()).Str("actor_id", actor.ID.String()).Msg("user sessions revoked by administrator")
+	return nil
+}
+
+// checkManagedTarget rejects role and status changes by machine clients, who cannot be held to
+// account in the audit trail as a person, and by administrators on themselves, which could
+// leave nobody able to manage users.
+func checkManagedTarget(actor UserContext, userID uuid.UUID) error {
+	if actor.IsAPIKey() {
+		return ErrHumanUserRequired
+	}
+	if actor.ID == userID {
+		return ErrSelfManagement
+	}
+	return nil
+}
+
+func getManagedUser(ctx context.Context, q *queries.Queries, userID uuid.UUID) (queries.User, error) {
+	user, err := q.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return queries.User{}, ErrUserNotFound
+		}
+		return queries.User{}, err
+	}
+	return user, nil
+}
+
+func toManagedUser(user queries.User) ManagedUser {
+	return ManagedUser{
+		ID:          user.ID,
+		Email:       user.Email,
+		Role:        user.Role,
+		Status:      user.Status,
+		LastLoginAt: user.LastLoginAt,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
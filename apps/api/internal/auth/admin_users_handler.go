@@ -0,0 +1,255 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type changeRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type suspendUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+type managedUserResponse struct {
+	ID          string  `json:"id"`
+	Email       string  `json:"email"`
+	Role        string  `json:"role"`
+	Status      string  `json:"status"`
+	LastLoginAt *string `json:"last_login_at"`
+	CreatedAt   string  `json:"created_at"`
+	UpdatedAt   string  `json:"updated_at"`
+}
+
+type listUsersResponse struct {
+	Users    []managedUserResponse `json:"users"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+	Total    int64                 `json:"total"`
+	HasMore  bool                  `json:"has_more"`
+}
+
+func (h *Handler) handleUnlockUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, ErrUserNotFound.Error())
+		return
+	}
+
+	if err := h.service.UnlockAccount(r.Context(), userID); err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to unlock account")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	fields := map[string]string{}
+	page := parseIntParam(query, "page", 1, 1, 0, fields)
+	pageSize := parseIntParam(query, "page_size", 20, 1, 100, fields)
+	if len(fields) > 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":  "invalid query parameters",
+			"fields": fields,
+		})
+		return
+	}
+
+	result, err := h.service.ListUsers(r.Context(), ListUsersParams{
+		Page:     page,
+		PageSize: pageSize,
+		Search:   query.Get("q"),
+		Role:     query.Get("role"),
+		Status:   query.Get("status"),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidRole), errors.Is(err, ErrInvalidStatus):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to list users")
+		}
+		return
+	}
+
+	resp := listUsersResponse{
+		Users:    make([]managedUserResponse, 0, len(result.Users)),
+		Page:     result.Page,
+		PageSize: result.PageSize,
+		Total:    result.Total,
+		HasMore:  result.HasMore,
+	}
+	for _, user := range result.Users {
+		resp.Users = append(resp.Users, toManagedUserResponse(user))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, ErrUserNotFound.Error())
+		return
+	}
+
+	user, err := h.service.ManagedUser(r.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to load user")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toManagedUserResponse(user))
+}
+
+func (h *Handler) handleChangeUserRole(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := managedUserRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req changeRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	user, err := h.service.ChangeUserRole(r.Context(), actor, userID, req.Role)
+	if err != nil {
+		writeManageUserError(w, err, "failed to change role")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toManagedUserResponse(user))
+}
+
+func (h *Handler) handleSuspendUser(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := managedUserRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// The reason is optional, so an empty body is accepted.
+	var req suspendUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	user, err := h.service.SuspendUser(r.Context(), actor, userID, req.Reason)
+	if err != nil {
+		writeManageUserError(w, err, "failed to suspend user")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toManagedUserResponse(user))
+}
+
+func (h *Handler) handleReactivateUser(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := managedUserRequest(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.service.ReactivateUser(r.Context(), actor, userID)
+	if err != nil {
+		writeManageUserError(w, err, "failed to reactivate user")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toManagedUserResponse(user))
+}
+
+func (h *Handler) handleForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := managedUserRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ForcePasswordReset(r.Context(), actor, userID); err != nil {
+		writeManageUserError(w, err, "failed to reset password")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleRevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := managedUserRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RevokeUserSessions(r.Context(), actor, userID); err != nil {
+		writeManageUserError(w, err, "failed to revoke sessions")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// managedUserRequest resolves the acting administrator and the target user of an admin
+// mutation, writing an error response on failure.
+func managedUserRequest(w http.ResponseWriter, r *http.Request) (UserContext, uuid.UUID, bool) {
+	actor, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return UserContext{}, uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, ErrUserNotFound.Error())
+		return UserContext{}, uuid.Nil, false
+	}
+	return actor, userID, true
+}
+
+// writeManageUserError maps the errors shared by the admin user mutations.
+func writeManageUserError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidRole):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrHumanUserRequired), errors.Is(err, ErrSelfManagement), errors.Is(err, ErrForbidden):
+		writeError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrStatusTransition):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, fallback)
+	}
+}
+
+func toManagedUserResponse(user ManagedUser) managedUserResponse {
+	return managedUserResponse{
+		ID:          user.ID.String(),
+		Email:       user.Email,
+		Role:        user.Role,
+		Status:      user.Status,
+		LastLoginAt: formatNullTime(user.LastLoginAt),
+		CreatedAt:   user.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:   user.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestHandleListUsersRejectsInvalidPaging(t *testing.T) {
+	// Paging is validated before the service is called, so no database is needed.
+	handler := &Handler{}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantFields []string
+	}{
+		{name: "page not a number", query: "page=two", wantFields: []string{"page"}},
+		{name: "page zero", query: "page=0", wantFields: []string{"page"}},
+		{name: "page size zero", query: "page_size=0", wantFields: []string{"page_size"}},
+		{name: "page size too large", query: "page_size=101", wantFields: []string{"page_size"}},
+		{name: "both invalid", query: "page=-1&page_size=abc", wantFields: []string{"page", "page_size"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.handleListUsers(recorder, httptest.NewRequest(http.MethodGet, "/admin/users?"+tt.query, nil))
+
+			if recorder.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusBadRequest, recorder.Body)
+			}
+			var body struct {
+				Fields map[string]string `json:"fields"`
+			}
+			if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if len(body.Fields) != len(tt.wantFields) {
+				t.Errorf("fields = %v, want %v", body.Fields, tt.wantFields)
+			}
+			for _, field := range tt.wantFields {
+				if body.Fields[field] == "" {
+					t.Errorf("fields = %v, missing %s", body.Fields, field)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckManagedTarget(t *testing.T) {
+	actorID := uuid.New()
+
+	tests := []struct {
+		name   string
+		actor  UserContext
+		target uuid.UUID
+		want   error
+	}{
+		{name: "other user", actor: UserContext{ID: actorID}, target: uuid.New()},
+		{name: "self", actor: UserContext{ID: actorID}, target: actorID, want: ErrSelfManagement},
+		{name: "api key", actor: UserContext{ID: actorID, APIKeyID: uuid.New()}, target: uuid.New(), want: ErrHumanUserRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkManagedTarget(tt.actor, tt.target); !errors.Is(err, tt.want) {
+				t.Errorf("checkManagedTarget() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangeUserRole(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+	ctx := context.Background()
+	admin := createTestUser(t, s, RoleStaffAdmin)
+	editor := createTestUser(t, s, RoleStaffEditor)
+	seeker := createTestUser(t, s, RoleSeeker)
+
+	updated, err := s.ChangeUserRole(ctx, staffContext(admin), seeker.ID, RoleStaffEditor)
+	if err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+	if updated.Role != RoleStaffEditor {
+		t.Errorf("role = %q, want %q", updated.Role, RoleStaffEditor)
+	}
+
+	tests := []struct {
+		name   string
+		actor  UserContext
+		target uuid.UUID
+		role   string
+		want   error
+	}{
+		{name: "unknown role", actor: staffContext(admin), target: seeker.ID, role: "owner", want: ErrInvalidRole},
+		{name: "own role", actor: staffContext(admin), target: admin.ID, role: RoleSeeker, want: ErrSelfManagement},
+		{name: "grant permissions the actor lacks", actor: staffContext(editor), target: seeker.ID, role: RoleStaffAdmin, want: ErrForbidden},
+		{name: "demote a more privileged user", actor: staffContext(editor), target: admin.ID, role: RoleSeeker, want: ErrForbidden},
+		{name: "missing user", actor: staffContext(admin), target: uuid.New(), role: RoleSeeker, want: ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.ChangeUserRole(ctx, tt.actor, tt.target, tt.role); !errors.Is(err, tt.want) {
+				t.Errorf("ChangeUserRole() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuspendAndReactivateUser(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+	ctx := context.Background()
+	admin := createTestUser(t, s, RoleStaffAdmin)
+	user := createTestUser(t, s, RoleSeeker)
+	session := login(t, s, user)
+
+	suspended, err := s.SuspendUser(ctx, staffContext(admin), user.ID, "spam")
+	if err != nil {
+		t.Fatalf("suspend: %v", err)
+	}
+	if suspended.Status != statusSuspended {
+		t.Errorf("status = %q, want %q", suspended.Status, statusSuspended)
+	}
+	if _, err := s.Refresh(ctx, session.RefreshToken, SessionMetadata{}); err == nil {
+		t.Error("refresh after suspension succeeded")
+	}
+	if _, err := s.Login(ctx, LoginInput{Email: user.Email, Password: testPassword}); !errors.Is(err, ErrInactiveAccount) {
+		t.Errorf("login while suspended: got %v, want %v", err, ErrInactiveAccount)
+	}
+	if _, err := s.SuspendUser(ctx, staffContext(admin), user.ID, ""); !errors.Is(err, ErrStatusTransition) {
+		t.Errorf("second suspension: got %v, want %v", err, ErrStatusTransition)
+	}
+
+	reactivated, err := s.ReactivateUser(ctx, staffContext(admin), user.ID)
+	if err != nil {
+		t.Fatalf("reactivate: %v", err)
+	}
+	if reactivated.Status != statusActive {
+		t.Errorf("status = %q, want %q", reactivated.Status, statusActive)
+	}
+	login(t, s, user)
+
+	if _, err := s.ReactivateUser(ctx, staffContext(admin), user.ID); !errors.Is(err, ErrStatusTransition) {
+		t.Errorf("reactivating an active user: got %v, want %v", err, ErrStatusTransition)
+	}
+}
@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+const (
+	apiKeyTokenPrefix = "sv_"
+	apiKeyPrefixBytes = 6
+	// apiKeyTouchInterval limits last_used_at writes to one per key per interval.
+	apiKeyTouchInterval = time.Minute
+)
+
+// RoleAPIKey is the role reported for API key principals. It is never stored in users.role.
+const RoleAPIKey = "api_key"
+
+var (
+	// ErrInvalidAPIKey signals the API key is malformed, unknown, revoked or expired.
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrAPIKeyNotFound indicates no API key matched the identifier.
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrInvalidAPIKeyName indicates the key name was empty.
+	ErrInvalidAPIKeyName = errors.New("api key name is required")
+	// ErrInvalidAPIKeyScopes indicates scopes were missing, unknown or exceed the creator's permissions.
+	ErrInvalidAPIKeyScopes = errors.New("api key scopes must be known permissions held by the creator")
+	// ErrInvalidAPIKeyExpiry indicates the requested expiry is in the past.
+	ErrInvalidAPIKeyExpiry = errors.New("api key expiry must be in the future")
+	// ErrHumanUserRequired indicates the endpoint is not available to API keys.
+	ErrHumanUserRequired = errors.New("endpoint requires a user session")
+)
+
+// APIKey describes a machine credential without its secret.
+type APIKey struct {
+	ID         uuid.UUID
+	Name       string
+	Prefix     string
+	Scopes     []Permission
+	CreatedBy  uuid.NullUUID
+	ExpiresAt  sql.NullTime
+	LastUsedAt sql.NullTime
+	RevokedAt  sql.NullTime
+	CreatedAt  time.Time
+}
+
+// CreateAPIKeyInput holds the attributes of a new key. A zero ExpiresAt never expires.
+type CreateAPIKeyInput struct {
+	Name      string
+	Scopes    []Permission
+	ExpiresAt time.Time
+}
+
+// CreatedAPIKey carries the full key, which is only ever shown once.
+type CreatedAPIKey struct {
+	APIKey
+	Key string
+}
+
+// CreateAPIKey issues a key on behalf of a staff user. Keys can only carry permissions their
+// creator holds.
+func (s *Service) CreateAPIKey(ctx context.Context, creator UserContext, input CreateAPIKeyInput) (CreatedAPIKey, error) {
+	if creator.IsAPIKey() {
+		return CreatedAPIKey{}, ErrHumanUserRequired
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return CreatedAPIKey{}, ErrInvalidAPIKeyName
+	}
+
+	if len(input.Scopes) == 0 {
+		return CreatedAPIKey{}, ErrInvalidAPIKeyScopes
+	}
+	scopes := make([]string, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		if !ValidPermission(scope) || !creator.Can(scope) {
+			return CreatedAPIKey{}, ErrInvalidAPIKeyScopes
+		}
+		scopes = append(scopes, string(scope))
+	}
+
+	expiresAt := sql.NullTime{Time: input.ExpiresAt, Valid: !input.ExpiresAt.IsZero()}
+	if expiresAt.Valid && !expiresAt.Time.After(s.now()) {
+		return CreatedAPIKey{}, ErrInvalidAPIKeyExpiry
+	}
+
+	prefix, secret, err := generateAPIKey()
+	if err != nil {
+		return CreatedAPIKey{}, err
+	}
+
+	record, err := s.store.Queries().CreateAPIKey(ctx, queries.CreateAPIKeyParams{
+		Name:       name,
+		Prefix:     prefix,
+		SecretHash: hashOpaqueToken(secret),
+		Scopes:     scopes,
+		CreatedBy:  uuid.NullUUID{UUID: creator.ID, Valid: true},
+		ExpiresAt:  expiresAt,
+	})
+	if err != nil {
+		return CreatedAPIKey{}, err
+	}
+
+	s.logger.Info().
+		Str("event", "api_key_created").
+		Str("api_key_id", record.ID.String()).
+		Str("created_by", creator.ID.String()).
+		Strs("scopes", scopes).
+		Msg("api key created")
+
+	return CreatedAPIKey{
+		APIKey: toAPIKey(record),
+		Key:    apiKeyTokenPrefix + prefix + "_" + secret,
+	}, nil
+}
+
+// ListAPIKeys returns every key, newest first, including revoked ones.
+func (s *Service) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	records, err := s.store.Queries().ListAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]APIKey, 0, len(records))
+	for _, record := range records {
+		keys = append(keys, toAPIKey(record))
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey disables a key immediately.
+func (s *Service) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	revoked, err := s.store.Queries().RevokeAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	if revoked == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	s.logger.Info().Str("event", "api_key_revoked").Str("api_key_id", id.String()).Msg("api key revoked")
+	return nil
+}
+
+// ValidateAPIKey resolves a presented key into a principal whose permissions are the key's scopes
+// that its creator still holds. Keys stop working once their creator is no longer active or
+// has been deleted.
+func (s *Service) ValidateAPIKey(ctx context.Context, key string) (UserContext, error) {
+	prefix, secret, ok := parseAPIKey(strings.TrimSpace(key))
+	if !ok {
+		return UserContext{}, ErrInvalidAPIKey
+	}
+
+	q := s.store.Queries()
+	record, err := q.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return UserContext{}, ErrInvalidAPIKey
+		}
+		return UserContext{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashOpaqueToken(secret)), []byte(record.SecretHash)) != 1 {
+		return UserContext{}, ErrInvalidAPIKey
+	}
+
+	now := s.now()
+	if record.RevokedAt.Valid || (record.ExpiresAt.Valid && record.ExpiresAt.Time.Before(now)) {
+		return UserContext{}, ErrInvalidAPIKey
+	}
+
+	if !record.CreatedBy.Valid {
+		return UserContext{}, ErrInvalidAPIKey
+	}
+	creator, err := s.loadPrincipal(ctx, record.CreatedBy.UUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return UserContext{}, ErrInvalidAPIKey
+		}
+		return UserContext{}, err
+	}
+	if !strings.EqualFold(creator.Status, statusActive) {
+		return UserContext{}, ErrInvalidAPIKey
+	}
+
+	if err := q.TouchAPIKey(ctx, queries.TouchAPIKeyParams{
+		ID:          record.ID,
+		UsedAt:      sql.NullTime{Time: now, Valid: true},
+		StaleBefore: sql.NullTime{Time: now.Add(-apiKeyTouchInterval), Valid: true},
+	}); err != nil {
+		s.logger.Warn().Err(err).Str("api_key_id", record.ID.String()).Msg("failed to record api key use")
+	}
+
+	return UserContext{
+		ID:          record.ID,
+		Role:        RoleAPIKey,
+		Permissions: keyPermissions(record.Scopes, creator.Role),
+		APIKeyID:    record.ID,
+	}, nil
+}
+
+// generateAPIKey returns a lookup prefix and a high-entropy secret.
+func generateAPIKey() (prefix, secret string, err error) {
+	buf := make([]byte, apiKeyPrefixBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	secret, err = randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(buf), secret, nil
+}
+
+// parseAPIKey splits "sv_<prefix>_<secret>". The secret is base64url and may itself contain "_".
+func parseAPIKey(key string) (prefix, secret string, ok bool) {
+	rest, found := strings.CutPrefix(key, apiKeyTokenPrefix)
+	if !found {
+		return "", "", false
+	}
+
+	prefix, secret, found = strings.Cut(rest, "_")
+	if !found || len(prefix) != apiKeyPrefixBytes*2 || secret == "" {
+		return "", "", false
+	}
+	return prefix, secret, true
+}
+
+func toAPIKey(record queries.ApiKey) APIKey {
+	return APIKey{
+		ID:         record.ID,
+		Name:       record.Name,
+		Prefix:     record.Prefix,
+		Scopes:     toPermissions(record.Scopes),
+		CreatedBy:  record.CreatedBy,
+		ExpiresAt:  record.ExpiresAt,
+		LastUsedAt: record.LastUsedAt,
+		RevokedAt:  record.RevokedAt,
+		CreatedAt:  record.CreatedAt,
+	}
+}
+
+// keyPermissions returns the scopes that role still grants, so a key loses permissions its
+// creator has since lost.
+func keyPermissions(scopes []string, role string) []Permission {
+	granted := PermissionsForRole(role)
+	perms := make([]Permission, 0, len(scopes))
+	for _, scope := range toPermissions(scopes) {
+		if slices.Contains(granted, scope) {
+			perms = append(perms, scope)
+		}
+	}
+	return perms
+}
+
+func toPermissions(scopes []string) []Permission {
+	perms := make([]Permission, 0, len(scopes))
+	for _, scope := range scopes {
+		perms = append(perms, Permission(scope))
+	}
+	return perms
+}
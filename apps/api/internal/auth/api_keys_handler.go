@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type createAPIKeyRequest struct {
+	Name      string       `json:"name"`
+	Scopes    []Permission `json:"scopes"`
+	ExpiresAt *time.Time   `json:"expires_at"`
+}
+
+type apiKeyResponse struct {
+	ID         string       `json:"id"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	Scopes     []Permission `json:"scopes"`
+	CreatedBy  *string      `json:"created_by"`
+	ExpiresAt  *string      `json:"expires_at"`
+	LastUsedAt *string      `json:"last_used_at"`
+	RevokedAt  *string      `json:"revoked_at"`
+	CreatedAt  string       `json:"created_at"`
+}
+
+type createdAPIKeyResponse struct {
+	apiKeyResponse
+	Key string `json:"key"`
+}
+
+func (h *Handler) handleListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.ListAPIKeys(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list api keys")
+		return
+	}
+
+	resp := make([]apiKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		resp = append(resp, toAPIKeyResponse(key))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"api_keys": resp})
+}
+
+func (h *Handler) handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	user, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req createAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	input := CreateAPIKeyInput{Name: req.Name, Scopes: req.Scopes}
+	if req.ExpiresAt != nil {
+		input.ExpiresAt = *req.ExpiresAt
+	}
+
+	created, err := h.service.CreateAPIKey(r.Context(), user, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrHumanUserRequired):
+			writeError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, ErrInvalidAPIKeyName),
+			errors.Is(err, ErrInvalidAPIKeyScopes),
+			errors.Is(err, ErrInvalidAPIKeyExpiry):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to create api key")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, createdAPIKeyResponse{
+		apiKeyResponse: toAPIKeyResponse(created.APIKey),
+		Key:            created.Key,
+	})
+}
+
+func (h *Handler) handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, ErrAPIKeyNotFound.Error())
+		return
+	}
+
+	if err := h.service.RevokeAPIKey(r.Context(), keyID); err != nil {
+		switch {
+		case errors.Is(err, ErrAPIKeyNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to revoke api key")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toAPIKeyResponse(key APIKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID:         key.ID.String(),
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		CreatedBy:  formatNullUUID(key.CreatedBy),
+		ExpiresAt:  formatNullTime(key.ExpiresAt),
+		LastUsedAt: formatNullTime(key.LastUsedAt),
+		RevokedAt:  formatNullTime(key.RevokedAt),
+		CreatedAt:  key.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
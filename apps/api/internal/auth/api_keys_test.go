@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+func TestKeyPermissions(t *testing.T) {
+	scopes := []string{string(PermStaffAccess), string(PermJobsWrite), string(PermUsersRead)}
+
+	tests := []struct {
+		role string
+		want []Permission
+	}{
+		{role: RoleStaffAdmin, want: []Permission{PermStaffAccess, PermJobsWrite, PermUsersRead}},
+		{role: RoleStaffEditor, want: []Permission{PermStaffAccess, PermJobsWrite}},
+		{role: RoleSeeker, want: []Permission{}},
+		{role: "unknown", want: []Permission{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			if got := keyPermissions(scopes, tt.role); !slices.Equal(got, tt.want) {
+				t.Errorf("keyPermissions(%q) = %v, want %v", tt.role, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAPIKeyFollowsCreator(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+	ctx := context.Background()
+	admin := createTestUser(t, s, RoleStaffAdmin)
+
+	created, err := s.CreateAPIKey(ctx, UserContext{
+		ID:          admin.ID,
+		Role:        admin.Role,
+		Permissions: PermissionsForRole(admin.Role),
+	}, CreateAPIKeyInput{
+		Name:   "reporting",
+		Scopes: []Permission{PermStaffAccess, PermJobsWrite, PermUsersRead},
+	})
+	if err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+	t.Cleanup(func() { _ = s.RevokeAPIKey(ctx, created.ID) })
+
+	principal, err := s.ValidateAPIKey(ctx, created.Key)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if !principal.Can(PermUsersRead) {
+		t.Fatalf("key permissions %v missing %s", principal.Permissions, PermUsersRead)
+	}
+
+	// Demoting the creator takes away the scopes the new role lacks.
+	if _, err := s.store.Queries().UpdateUserRole(ctx, queries.UpdateUserRoleParams{ID: admin.ID, Role: RoleStaffEditor}); err != nil {
+		t.Fatalf("demote: %v", err)
+	}
+	s.invalidatePrincipal(ctx, admin.ID)
+
+	principal, err = s.ValidateAPIKey(ctx, created.Key)
+	if err != nil {
+		t.Fatalf("validate after demotion: %v", err)
+	}
+	if principal.Can(PermUsersRead) || !principal.Can(PermStaffAccess, PermJobsWrite) {
+		t.Errorf("key permissions after demotion = %v", principal.Permissions)
+	}
+
+	// Suspending the creator disables the key.
+	if _, err := s.store.Queries().UpdateUserStatus(ctx, queries.UpdateUserStatusParams{ID: admin.ID, Status: statusSuspended}); err != nil {
+		t.Fatalf("suspend: %v", err)
+	}
+	s.invalidatePrincipal(ctx, admin.ID)
+
+	if _, err := s.ValidateAPIKey(ctx, created.Key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("validate after suspension: got %v, want %v", err, ErrInvalidAPIKey)
+	}
+}
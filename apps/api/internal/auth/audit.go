@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/clientip"
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+// Audited actions.
+const (
+	auditStaffInvited            = "staff.invited"
+	auditStaffInvitationRevoked  = "staff.invitation_revoked"
+	auditStaffInvitationAccepted = "staff.invitation_accepted"
+	auditStaffBootstrapped       = "staff.bootstrapped"
+	auditUserRoleChanged         = "user.role_changed"
+	auditUserSuspended           = "user.suspended"
+	auditUserReactivated         = "user.reactivated"
+	auditUserPasswordResetForced = "user.password_reset_forced"
+	auditUserSessionsRevoked     = "user.sessions_revoked"
+	auditImpersonationStarted    = "user.impersonation_started"
+	auditImpersonationStopped    = "user.impersonation_stopped"
+	auditDataExported            = "user.data_exported"
+	auditErasureRequested        = "user.erasure_requested"
+	auditErasureCompleted        = "user.erased"
+	auditErasureRejected         = "user.erasure_rejected"
+)
+
+// Audit target types.
+const (
+	auditTargetUser       = "user"
+	auditTargetInvitation = "staff_invitation"
+)
+
+// auditEntry describes a privileged action. A zero ActorID records a system action, such as
+// the bootstrap command.
+type auditEntry struct {
+	ActorID    uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   uuid.UUID
+	Metadata   map[string]any
+}
+
+// recordAudit appends an entry to the audit trail inside the caller's transaction, so the
+// record commits or rolls back with the action itself. The client IP is taken from the
+// request context when there is one.
+func (s *Service) recordAudit(ctx context.Context, q *queries.Queries, entry auditEntry) error {
+	metadata := entry.Metadata
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	ip, _ := parseIP(clientip.FromContext(ctx))
+	return q.CreateAuditEvent(ctx, queries.CreateAuditEventParams{
+		ActorID:    uuid.NullUUID{UUID: entry.ActorID, Valid: entry.ActorID != uuid.Nil},
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   uuid.NullUUID{UUID: entry.TargetID, Valid: entry.TargetID != uuid.Nil},
+		Metadata:   encoded,
+		Ip:         ip,
+	})
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// SessionModeBody returns the refresh token in JSON responses (default, suited to mobile/API clients).
+	SessionModeBody = "body"
+	// SessionModeCookie keeps the refresh token in an httpOnly cookie guarded by a double-submit CSRF token.
+	SessionModeCookie = "cookie"
+
+	csrfHeader        = "X-CSRF-Token"
+	refreshCookiePath = "/api/v1/auth"
+)
+
+// ErrInvalidCSRFToken indicates the double-submit CSRF header was missing or did not match the cookie.
+var ErrInvalidCSRFToken = errors.New("invalid CSRF token")
+
+// CookieConfig controls how refresh tokens are transported to browser clients.
+type CookieConfig struct {
+	Mode          string
+	RefreshCookie string
+	CSRFCookie    string
+	Domain        string
+	Secure        bool
+	SameSite      http.SameSite
+}
+
+// WithCookies configures the session transport used by the auth endpoints.
+func (h *Handler) WithCookies(cfg CookieConfig) *Handler {
+	if cfg.RefreshCookie == "" {
+		cfg.RefreshCookie = "sv_refresh"
+	}
+	if cfg.CSRFCookie == "" {
+		cfg.CSRFCookie = "sv_csrf"
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+	cfg.Mode = strings.ToLower(strings.TrimSpace(cfg.Mode))
+	h.cookies = cfg
+	return h
+}
+
+func (h *Handler) cookieMode() bool {
+	return h.cookies.Mode == SessionModeCookie
+}
+
+// writeAuthResponse returns issued tokens, moving the refresh token into cookies in cookie mode.
+func (h *Handler) writeAuthResponse(w http.ResponseWriter, status int, result AuthResult) {
+	resp := toAuthResponse(result)
+	if h.cookieMode() {
+		csrfToken, err := randomToken()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to issue session")
+			return
+		}
+
+		h.setSessionCookies(w, result.RefreshToken, csrfToken, result.RefreshTokenExpiry)
+		resp.RefreshToken = ""
+		resp.CSRFToken = csrfToken
+	}
+
+	writeJSON(w, status, resp)
+}
+
+// refreshTokenFromRequest resolves the refresh token from the session cookie, enforcing the
+// CSRF header, and falls back to the JSON body so token-based clients keep working.
+func (h *Handler) refreshTokenFromRequest(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if h.cookieMode() {
+		if cookie, err := r.Cookie(h.cookies.RefreshCookie); err == nil && cookie.Value != "" {
+			if !h.validCSRF(r) {
+				writeError(w, http.StatusForbidden, ErrInvalidCSRFToken.Error())
+				return "", false
+			}
+			return cookie.Value, true
+		}
+	}
+
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return "", false
+	}
+	return req.RefreshToken, true
+}
+
+func (h *Handler) validCSRF(r *http.Request) bool {
+	header := strings.TrimSpace(r.Header.Get(csrfHeader))
+	cookie, err := r.Cookie(h.cookies.CSRFCookie)
+	if header == "" || err != nil || cookie.Value == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(header), []byte(cookie.Value)) == 1
+}
+
+func (h *Handler) setSessionCookies(w http.ResponseWriter, refreshToken, csrfToken string, expires time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     h.cookies.RefreshCookie,
+		Value:    refreshToken,
+		Path:     refreshCookiePath,
+		Domain:   h.cookies.Domain,
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   h.cookies.Secure,
+		SameSite: h.cookies.SameSite,
+	})
+	// The CSRF cookie is readable by scripts so the client can echo it in the header.
+	http.SetCookie(w, &http.Cookie{
+		Name:     h.cookies.CSRFCookie,
+		Value:    csrfToken,
+		Path:     "/",
+		Domain:   h.cookies.Domain,
+		Expires:  expires,
+		Secure:   h.cookies.Secure,
+		SameSite: h.cookies.SameSite,
+	})
+}
+
+func (h *Handler) clearSessionCookies(w http.ResponseWriter) {
+	if !h.cookieMode() {
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     h.cookies.RefreshCookie,
+		Value:    "",
+		Path:     refreshCookiePath,
+		Domain:   h.cookies.Domain,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.cookies.Secure,
+		SameSite: h.cookies.SameSite,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     h.cookies.CSRFCookie,
+		Value:    "",
+		Path:     "/",
+		Domain:   h.cookies.Domain,
+		MaxAge:   -1,
+		Secure:   h.cookies.Secure,
+		SameSite: h.cookies.SameSite,
+	})
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/synergyvets/platform/internal/mailer"
+)
+
+// sendVerificationEmail delivers the verification link. Failures are logged rather than
+// returned so that registration succeeds and the user can request a new email.
+func (s *Service) sendVerificationEmail(ctx context.Context, email, token string) {
+	link := s.link("/auth/verify-email", token)
+
+	err := s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Confirm your Synergy Vets email address",
+		Text: fmt.Sprintf("Welcome to Synergy Vets!\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s. If you did not create an account you can ignore this email.\n",
+			link, describeTTL(s.config.VerificationTokenTTL)),
+	})
+	if err != nil {
+		s.logger.Warn().Err(err).Str("email", email).Msg("failed to send verification email")
+	}
+}
+
+// sendEmailChangeVerification asks the new address to confirm an email change, logging failures.
+func (s *Service) sendEmailChangeVerification(ctx context.Context, email, token string) {
+	link := s.link("/auth/verify-email", token)
+
+	err := s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Confirm your new Synergy Vets email address",
+		Text: fmt.Sprintf("We received a request to use this address for your Synergy Vets account.\n\nConfirm the change by opening the link below:\n\n%s\n\nThe link expires in %s. Until then your account keeps its current address. If you did not request this you can ignore this email.\n",
+			link, describeTTL(s.config.VerificationTokenTTL)),
+	})
+	if err != nil {
+		s.logger.Warn().Err(err).Str("email", email).Msg("failed to send email change verification")
+	}
+}
+
+// sendEmailChangedNotice tells the previous address that the account email changed, logging failures.
+func (s *Service) sendEmailChangedNotice(ctx context.Context, previousEmail, newEmail string) {
+	err := s.mailer.Send(ctx, mailer.Message{
+		To:      previousEmail,
+		Subject: "Your Synergy Vets email address was changed",
+		Text: fmt.Sprintf("The email address for your Synergy Vets account was changed to %s.\n\nIf you did not make this change, reset your password and contact support immediately.\n",
+			newEmail),
+	})
+	if err != nil {
+		s.logger.Warn().Err(err).Str("email", previousEmail).Msg("failed to send email changed notice")
+	}
+}
+
+// sendStaffInvitationEmail delivers a staff invitation link, logging failures. The invitation
+// can be sent again if delivery fails.
+func (s *Service) sendStaffInvitationEmail(ctx context.Context, email, role, token string) {
+	link := s.link("/auth/accept-invite", token)
+
+	err := s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "You have been invited to the Synergy Vets staff portal",
+		Text: fmt.Sprintf("You have been invited to join the Synergy Vets staff portal as %s.\n\nSet your password and activate your account by opening the link below:\n\n%s\n\nThe invitation expires in %s and can only be used once. If you were not expecting it you can ignore this email.\n",
+			describeRole(role), link, describeTTL(s.config.InvitationTTL)),
+	})
+	if err != nil {
+		s.logger.Warn().Err(err).Str("email", email).Msg("failed to send staff invitation email")
+	}
+}
+
+// sendPasswordResetEmail delivers the password reset link, logging failures.
+func (s *Service) sendPasswordResetEmail(ctx context.Context, email, token string) {
+	link := s.link("/auth/reset-password", token)
+
+	err := s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Reset your Synergy Vets password",
+		Text: fmt.Sprintf("We received a request to reset the password for your Synergy Vets account.\n\nChoose a new password by opening the link below:\n\n%s\n\nThe link expires in %s and can only be used once. If you did not request a reset you can ignore this email.\n",
+			link, describeTTL(s.config.PasswordResetTokenTTL)),
+	})
+	if err != nil {
+		s.logger.Warn().Err(err).Str("email", email).Msg("failed to send password reset email")
+	}
+}
+
+// sendMagicLinkEmail delivers a passwordless login link, logging failures.
+func (s *Service) sendMagicLinkEmail(ctx context.Context, email, token string) {
+	link := s.link("/auth/magic-link", token)
+
+	err := s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Your Synergy Vets login link",
+		Text: fmt.Sprintf("Use the link below to log in to Synergy Vets:\n\n%s\n\nThe link expires in %s, can only be used once and must be opened in the same browser you requested it from. If you did not request it you can ignore this email.\n",
+			link, describeTTL(s.config.MagicLinkTTL)),
+	})
+	if err != nil {
+		s.logger.Warn().Err(err).Str("email", email).Msg("failed to send magic link email")
+	}
+}
+
+// sendErasureRequestedEmail confirms a right-to-erasure request and when it will be answered,
+// logging failures.
+func (s *Service) sendErasureRequestedEmail(ctx context.Context, email string, dueAt time.Time) {
+	err := s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "We received your request to delete your Synergy Vets data",
+		Text: fmt.Sprintf("We received your request to delete your Synergy Vets account and personal data.\n\nOur team will review it and respond by %s. Until then your account stays open, and you can download a copy of your data from your account settings.\n\nIf you did not make this request, please contact us.\n",
+			dueAt.UTC().Format("2 January 2006")),
+	})
+	if err != nil {
+		s.logger.Warn().Err(err).Str("email", email).Msg("failed to send erasure request email")
+	}
+}
+
+// sendErasureCompletedEmail tells the user their data has been erased, logging failures. It is
+// the last message sent to the address.
+func (s *Service) sendErasureCompletedEmail(ctx context.Context, email string) {
+	err := s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Your Synergy Vets data has been deleted",
+		Text:    "Your Synergy Vets account and the personal data linked to it have been deleted, as you requested.\n\nWe keep anonymous application statistics that can no longer be linked to you. We will not email this address again.\n",
+	})
+	if err != nil {
+		s.logger.Warn().Err(err).Str("email", email).Msg("failed to send erasure completed email")
+	}
+}
+
+// sendErasureRejectedEmail explains why a right-to-erasure request was declined, logging
+// failures.
+func (s *Service) sendErasureRejectedEmail(ctx context.Context, email, note string) {
+	err := s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "About your request to delete your Synergy Vets data",
+		Text: fmt.Sprintf("We reviewed your request to delete your Synergy Vets account and personal data and are unable to complete it:\n\n%s\n\nIf you have questions about this decision, please contact us.\n",
+			note),
+	})
+	if err != nil {
+		s.logger.Warn().Err(err).Str("email", email).Msg("failed to send erasure rejected email")
+	}
+}
+
+// link builds an absolute web URL for the given path carrying a token query parameter.
+func (s *Service) link(path, token string) string {
+	return fmt.Sprintf("%s%s?token=%s", s.config.AppURL, path, url.QueryEscape(token))
+}
+
+// describeRole names a staff role in words suitable for email copy.
+func describeRole(role string) string {
+	switch role {
+	case RoleStaffAdmin:
+		return "an administrator"
+	case RoleStaffEditor:
+		return "an editor"
+	default:
+		return role
+	}
+}
+
+// describeTTL renders a token lifetime in words suitable for email copy.
+func describeTTL(d time.Duration) string {
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		hours := int(d / time.Hour)
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	case d >= time.Minute:
+		minutes := int(d / time.Minute)
+		if minutes == 1 {
+			return "1 minute"
+		}
+		return fmt.Sprintf("%d minutes", minutes)
+	default:
+		return d.String()
+	}
+}
@@ -1,19 +1,28 @@
 package auth
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
-	"net"
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/clientip"
+	"github.com/synergyvets/platform/internal/queries"
 )
 
 // Handler exposes HTTP endpoints for authentication operations.
 type Handler struct {
 	service *Service
+	cookies CookieConfig
 }
 
 // NewHandler builds an auth HTTP handler layer.
@@ -25,8 +34,76 @@ func NewHandler(service *Service) *Handler {
 func (h *Handler) Routes(r chi.Router) {
 	r.Post("/register", h.handleRegister)
 	r.Post("/login", h.handleLogin)
+	r.Post("/login/mfa", h.handleLoginMFA)
+	r.Get("/oidc/providers", h.handleOIDCProviders)
+	r.Post("/oidc/{provider}/start", h.handleStartOIDC)
+	r.Post("/oidc/{provider}/callback", h.handleOIDCCallback)
 	r.Post("/refresh", h.handleRefresh)
 	r.Post("/logout", h.handleLogout)
+	r.Post("/verify-email", h.handleVerifyEmail)
+	r.Post("/resend-verification", h.handleResendVerification)
+	r.Post("/forgot-password", h.handleForgotPassword)
+	r.Post("/reset-password", h.handleResetPassword)
+	r.Post("/magic-link", h.handleRequestMagicLink)
+	r.Post("/magic-link/consume", h.handleConsumeMagicLink)
+	r.Post("/accept-invite", h.handleAcceptInvitation)
+}
+
+// WellKnownRoutes registers public discovery documents such as the JWKS.
+func (h *Handler) WellKnownRoutes(r chi.Router) {
+	r.Get("/jwks.json", h.handleJWKS)
+}
+
+// StaffRoutes registers staff-only auth administration endpoints.
+// The router is expected to apply staff authentication.
+func (h *Handler) StaffRoutes(r chi.Router) {
+	r.With(h.RequirePermission(PermUsersManage)).Post("/users/{id}/unlock", h.handleUnlockUser)
+	r.With(h.RequirePermission(PermUsersManage)).Post("/users/{id}/mfa/reset", h.handleResetUserMFA)
+	r.With(h.RequirePermission(PermUsersManage)).Get("/invitations", h.handleListInvitations)
+	r.With(h.RequirePermission(PermUsersManage)).Post("/invitations", h.handleInviteStaff)
+	r.With(h.RequirePermission(PermUsersManage)).Delete("/invitations/{id}", h.handleRevokeInvitation)
+	r.With(h.RequirePermission(PermAPIKeysManage)).Get("/api-keys", h.handleListAPIKeys)
+	r.With(h.RequirePermission(PermAPIKeysManage)).Post("/api-keys", h.handleCreateAPIKey)
+	r.With(h.RequirePermission(PermAPIKeysManage)).Delete("/api-keys/{id}", h.handleRevokeAPIKey)
+	r.With(h.RequirePermission(PermMetricsRead)).Get("/metrics", h.handleMetrics)
+}
+
+// AdminRoutes registers user management endpoints for administrators.
+// The router is expected to apply staff authentication.
+func (h *Handler) AdminRoutes(r chi.Router) {
+	r.With(h.RequirePermission(PermUsersRead)).Get("/users", h.handleListUsers)
+	r.With(h.RequirePermission(PermUsersRead)).Get("/users/{id}", h.handleGetUser)
+	r.With(h.RequirePermission(PermUsersManage)).Put("/users/{id}/role", h.handleChangeUserRole)
+	r.With(h.RequirePermission(PermUsersManage)).Post("/users/{id}/suspend", h.handleSuspendUser)
+	r.With(h.RequirePermission(PermUsersManage)).Post("/users/{id}/reactivate", h.handleReactivateUser)
+	r.With(h.RequirePermission(PermUsersManage)).Post("/users/{id}/password-reset", h.handleForcePasswordReset)
+	r.With(h.RequirePermission(PermUsersManage)).Delete("/users/{id}/sessions", h.handleRevokeUserSessions)
+	r.With(h.RequirePermission(PermUsersImpersonate)).Post("/users/{id}/impersonate", h.handleStartImpersonation)
+	r.With(h.RequirePermission(PermAuditRead)).Get("/impersonations", h.handleListImpersonations)
+	r.With(h.RequirePermission(PermUsersImpersonate)).Delete("/impersonations/{id}", h.handleStopImpersonation)
+	r.With(h.RequirePermission(PermPrivacyManage)).Get("/erasure-requests", h.handleListErasureRequests)
+	r.With(h.RequirePermission(PermPrivacyManage)).Post("/erasure-requests/{id}/complete", h.handleCompleteErasure)
+	r.With(h.RequirePermission(PermPrivacyManage)).Post("/erasure-requests/{id}/reject", h.handleRejectErasure)
+}
+
+// MeRoutes registers self-service endpoints for the authenticated user.
+// The router is expected to apply RequireUser.
+func (h *Handler) MeRoutes(r chi.Router) {
+	r.Get("/", h.handleGetAccount)
+	r.Delete("/", h.handleCloseAccount)
+	r.Post("/password", h.handleChangePassword)
+	r.Post("/email", h.handleChangeEmail)
+	r.Get("/sessions", h.handleListSessions)
+	r.Post("/sessions/revoke-others", h.handleRevokeOtherSessions)
+	r.Delete("/sessions/{id}", h.handleRevokeSession)
+	r.Get("/mfa", h.handleMFAStatus)
+	r.Post("/mfa/enroll", h.handleBeginMFAEnrollment)
+	r.Post("/mfa/confirm", h.handleConfirmMFAEnrollment)
+	r.Post("/mfa/recovery-codes", h.handleRegenerateRecoveryCodes)
+	r.Post("/mfa/disable", h.handleDisableMFA)
+	r.Post("/data-export", h.handleDataExport)
+	r.Get("/erasure-request", h.handleGetErasureRequest)
+	r.Post("/erasure-request", h.handleRequestErasure)
 }
 
 type registerRequest struct {
@@ -39,15 +116,34 @@ type loginRequest struct {
 	Password string `json:"password"`
 }
 
+type loginMFARequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
 type refreshRequest struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
 type authResponse struct {
-	AccessToken        string       `json:"access_token"`
-	RefreshToken       string       `json:"refresh_token"`
-	RefreshTokenExpiry string       `json:"refresh_token_expiry"`
-	User               userResponse `json:"user"`
+	AccessToken           string       `json:"access_token"`
+	RefreshToken          string       `json:"refresh_token,omitempty"`
+	RefreshTokenExpiry    string       `json:"refresh_token_expiry"`
+	CSRFToken             string       `json:"csrf_token,omitempty"`
+	User                  userResponse `json:"user"`
+	MFAEnrollmentRequired bool         `json:"mfa_enrollment_required,omitempty"`
+}
+
+type mfaChallengeResponse struct {
+	MFARequired    bool   `json:"mfa_required"`
+	MFAToken       string `json:"mfa_token"`
+	MFATokenExpiry string `json:"mfa_token_expiry"`
+}
+
+// metricsResponse is deliberately limited to application counters; process details such as the
+// command line and memory statistics are not exposed over the API.
+type metricsResponse struct {
+	AuthPrincipalCache PrincipalCacheStats `json:"auth_principal_cache"`
 }
 
 type userResponse struct {
@@ -69,7 +165,7 @@ func (h *Handler) handleRegister(w http.ResponseWriter, r *http.Request) {
 		Password: req.Password,
 	}, SessionMetadata{
 		UserAgent: r.UserAgent(),
-		IP:        remoteIP(r),
+		IP:        clientip.FromRequest(r),
 	})
 	if err != nil {
 		switch {
@@ -85,7 +181,7 @@ func (h *Handler) handleRegister(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, toAuthResponse(result))
+	h.writeAuthResponse(w, http.StatusCreated, result)
 }
 
 func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
@@ -99,37 +195,87 @@ func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 		Email:     req.Email,
 		Password:  req.Password,
 		UserAgent: r.UserAgent(),
-		IP:        remoteIP(r),
+		IP:        clientip.FromRequest(r),
 	})
 	if err != nil {
+		setRetryAfter(w, err)
+
 		switch {
 		case errors.Is(err, ErrInvalidCredentials):
 			writeError(w, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, ErrAccountLocked):
+			writeError(w, http.StatusLocked, ErrAccountLocked.Error())
+		case errors.Is(err, ErrTooManyAttempts):
+			writeError(w, http.StatusTooManyRequests, ErrTooManyAttempts.Error())
+		case errors.Is(err, ErrInactiveAccount):
+			writeError(w, http.StatusForbidden, err.Error())
 		default:
 			writeError(w, http.StatusInternalServerError, "failed to authenticate user")
 		}
 		return
 	}
 
-	writeJSON(w, http.StatusOK, toAuthResponse(result))
+	h.writeLoginResponse(w, result)
 }
 
-func (h *Handler) handleRefresh(w http.ResponseWriter, r *http.Request) {
-	var req refreshRequest
+func (h *Handler) handleLoginMFA(w http.ResponseWriter, r *http.Request) {
+	var req loginMFARequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON payload")
 		return
 	}
 
-	result, err := h.service.Refresh(r.Context(), req.RefreshToken, SessionMetadata{
+	result, err := h.service.CompleteMFALogin(r.Context(), MFALoginInput{
+		ChallengeToken: req.MFAToken,
+		Code:           req.Code,
+		UserAgent:      r.UserAgent(),
+		IP:             clientip.FromRequest(r),
+	})
+	if err != nil {
+		setRetryAfter(w, err)
+
+		switch {
+		case errors.Is(err, ErrInvalidMFACode):
+			writeError(w, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, ErrInvalidMFAChallenge):
+			writeError(w, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, ErrExpiredMFAChallenge):
+			writeError(w, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, ErrAccountLocked):
+			writeError(w, http.StatusLocked, ErrAccountLocked.Error())
+		case errors.Is(err, ErrTooManyAttempts):
+			writeError(w, http.StatusTooManyRequests, ErrTooManyAttempts.Error())
+		case errors.Is(err, ErrInactiveAccount):
+			writeError(w, http.StatusForbidden, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to authenticate user")
+		}
+		return
+	}
+
+	h.writeAuthResponse(w, http.StatusOK, result)
+}
+
+func (h *Handler) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	refreshToken, ok := h.refreshTokenFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Refresh(r.Context(), refreshToken, SessionMetadata{
 		UserAgent: r.UserAgent(),
-		IP:        remoteIP(r),
+		IP:        clientip.FromRequest(r),
 	})
 	if err != nil {
 		switch {
 		case errors.Is(err, ErrInvalidRefreshToken):
+			h.clearSessionCookies(w)
 			writeError(w, http.StatusUnauthorized, err.Error())
 		case errors.Is(err, ErrExpiredRefreshToken):
+			h.clearSessionCookies(w)
+			writeError(w, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, ErrRefreshTokenReused):
+			h.clearSessionCookies(w)
 			writeError(w, http.StatusUnauthorized, err.Error())
 		default:
 			writeError(w, http.StatusInternalServerError, "failed to refresh session")
@@ -137,19 +283,19 @@ func (h *Handler) handleRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, toAuthResponse(result))
+	h.writeAuthResponse(w, http.StatusOK, result)
 }
 
 func (h *Handler) handleLogout(w http.ResponseWriter, r *http.Request) {
-	var req refreshRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+	refreshToken, ok := h.refreshTokenFromRequest(w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.service.Logout(r.Context(), req.RefreshToken); err != nil {
+	if err := h.service.Logout(r.Context(), refreshToken); err != nil {
 		switch {
 		case errors.Is(err, ErrInvalidRefreshToken):
+			h.clearSessionCookies(w)
 			writeError(w, http.StatusBadRequest, err.Error())
 		default:
 			writeError(w, http.StatusInternalServerError, "failed to log out")
@@ -157,23 +303,98 @@ func (h *Handler) handleLogout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.clearSessionCookies(w)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *Handler) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, metricsResponse{AuthPrincipalCache: h.service.PrincipalCacheStats()})
+}
+
+func formatNullTime(value sql.NullTime) *string {
+	if !value.Valid {
+		return nil
+	}
+	formatted := value.Time.UTC().Format(time.RFC3339)
+	return &formatted
+}
+
+func formatNullUUID(value uuid.NullUUID) *string {
+	if !value.Valid {
+		return nil
+	}
+	formatted := value.UUID.String()
+	return &formatted
+}
+
+func (h *Handler) handleJWKS(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(w, http.StatusOK, h.service.JWKS())
+}
+
 func toAuthResponse(result AuthResult) authResponse {
 	return authResponse{
-		AccessToken:        result.AccessToken,
-		RefreshToken:       result.RefreshToken,
-		RefreshTokenExpiry: result.RefreshTokenExpiry.UTC().Format(time.RFC3339),
-		User: userResponse{
-			ID:     result.User.ID.String(),
-			Email:  result.User.Email,
-			Role:   result.User.Role,
-			Status: result.User.Status,
-		},
+		AccessToken:           result.AccessToken,
+		RefreshToken:          result.RefreshToken,
+		RefreshTokenExpiry:    result.RefreshTokenExpiry.UTC().Format(time.RFC3339),
+		User:                  toUserResponse(result.User),
+		MFAEnrollmentRequired: result.MFAEnrollmentRequired,
 	}
 }
 
+// writeLoginResponse writes either the MFA challenge or the issued session.
+func (h *Handler) writeLoginResponse(w http.ResponseWriter, result AuthResult) {
+	if result.MFAChallenge != nil {
+		writeJSON(w, http.StatusOK, mfaChallengeResponse{
+			MFARequired:    true,
+			MFAToken:       result.MFAChallenge.Token,
+			MFATokenExpiry: result.MFAChallenge.ExpiresAt.UTC().Format(time.RFC3339),
+		})
+		return
+	}
+
+	h.writeAuthResponse(w, http.StatusOK, result)
+}
+
+// setRetryAfter advertises how long a locked-out client must wait.
+func setRetryAfter(w http.ResponseWriter, err error) {
+	var lockout *LockoutError
+	if errors.As(err, &lockout) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(lockout.RetryAfter.Seconds()))))
+	}
+}
+
+func toUserResponse(user queries.User) userResponse {
+	return userResponse{
+		ID:     user.ID.String(),
+		Email:  user.Email,
+		Role:   user.Role,
+		Status: user.Status,
+	}
+}
+
+// parseIntParam reads an optional integer query parameter, which must be at least min and, when
+// max is positive, at most max. Problems are recorded in fields under the parameter name.
+func parseIntParam(query url.Values, name string, fallback, min, max int, fields map[string]string) int {
+	value := strings.TrimSpace(query.Get(name))
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+	switch {
+	case err != nil:
+		fields[name] = "must be a whole number"
+	case parsed < min:
+		fields[name] = fmt.Sprintf("must be at least %d", min)
+	case max > 0 && parsed > max:
+		fields[name] = fmt.Sprintf("must be at most %d", max)
+	default:
+		return parsed
+	}
+	return fallback
+}
+
 func writeJSON(w http.ResponseWriter, status int, payload any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -183,17 +404,3 @@ func writeJSON(w http.ResponseWriter, status int, payload any) {
 func writeError(w http.ResponseWriter, status int, message string) {
 	writeJSON(w, status, map[string]string{"error": message})
 }
-
-func remoteIP(r *http.Request) string {
-	ip := r.Header.Get("X-Forwarded-For")
-	if ip != "" {
-		parts := strings.Split(ip, ",")
-		return strings.TrimSpace(parts[0])
-	}
-
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		return r.RemoteAddr
-	}
-	return host
-}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/synergyvets/platform/internal/db"
+	"github.com/synergyvets/platform/internal/mailer"
+	"github.com/synergyvets/platform/internal/queries"
+	"github.com/synergyvets/platform/internal/store"
+)
+
+// Tests that need Postgres run against TEST_DATABASE_URL, e.g. the docker compose database,
+// and are skipped when it is not set. Every test works on its own users, so they can share a
+// database with development data.
+
+const testPassword = "correct horse battery staple"
+
+var migrateOnce sync.Once
+
+// testClock is a settable clock for Service.WithNow.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newTestService returns a Service on the test database whose clock starts at the current time
+// and only moves when advanced.
+func newTestService(t *testing.T, cfg Config) (*Service, *testClock) {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	database, err := db.Connect(context.Background(), dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	var migrateErr error
+	migrateOnce.Do(func() { migrateErr = db.Migrate(database) })
+	if migrateErr != nil {
+		t.Fatalf("migrate: %v", migrateErr)
+	}
+
+	if cfg.Secret == "" {
+		cfg.Secret = "test-secret"
+	}
+	// Cheap hashes keep the tests fast; they are still argon2id.
+	if cfg.Password == (PasswordParams{}) {
+		cfg.Password = PasswordParams{Time: 1, Memory: 1024, Threads: 1}
+	}
+
+	clock := &testClock{now: time.Now().UTC().Truncate(time.Second)}
+	service := NewService(store.New(database), zerolog.Nop(), cfg).WithNow(clock.Now)
+	return service, clock
+}
+
+// createTestUser inserts an active user with testPassword and removes it when the test ends.
+func createTestUser(t *testing.T, s *Service, role string) queries.User {
+	t.Helper()
+	ctx := context.Background()
+
+	hash, err := hashPassword(testPassword, s.config.Password)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+
+	user, err := s.store.Queries().CreateUser(ctx, queries.CreateUserParams{
+		Email:        fmt.Sprintf("test-%s@example.test", uuid.NewString()),
+		PasswordHash: hash,
+		Role:         sql.NullString{String: role, Valid: role != ""},
+		Status:       sql.NullString{String: statusActive, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	t.Cleanup(func() {
+		q := s.store.Queries()
+		_ = q.DeleteLoginThrottle(ctx, queries.DeleteLoginThrottleParams{Scope: throttleScopeAccount, Key: user.Email})
+		_ = q.DeleteUser(ctx, user.ID)
+	})
+	return user
+}
+
+// login signs user in with testPassword and fails the test on error.
+func login(t *testing.T, s *Service, user queries.User) AuthResult {
+	t.Helper()
+
+	result, err := s.Login(context.Background(), LoginInput{Email: user.Email, Password: testPassword})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	return result
+}
+
+// recordingMailer keeps sent messages so tests can follow emailed links.
+type recordingMailer struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (m *recordingMailer) Send(_ context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+var emailedTokenPattern = regexp.MustCompile(`[?&]token=([^\s&]+)`)
+
+// token returns the token of the latest link emailed to address.
+func (m *recordingMailer) token(t *testing.T, address string) string {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To != address {
+			continue
+		}
+		if match := emailedTokenPattern.FindStringSubmatch(m.messages[i].Text); match != nil {
+			token, err := url.QueryUnescape(match[1])
+			if err != nil {
+				t.Fatalf("unescape token: %v", err)
+			}
+			return token
+		}
+	}
+	t.Fatalf("no link emailed to %s", address)
+	return ""
+}
+
+// staffContext is the principal of a signed-in staff user.
+func staffContext(user queries.User) UserContext {
+	return UserContext{ID: user.ID, Email: user.Email, Role: user.Role, Permissions: PermissionsForRole(user.Role)}
+}
@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+// Impersonation states reported by Impersonation.Status.
+const (
+	impersonationActive  = "active"
+	impersonationEnded   = "ended"
+	impersonationExpired = "expired"
+)
+
+var (
+	// ErrImpersonationReadOnly indicates an impersonation token attempted a state-changing request.
+	ErrImpersonationReadOnly = errors.New("impersonation sessions are read-only")
+	// ErrInvalidImpersonationTarget indicates the user cannot be impersonated.
+	ErrInvalidImpersonationTarget = errors.New("only active candidate accounts can be impersonated")
+	// ErrImpersonationReasonRequired indicates no reason was given for the impersonation.
+	ErrImpersonationReasonRequired = errors.New("a reason is required to impersonate a user")
+	// ErrImpersonationNotFound indicates no ongoing impersonation matched the identifier.
+	ErrImpersonationNotFound = errors.New("impersonation not found")
+)
+
+// Impersonation describes an administrator viewing the product as another user.
+type Impersonation struct {
+	ID           uuid.UUID
+	ActorID      uuid.UUID
+	TargetUserID uuid.UUID
+	Reason       string
+	Status       string
+	StartedAt    time.Time
+	ExpiresAt    time.Time
+	EndedAt      sql.NullTime
+}
+
+// StartedImpersonation carries the access token for acting as the target. No refresh token is
+// issued, so the impersonation ends when the token expires at the latest.
+type StartedImpersonation struct {
+	Impersonation
+	AccessToken string
+}
+
+// StartImpersonation issues a short-lived, read-only access token for a candidate account on
+// behalf of an administrator and records who did it, why and when.
+func (s *Service) StartImpersonation(ctx context.Context, actor UserContext, targetID uuid.UUID, reason string, meta SessionMetadata) (StartedImpersonation, error) {
+	if actor.IsAPIKey() {
+		return StartedImpersonation{}, ErrHumanUserRequired
+	}
+	if actor.IsImpersonated() || actor.ID == targetID {
+		return StartedImpersonation{}, ErrInvalidImpersonationTarget
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return StartedImpersonation{}, ErrImpersonationReasonRequired
+	}
+
+	now := s.now()
+	var (
+		record queries.ImpersonationSession
+		token  string
+	)
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		target, err := getManagedUser(ctx, q, targetID)
+		if err != nil {
+			return err
+		}
+		if target.Role != RoleSeeker || strings.ToLower(target.Status) != statusActive {
+			return ErrInvalidImpersonationTarget
+		}
+
+		params := queries.CreateImpersonationSessionParams{
+			ActorID:      actor.ID,
+			TargetUserID: target.ID,
+			Reason:       reason,
+			Jti:          uuid.New(),
+			UserAgent:    sql.NullString{String: meta.UserAgent, Valid: strings.TrimSpace(meta.UserAgent) != ""},
+			ExpiresAt:    now.Add(s.config.ImpersonationTTL),
+		}
+		if inet, ok := parseIP(meta.IP); ok {
+			params.Ip = inet
+		}
+
+		record, err = q.CreateImpersonationSession(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		token, err = generateImpersonationToken(s.keys, target.ID, target.Role, record.ID, record.Jti, actor.ID, s.config.ImpersonationTTL, now)
+		if err != nil {
+			return err
+		}
+
+		return s.recordAudit(ctx, q, auditEntry{
+			ActorID:    actor.ID,
+			Action:     auditImpersonationStarted,
+			TargetType: auditTargetUser,
+			TargetID:   target.ID,
+			Metadata:   map[string]any{"impersonation_id": record.ID.String(), "reason": reason},
+		})
+	})
+	if err != nil {
+		return StartedImpersonation{}, err
+	}
+
+	s.logger.Info().
+		Str("event", "impersonation_started").
+		Str("impersonation_id", record.ID.String()).
+		Str("actor_id", actor.ID.String()).
+		Str("user_id", targetID.String()).
+		Msg("impersonation started")
+
+	return StartedImpersonation{
+		Impersonation: s.toImpersonation(record),
+		AccessToken:   token,
+	}, nil
+}
+
+// StopImpersonation ends an ongoing impersonation and revokes its access token.
+func (s *Service) StopImpersonation(ctx context.Context, actor UserContext, id uuid.UUID) error {
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		record, err := q.EndImpersonationSession(ctx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrImpersonationNotFound
+			}
+			return err
+		}
+
+		if err := q.RevokeAccessToken(ctx, queries.RevokeAccessTokenParams{
+			Jti:       record.Jti,
+			UserID:    record.TargetUserID,
+			ExpiresAt: record.ExpiresAt,
+		}); err != nil {
+			return err
+		}
+
+		return s.recordAudit(ctx, q, auditEntry{
+			ActorID:    actor.ID,
+			Action:     auditImpersonationStopped,
+			TargetType: auditTargetUser,
+			TargetID:   record.TargetUserID,
+			Metadata:   map[string]any{"impersonation_id": record.ID.String()},
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.revocations.markStale()
+	s.logger.Info().Str("event", "impersonation_stopped").Str("impersonation_id", id.String()).Str("actor_id", actor.ID.String()).Msg("impersonation stopped")
+	return nil
+}
+
+// ListImpersonations returns every impersonation, most recent first.
+func (s *Service) ListImpersonations(ctx context.Context) ([]Impersonation, error) {
+	records, err := s.store.Queries().ListImpersonationSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	impersonations := make([]Impersonation, 0, len(records))
+	for _, record := range records {
+		impersonations = append(impersonations, s.toImpersonation(record))
+	}
+	return impersonations, nil
+}
+
+// checkImpersonation accepts an impersonation token only while the target is still a candidate
+// and the administrator behind it is still allowed to impersonate, so demoting or suspending
+// them ends their impersonations too.
+func (s *Service) checkImpersonation(ctx context.Context, target Principal, actor *actorClaim) error {
+	if target.Role != RoleSeeker {
+		return ErrInvalidAccessToken
+	}
+
+	actorID, err := uuid.Parse(actor.Subject)
+	if err != nil {
+		return ErrInvalidAccessToken
+	}
+
+	principal, err := s.loadPrincipal(ctx, actorID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidAccessToken
+		}
+		return err
+	}
+	if strings.ToLower(principal.Status) != statusActive || !slices.Contains(PermissionsForRole(principal.Role), PermUsersImpersonate) {
+		return ErrInvalidAccessToken
+	}
+	return nil
+}
+
+func (s *Service) toImpersonation(record queries.ImpersonationSession) Impersonation {
+	impersonation := Impersonation{
+		ID:           record.ID,
+		ActorID:      record.ActorID,
+		TargetUserID: record.TargetUserID,
+		Reason:       record.Reason,
+		Status:       impersonationActive,
+		StartedAt:    record.StartedAt,
+		ExpiresAt:    record.ExpiresAt,
+		EndedAt:      record.EndedAt,
+	}
+
+	switch {
+	case record.EndedAt.Valid:
+		impersonation.Status = impersonationEnded
+	case !record.ExpiresAt.After(s.now()):
+		impersonation.Status = impersonationExpired
+	}
+	return impersonation
+}
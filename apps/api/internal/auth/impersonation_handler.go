@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/clientip"
+)
+
+type startImpersonationRequest struct {
+	Reason string `json:"reason"`
+}
+
+type impersonationResponse struct {
+	ID           string  `json:"id"`
+	ActorID      string  `json:"actor_id"`
+	TargetUserID string  `json:"target_user_id"`
+	Reason       string  `json:"reason"`
+	Status       string  `json:"status"`
+	StartedAt    string  `json:"started_at"`
+	ExpiresAt    string  `json:"expires_at"`
+	EndedAt      *string `json:"ended_at"`
+}
+
+type startedImpersonationResponse struct {
+	impersonationResponse
+	AccessToken string `json:"access_token"`
+}
+
+func (h *Handler) handleStartImpersonation(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := managedUserRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req startImpersonationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	started, err := h.service.StartImpersonation(r.Context(), actor, userID, req.Reason, SessionMetadata{
+		UserAgent: r.UserAgent(),
+		IP:        clientip.FromRequest(r),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, ErrImpersonationReasonRequired):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrHumanUserRequired):
+			writeError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, ErrInvalidImpersonationTarget):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to start impersonation")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, startedImpersonationResponse{
+		impersonationResponse: toImpersonationResponse(started.Impersonation),
+		AccessToken:           started.AccessToken,
+	})
+}
+
+func (h *Handler) handleListImpersonations(w http.ResponseWriter, r *http.Request) {
+	impersonations, err := h.service.ListImpersonations(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list impersonations")
+		return
+	}
+
+	resp := make([]impersonationResponse, 0, len(impersonations))
+	for _, impersonation := range impersonations {
+		resp = append(resp, toImpersonationResponse(impersonation))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"impersonations": resp})
+}
+
+func (h *Handler) handleStopImpersonation(w http.ResponseWriter, r *http.Request) {
+	actor, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	impersonationID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, ErrImpersonationNotFound.Error())
+		return
+	}
+
+	if err := h.service.StopImpersonation(r.Context(), actor, impersonationID); err != nil {
+		switch {
+		case errors.Is(err, ErrImpersonationNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to stop impersonation")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toImpersonationResponse(impersonation Impersonation) impersonationResponse {
+	return impersonationResponse{
+		ID:           impersonation.ID.String(),
+		ActorID:      impersonation.ActorID.String(),
+		TargetUserID: impersonation.TargetUserID.String(),
+		Reason:       impersonation.Reason,
+		Status:       impersonation.Status,
+		StartedAt:    impersonation.StartedAt.UTC().Format(time.RFC3339),
+		ExpiresAt:    impersonation.ExpiresAt.UTC().Format(time.RFC3339),
+		EndedAt:      formatNullTime(impersonation.EndedAt),
+	}
+}
@@ -0,0 +1,251 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+func TestStartImpersonationPreconditions(t *testing.T) {
+	// These are refused before the database is touched.
+	s := &Service{}
+	actorID := uuid.New()
+
+	tests := []struct {
+		name   string
+		actor  UserContext
+		target uuid.UUID
+		reason string
+		want   error
+	}{
+		{name: "api key", actor: UserContext{ID: actorID, APIKeyID: uuid.New()}, target: uuid.New(), reason: "support", want: ErrHumanUserRequired},
+		{name: "self", actor: UserContext{ID: actorID}, target: actorID, reason: "support", want: ErrInvalidImpersonationTarget},
+		{name: "already impersonating", actor: UserContext{ID: actorID, ImpersonatorID: uuid.New()}, target: uuid.New(), reason: "support", want: ErrInvalidImpersonationTarget},
+		{name: "no reason", actor: UserContext{ID: actorID}, target: uuid.New(), reason: "  ", want: ErrImpersonationReasonRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.StartImpersonation(context.Background(), tt.actor, tt.target, tt.reason, SessionMetadata{}); !errors.Is(err, tt.want) {
+				t.Errorf("StartImpersonation() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadOnlyMethod(t *testing.T) {
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{method: http.MethodGet, want: true},
+		{method: http.MethodHead, want: true},
+		{method: http.MethodOptions, want: true},
+		{method: http.MethodPost},
+		{method: http.MethodPut},
+		{method: http.MethodPatch},
+		{method: http.MethodDelete},
+	}
+
+	for _, tt := range tests {
+		if got := readOnlyMethod(tt.method); got != tt.want {
+			t.Errorf("readOnlyMethod(%s) = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestStartImpersonationTargets(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+	ctx := context.Background()
+	admin := createTestUser(t, s, RoleStaffAdmin)
+	editor := createTestUser(t, s, RoleStaffEditor)
+	suspended := createTestUser(t, s, RoleSeeker)
+	if _, err := s.SuspendUser(ctx, staffContext(admin), suspended.ID, ""); err != nil {
+		t.Fatalf("suspend: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		target uuid.UUID
+		want   error
+	}{
+		{name: "staff account", target: editor.ID, want: ErrInvalidImpersonationTarget},
+		{name: "suspended candidate", target: suspended.ID, want: ErrInvalidImpersonationTarget},
+		{name: "missing user", target: uuid.New(), want: ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.StartImpersonation(ctx, staffContext(admin), tt.target, "support", SessionMetadata{}); !errors.Is(err, tt.want) {
+				t.Errorf("StartImpersonation() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestImpersonationLifecycle(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+	ctx := context.Background()
+	admin := createTestUser(t, s, RoleStaffAdmin)
+	seeker := createTestUser(t, s, RoleSeeker)
+
+	started, err := s.StartImpersonation(ctx, staffContext(admin), seeker.ID, "support ticket 42", SessionMetadata{})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if started.Status != impersonationActive || started.ActorID != admin.ID || started.TargetUserID != seeker.ID {
+		t.Errorf("impersonation = %+v", started.Impersonation)
+	}
+
+	principal, err := s.ValidateAccessToken(ctx, started.AccessToken)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if principal.ID != seeker.ID {
+		t.Errorf("principal = %s, want the target %s", principal.ID, seeker.ID)
+	}
+
+	if err := s.StopImpersonation(ctx, staffContext(admin), started.ID); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if _, err := s.ValidateAccessToken(ctx, started.AccessToken); !errors.Is(err, ErrAccessTokenRevoked) {
+		t.Errorf("validate after stop: got %v, want %v", err, ErrAccessTokenRevoked)
+	}
+	if err := s.StopImpersonation(ctx, staffContext(admin), started.ID); !errors.Is(err, ErrImpersonationNotFound) {
+		t.Errorf("second stop: got %v, want %v", err, ErrImpersonationNotFound)
+	}
+
+	if got := findImpersonation(t, s, started.ID); got.Status != impersonationEnded || !got.EndedAt.Valid {
+		t.Errorf("listed impersonation = %+v, want ended", got)
+	}
+}
+
+func TestImpersonationExpires(t *testing.T) {
+	s, clock := newTestService(t, Config{})
+	ctx := context.Background()
+	admin := createTestUser(t, s, RoleStaffAdmin)
+	seeker := createTestUser(t, s, RoleSeeker)
+
+	started, err := s.StartImpersonation(ctx, staffContext(admin), seeker.ID, "support", SessionMetadata{})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	clock.Advance(s.config.ImpersonationTTL + 1)
+	if _, err := s.ValidateAccessToken(ctx, started.AccessToken); !errors.Is(err, ErrAccessTokenExpired) {
+		t.Errorf("validate after expiry: got %v, want %v", err, ErrAccessTokenExpired)
+	}
+	if got := findImpersonation(t, s, started.ID); got.Status != impersonationExpired {
+		t.Errorf("status = %q, want %q", got.Status, impersonationExpired)
+	}
+}
+
+func TestImpersonationFollowsActor(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(t *testing.T, s *Service, other, actor queries.User)
+	}{
+		{
+			name: "actor demoted",
+			change: func(t *testing.T, s *Service, other, actor queries.User) {
+				if _, err := s.ChangeUserRole(context.Background(), staffContext(other), actor.ID, RoleStaffEditor); err != nil {
+					t.Fatalf("demote: %v", err)
+				}
+			},
+		},
+		{
+			name: "actor suspended",
+			change: func(t *testing.T, s *Service, other, actor queries.User) {
+				if _, err := s.SuspendUser(context.Background(), staffContext(other), actor.ID, ""); err != nil {
+					t.Fatalf("suspend: %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t, Config{})
+			ctx := context.Background()
+			other := createTestUser(t, s, RoleStaffAdmin)
+			actor := createTestUser(t, s, RoleStaffAdmin)
+			seeker := createTestUser(t, s, RoleSeeker)
+
+			started, err := s.StartImpersonation(ctx, staffContext(actor), seeker.ID, "support", SessionMetadata{})
+			if err != nil {
+				t.Fatalf("start: %v", err)
+			}
+			tt.change(t, s, other, actor)
+
+			if _, err := s.ValidateAccessToken(ctx, started.AccessToken); !errors.Is(err, ErrInvalidAccessToken) {
+				t.Errorf("validate: got %v, want %v", err, ErrInvalidAccessToken)
+			}
+		})
+	}
+}
+
+func TestImpersonationIsReadOnly(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+	admin := createTestUser(t, s, RoleStaffAdmin)
+	seeker := createTestUser(t, s, RoleSeeker)
+
+	started, err := s.StartImpersonation(context.Background(), staffContext(admin), seeker.ID, "support", SessionMetadata{})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	var seen UserContext
+	protected := NewHandler(s).RequireUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = UserFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		method string
+		want   int
+	}{
+		{method: http.MethodGet, want: http.StatusNoContent},
+		{method: http.MethodPost, want: http.StatusForbidden},
+		{method: http.MethodDelete, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/account", nil)
+			req.Header.Set("Authorization", "Bearer "+started.AccessToken)
+			recorder := httptest.NewRecorder()
+			protected.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, tt.want, recorder.Body)
+			}
+		})
+	}
+
+	if seen.ID != seeker.ID || seen.ImpersonatorID != admin.ID {
+		t.Errorf("user context = %+v, want %s impersonated by %s", seen, seeker.ID, admin.ID)
+	}
+}
+
+// findImpersonation returns the listed impersonation with the given ID.
+func findImpersonation(t *testing.T, s *Service, id uuid.UUID) Impersonation {
+	t.Helper()
+
+	impersonations, err := s.ListImpersonations(context.Background())
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, impersonation := range impersonations {
+		if impersonation.ID == id {
+			return impersonation
+		}
+	}
+	t.Fatalf("impersonation %s not listed", id)
+	return Impersonation{}
+}
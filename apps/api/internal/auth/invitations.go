@@ -0,0 +1,380 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+// Invitation states reported by StaffInvitation.Status.
+const (
+	invitationPending  = "pending"
+	invitationAccepted = "accepted"
+	invitationRevoked  = "revoked"
+	invitationExpired  = "expired"
+)
+
+var (
+	// ErrInvalidInvitation signals the invitation token could not be validated.
+	ErrInvalidInvitation = errors.New("invalid invitation")
+	// ErrExpiredInvitation indicates the invitation is no longer valid.
+	ErrExpiredInvitation = errors.New("invitation expired")
+	// ErrInvitationNotFound indicates no open invitation matched the identifier.
+	ErrInvitationNotFound = errors.New("invitation not found")
+	// ErrInvalidInvitationRole indicates the invited role is not a staff role.
+	ErrInvalidInvitationRole = errors.New("invitation role must be a staff role")
+	// ErrAdminExists indicates the first admin has already been created.
+	ErrAdminExists = errors.New("a staff admin already exists")
+)
+
+// StaffInvitation describes an invitation without its token.
+type StaffInvitation struct {
+	ID             uuid.UUID
+	Email          string
+	Role           string
+	Status         string
+	InvitedBy      uuid.NullUUID
+	AcceptedUserID uuid.NullUUID
+	ExpiresAt      time.Time
+	AcceptedAt     sql.NullTime
+	RevokedAt      sql.NullTime
+	CreatedAt      time.Time
+}
+
+// InviteStaffInput holds the address to invite and the role the account will be created with.
+type InviteStaffInput struct {
+	Email string
+	Role  string
+}
+
+// AcceptInvitationInput holds the emailed token and the password chosen by the invitee.
+type AcceptInvitationInput struct {
+	Token    string
+	Password string
+}
+
+// InviteStaff emails an invitation to join as staff with a pre-assigned role. Inviters cannot
+// grant permissions they do not hold themselves, and inviting an address again replaces any
+// open invitation for it.
+func (s *Service) InviteStaff(ctx context.Context, inviter UserContext, input InviteStaffInput) (StaffInvitation, error) {
+	if inviter.IsAPIKey() {
+		return StaffInvitation{}, ErrHumanUserRequired
+	}
+
+	email, err := normalizeEmail(input.Email)
+	if err != nil {
+		return StaffInvitation{}, err
+	}
+	role := strings.ToLower(strings.TrimSpace(input.Role))
+	if !IsStaffRole(role) {
+		return StaffInvitation{}, ErrInvalidInvitationRole
+	}
+	if !inviter.Can(PermissionsForRole(role)...) {
+		return StaffInvitation{}, ErrForbidden
+	}
+
+	var (
+		record queries.StaffInvitation
+		token  string
+	)
+	err = s.store.WithTx(ctx, func(q *queries.Queries) error {
+		if _, err := q.GetUserByEmail(ctx, email); err == nil {
+			return ErrEmailInUse
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		if err := q.RevokeOpenStaffInvitations(ctx, email); err != nil {
+			return err
+		}
+
+		var hashed string
+		var expiresAt time.Time
+		token, hashed, expiresAt, err = generateOpaqueToken(s.config.InvitationTTL, s.now())
+		if err != nil {
+			return err
+		}
+
+		record, err = q.CreateStaffInvitation(ctx, queries.CreateStaffInvitationParams{
+			Email:     email,
+			Role:      role,
+			TokenHash: hashed,
+			InvitedBy: uuid.NullUUID{UUID: inviter.ID, Valid: true},
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.recordAudit(ctx, q, auditEntry{
+			ActorID:    inviter.ID,
+			Action:     auditStaffInvited,
+			TargetType: auditTargetInvitation,
+			TargetID:   record.ID,
+			Metadata:   map[string]any{"email": email, "role": role},
+		})
+	})
+	if err != nil {
+		return StaffInvitation{}, err
+	}
+
+	s.logger.Info().
+		Str("event", "staff_invited").
+		Str("invitation_id", record.ID.String()).
+		Str("invited_by", inviter.ID.String()).
+		Str("role", role).
+		Msg("staff invitation sent")
+
+	s.sendStaffInvitationEmail(ctx, email, role, token)
+	return s.toStaffInvitation(record), nil
+}
+
+// ListStaffInvitations returns every invitation, newest first.
+func (s *Service) ListStaffInvitations(ctx context.Context) ([]StaffInvitation, error) {
+	records, err := s.store.Queries().ListStaffInvitations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := make([]StaffInvitation, 0, len(records))
+	for _, record := range records {
+		invitations = append(invitations, s.toStaffInvitation(record))
+	}
+	return invitations, nil
+}
+
+// RevokeStaffInvitation cancels an invitation that has not been accepted yet.
+func (s *Service) RevokeStaffInvitation(ctx context.Context, actor UserContext, id uuid.UUID) error {
+	if actor.IsAPIKey() {
+		return ErrHumanUserRequired
+	}
+
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		revoked, err := q.RevokeStaffInvitation(ctx, id)
+		if err != nil {
+			return err
+		}
+		if revoked == 0 {
+			return ErrInvitationNotFound
+		}
+
+		return s.recordAudit(ctx, q, auditEntry{
+			ActorID:    actor.ID,
+			Action:     auditStaffInvitationRevoked,
+			TargetType: auditTargetInvitation,
+			TargetID:   id,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().Str("event", "staff_invitation_revoked").Str("invitation_id", id.String()).Msg("staff invitation revoked")
+	return nil
+}
+
+// checkInviter reports ErrInvalidInvitation once the inviter has been deleted, is no longer
+// active or has lost permissions the invited role grants.
+func checkInviter(ctx context.Context, q *queries.Queries, invitation queries.StaffInvitation) error {
+	if !invitation.InvitedBy.Valid {
+		return ErrInvalidInvitation
+	}
+	inviter, err := q.GetUserByID(ctx, invitation.InvitedBy.UUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidInvitation
+		}
+		return err
+	}
+
+	granted := UserContext{Permissions: PermissionsForRole(inviter.Role)}
+	if !strings.EqualFold(inviter.Status, statusActive) || !granted.Can(PermissionsForRole(invitation.Role)...) {
+		return ErrInvalidInvitation
+	}
+	return nil
+}
+
+// AcceptInvitation creates the invited staff account with the chosen password and signs it in.
+// Following the emailed link proves ownership of the address, so the account starts active.
+// The invitation only holds while its inviter is still an active user able to grant its role.
+func (s *Service) AcceptInvitation(ctx context.Context, input AcceptInvitationInput, meta SessionMetadata) (AuthResult, error) {
+	result := AuthResult{}
+	token := strings.TrimSpace(input.Token)
+	if token == "" {
+		return result, ErrInvalidInvitation
+	}
+	password, err := normalizePassword(input.Password)
+	if err != nil {
+		return result, err
+	}
+
+	hashed := hashOpaqueToken(token)
+
+	err = s.store.WithTx(ctx, func(q *queries.Queries) error {
+		invitation, err := q.GetStaffInvitationByHash(ctx, hashed)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidInvitation
+			}
+			return err
+		}
+
+		if invitation.AcceptedAt.Valid || invitation.RevokedAt.Valid {
+			return ErrInvalidInvitation
+		}
+		if invitation.ExpiresAt.Before(s.now()) {
+			return ErrExpiredInvitation
+		}
+		if err := checkInviter(ctx, q, invitation); err != nil {
+			return err
+		}
+
+		if _, err := q.GetUserByEmail(ctx, invitation.Email); err == nil {
+			return ErrEmailInUse
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		hash, err := hashPassword(password, s.config.Password)
+		if err != nil {
+			return err
+		}
+
+		user, err := q.CreateUser(ctx, queries.CreateUserParams{
+			Email:        invitation.Email,
+			PasswordHash: hash,
+			Role:         sql.NullString{String: invitation.Role, Valid: true},
+			Status:       sql.NullString{String: statusActive, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		accepted, err := q.AcceptStaffInvitation(ctx, queries.AcceptStaffInvitationParams{
+			AcceptedUserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+			ID:             invitation.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if accepted == 0 {
+			return ErrInvalidInvitation
+		}
+
+		metadata := map[string]any{"role": invitation.Role}
+		if invitation.InvitedBy.Valid {
+			metadata["invited_by"] = invitation.InvitedBy.UUID.String()
+		}
+		if err := s.recordAudit(ctx, q, auditEntry{
+			ActorID:    user.ID,
+			Action:     auditStaffInvitationAccepted,
+			TargetType: auditTargetInvitation,
+			TargetID:   invitation.ID,
+			Metadata:   metadata,
+		}); err != nil {
+			return err
+		}
+
+		result, err = s.completeLogin(ctx, q, user, meta, []string{amrPassword})
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	s.logger.Info().
+		Str("event", "staff_invitation_accepted").
+		Str("user_id", result.User.ID.String()).
+		Str("role", result.User.Role).
+		Msg("staff invitation accepted")
+	return result, nil
+}
+
+// BootstrapAdmin creates the very first staff admin directly, for installations where nobody
+// can send an invitation yet. It refuses once any staff admin exists.
+func (s *Service) BootstrapAdmin(ctx context.Context, email, password string) (queries.User, error) {
+	normalized, err := normalizeEmail(email)
+	if err != nil {
+		return queries.User{}, err
+	}
+	password, err = normalizePassword(password)
+	if err != nil {
+		return queries.User{}, err
+	}
+
+	var user queries.User
+	err = s.store.WithTx(ctx, func(q *queries.Queries) error {
+		admins, err := q.CountUsersByRole(ctx, RoleStaffAdmin)
+		if err != nil {
+			return err
+		}
+		if admins > 0 {
+			return ErrAdminExists
+		}
+
+		if _, err := q.GetUserByEmail(ctx, normalized); err == nil {
+			return ErrEmailInUse
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		hash, err := hashPassword(password, s.config.Password)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.CreateUser(ctx, queries.CreateUserParams{
+			Email:        normalized,
+			PasswordHash: hash,
+			Role:         sql.NullString{String: RoleStaffAdmin, Valid: true},
+			Status:       sql.NullString{String: statusActive, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.recordAudit(ctx, q, auditEntry{
+			Action:     auditStaffBootstrapped,
+			TargetType: auditTargetUser,
+			TargetID:   user.ID,
+			Metadata:   map[string]any{"email": normalized, "role": RoleStaffAdmin},
+		})
+	})
+	if err != nil {
+		return queries.User{}, err
+	}
+
+	s.logger.Info().Str("event", "staff_admin_bootstrapped").Str("user_id", user.ID.String()).Msg("first staff admin created")
+	return user, nil
+}
+
+func (s *Service) toStaffInvitation(record queries.StaffInvitation) StaffInvitation {
+	invitation := StaffInvitation{
+		ID:             record.ID,
+		Email:          record.Email,
+		Role:           record.Role,
+		Status:         invitationPending,
+		InvitedBy:      record.InvitedBy,
+		AcceptedUserID: record.AcceptedUserID,
+		ExpiresAt:      record.ExpiresAt,
+		AcceptedAt:     record.AcceptedAt,
+		RevokedAt:      record.RevokedAt,
+		CreatedAt:      record.CreatedAt,
+	}
+
+	switch {
+	case record.AcceptedAt.Valid:
+		invitation.Status = invitationAccepted
+	case record.RevokedAt.Valid:
+		invitation.Status = invitationRevoked
+	case record.ExpiresAt.Before(s.now()):
+		invitation.Status = invitationExpired
+	}
+	return invitation
+}
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/clientip"
+)
+
+type acceptInvitationRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type inviteStaffRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type invitationResponse struct {
+	ID             string  `json:"id"`
+	Email          string  `json:"email"`
+	Role           string  `json:"role"`
+	Status         string  `json:"status"`
+	InvitedBy      *string `json:"invited_by"`
+	AcceptedUserID *string `json:"accepted_user_id"`
+	ExpiresAt      string  `json:"expires_at"`
+	AcceptedAt     *string `json:"accepted_at"`
+	RevokedAt      *string `json:"revoked_at"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+func (h *Handler) handleAcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var req acceptInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	result, err := h.service.AcceptInvitation(r.Context(), AcceptInvitationInput{
+		Token:    req.Token,
+		Password: req.Password,
+	}, SessionMetadata{
+		UserAgent: r.UserAgent(),
+		IP:        clientip.FromRequest(r),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidInvitation):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrExpiredInvitation):
+			writeError(w, http.StatusGone, err.Error())
+		case errors.Is(err, ErrWeakPassword):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrEmailInUse):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to accept invitation")
+		}
+		return
+	}
+
+	h.writeAuthResponse(w, http.StatusCreated, result)
+}
+
+func (h *Handler) handleListInvitations(w http.ResponseWriter, r *http.Request) {
+	invitations, err := h.service.ListStaffInvitations(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list invitations")
+		return
+	}
+
+	resp := make([]invitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		resp = append(resp, toInvitationResponse(invitation))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"invitations": resp})
+}
+
+func (h *Handler) handleInviteStaff(w http.ResponseWriter, r *http.Request) {
+	user, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req inviteStaffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	invitation, err := h.service.InviteStaff(r.Context(), user, InviteStaffInput{Email: req.Email, Role: req.Role})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrHumanUserRequired), errors.Is(err, ErrForbidden):
+			writeError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, ErrInvalidEmail), errors.Is(err, ErrInvalidInvitationRole):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrEmailInUse):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to send invitation")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toInvitationResponse(invitation))
+}
+
+func (h *Handler) handleRevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	user, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	invitationID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, ErrInvitationNotFound.Error())
+		return
+	}
+
+	if err := h.service.RevokeStaffInvitation(r.Context(), user, invitationID); err != nil {
+		switch {
+		case errors.Is(err, ErrHumanUserRequired):
+			writeError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, ErrInvitationNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to revoke invitation")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toInvitationResponse(invitation StaffInvitation) invitationResponse {
+	return invitationResponse{
+		ID:             invitation.ID.String(),
+		Email:          invitation.Email,
+		Role:           invitation.Role,
+		Status:         invitation.Status,
+		InvitedBy:      formatNullUUID(invitation.InvitedBy),
+		AcceptedUserID: formatNullUUID(invitation.AcceptedUserID),
+		ExpiresAt:      invitation.ExpiresAt.UTC().Format(time.RFC3339),
+		AcceptedAt:     formatNullTime(invitation.AcceptedAt),
+		RevokedAt:      formatNullTime(invitation.RevokedAt),
+		CreatedAt:      invitation.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+// inviteTestStaff has inviter invite a fresh address and returns the invitation and its
+// emailed token. Any account created by accepting it is removed when the test ends.
+func inviteTestStaff(t *testing.T, s *Service, mail *recordingMailer, inviter queries.User, role string) (StaffInvitation, string) {
+	t.Helper()
+	ctx := context.Background()
+
+	email := fmt.Sprintf("invitee-%s@example.test", uuid.NewString())
+	invitation, err := s.InviteStaff(ctx, staffContext(inviter), InviteStaffInput{Email: email, Role: role})
+	if err != nil {
+		t.Fatalf("invite: %v", err)
+	}
+	t.Cleanup(func() {
+		if user, err := s.store.Queries().GetUserByEmail(ctx, email); err == nil {
+			_ = s.store.Queries().DeleteUser(ctx, user.ID)
+		}
+	})
+	return invitation, mail.token(t, email)
+}
+
+func newInvitationTestService(t *testing.T) (*Service, *recordingMailer) {
+	t.Helper()
+
+	s, _ := newTestService(t, Config{})
+	mail := &recordingMailer{}
+	s.WithMailer(mail)
+	return s, mail
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	s, mail := newInvitationTestService(t)
+	ctx := context.Background()
+	admin := createTestUser(t, s, RoleStaffAdmin)
+
+	invitation, token := inviteTestStaff(t, s, mail, admin, RoleStaffEditor)
+	if invitation.Status != invitationPending || invitation.InvitedBy.UUID != admin.ID {
+		t.Errorf("invitation = %+v", invitation)
+	}
+
+	result, err := s.AcceptInvitation(ctx, AcceptInvitationInput{Token: token, Password: testPassword}, SessionMetadata{})
+	if err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if result.User.Role != RoleStaffEditor || result.User.Status != statusActive || result.AccessToken == "" {
+		t.Errorf("accepted user = %+v, access token %q", result.User, result.AccessToken)
+	}
+
+	if _, err := s.AcceptInvitation(ctx, AcceptInvitationInput{Token: token, Password: testPassword}, SessionMetadata{}); !errors.Is(err, ErrInvalidInvitation) {
+		t.Errorf("second acceptance: got %v, want %v", err, ErrInvalidInvitation)
+	}
+}
+
+func TestAcceptInvitationChecksInviter(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(t *testing.T, s *Service, inviter queries.User)
+	}{
+		{
+			name: "inviter suspended",
+			change: func(t *testing.T, s *Service, inviter queries.User) {
+				if _, err := s.store.Queries().UpdateUserStatus(context.Background(), queries.UpdateUserStatusParams{ID: inviter.ID, Status: statusSuspended}); err != nil {
+					t.Fatalf("suspend: %v", err)
+				}
+			},
+		},
+		{
+			name: "inviter demoted",
+			change: func(t *testing.T, s *Service, inviter queries.User) {
+				if _, err := s.store.Queries().UpdateUserRole(context.Background(), queries.UpdateUserRoleParams{ID: inviter.ID, Role: RoleStaffEditor}); err != nil {
+					t.Fatalf("demote: %v", err)
+				}
+			},
+		},
+		{
+			name: "inviter deleted",
+			change: func(t *testing.T, s *Service, inviter queries.User) {
+				if err := s.store.Queries().DeleteUser(context.Background(), inviter.ID); err != nil {
+					t.Fatalf("delete: %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mail := newInvitationTestService(t)
+			inviter := createTestUser(t, s, RoleStaffAdmin)
+			_, token := inviteTestStaff(t, s, mail, inviter, RoleStaffAdmin)
+
+			tt.change(t, s, inviter)
+
+			_, err := s.AcceptInvitation(context.Background(), AcceptInvitationInput{Token: token, Password: testPassword}, SessionMetadata{})
+			if !errors.Is(err, ErrInvalidInvitation) {
+				t.Fatalf("got %v, want %v", err, ErrInvalidInvitation)
+			}
+		})
+	}
+}
+
+func TestInviteStaffLimits(t *testing.T) {
+	s, _ := newInvitationTestService(t)
+	ctx := context.Background()
+	editor := createTestUser(t, s, RoleStaffEditor)
+	existing := createTestUser(t, s, RoleSeeker)
+
+	tests := []struct {
+		name    string
+		inviter UserContext
+		input   InviteStaffInput
+		wantErr error
+	}{
+		{name: "api key", inviter: UserContext{ID: uuid.New(), APIKeyID: uuid.New(), Permissions: PermissionsForRole(RoleStaffAdmin)}, input: InviteStaffInput{Email: "new@example.test", Role: RoleStaffEditor}, wantErr: ErrHumanUserRequired},
+		{name: "role above the inviter", inviter: staffContext(editor), input: InviteStaffInput{Email: "new@example.test", Role: RoleStaffAdmin}, wantErr: ErrForbidden},
+		{name: "not a staff role", inviter: staffContext(editor), input: InviteStaffInput{Email: "new@example.test", Role: RoleSeeker}, wantErr: ErrInvalidInvitationRole},
+		{name: "existing account", inviter: staffContext(editor), input: InviteStaffInput{Email: existing.Email, Role: RoleStaffEditor}, wantErr: ErrEmailInUse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.InviteStaff(ctx, tt.inviter, tt.input); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRevokeStaffInvitation(t *testing.T) {
+	s, mail := newInvitationTestService(t)
+	ctx := context.Background()
+	admin := createTestUser(t, s, RoleStaffAdmin)
+	invitation, token := inviteTestStaff(t, s, mail, admin, RoleStaffEditor)
+
+	apiKey := UserContext{ID: uuid.New(), APIKeyID: uuid.New(), Permissions: PermissionsForRole(RoleStaffAdmin)}
+	if err := s.RevokeStaffInvitation(ctx, apiKey, invitation.ID); !errors.Is(err, ErrHumanUserRequired) {
+		t.Fatalf("revoke with an api key: got %v, want %v", err, ErrHumanUserRequired)
+	}
+
+	if err := s.RevokeStaffInvitation(ctx, staffContext(admin), invitation.ID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if err := s.RevokeStaffInvitation(ctx, staffContext(admin), invitation.ID); !errors.Is(err, ErrInvitationNotFound) {
+		t.Errorf("second revoke: got %v, want %v", err, ErrInvitationNotFound)
+	}
+	if _, err := s.AcceptInvitation(ctx, AcceptInvitationInput{Token: token, Password: testPassword}, SessionMetadata{}); !errors.Is(err, ErrInvalidInvitation) {
+		t.Errorf("accept revoked invitation: got %v, want %v", err, ErrInvalidInvitation)
+	}
+}
+
+func TestBootstrapAdminRefusesOnceAnAdminExists(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+	createTestUser(t, s, RoleStaffAdmin)
+
+	email := fmt.Sprintf("bootstrap-%s@example.test", uuid.NewString())
+	if _, err := s.BootstrapAdmin(context.Background(), email, testPassword); !errors.Is(err, ErrAdminExists) {
+		t.Fatalf("got %v, want %v", err, ErrAdminExists)
+	}
+}
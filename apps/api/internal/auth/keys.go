@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey is a single entry of the keyring. Retired keys only carry a public half.
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// Keyring signs access tokens with the active key and verifies tokens issued by any known key.
+// Without asymmetric keys it falls back to HS256 with the shared secret.
+type Keyring struct {
+	active *signingKey
+	keys   map[string]*signingKey
+	// secret verifies (and, without an active key, signs) legacy HS256 tokens that carry no kid.
+	secret []byte
+}
+
+// NewHMACKeyring builds a keyring that signs and verifies HS256 tokens with the shared secret.
+func NewHMACKeyring(secret string) *Keyring {
+	return &Keyring{keys: map[string]*signingKey{}, secret: []byte(secret)}
+}
+
+// LoadKeyring reads PEM keys from dir, one per file named <kid>.pem. Private keys (PKCS#8 Ed25519 or
+// RSA, or PKCS#1 RSA) can sign; public keys (PKIX) are kept to verify tokens from retired keys.
+// activeKID selects the signing key and may be empty when the directory holds a single private key.
+// legacySecret, when non-empty, keeps HS256 tokens without a kid valid during migration.
+func LoadKeyring(dir, activeKID, legacySecret string) (*Keyring, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ring := &Keyring{keys: map[string]*signingKey{}}
+	if legacySecret != "" {
+		ring.secret = []byte(legacySecret)
+	}
+
+	var privateKIDs []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".pem" {
+			continue
+		}
+
+		kid := strings.TrimSuffix(entry.Name(), ".pem")
+		raw, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := parseSigningKey(kid, raw)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", kid, err)
+		}
+
+		ring.keys[kid] = key
+		if key.private != nil {
+			privateKIDs = append(privateKIDs, kid)
+		}
+	}
+
+	if activeKID == "" {
+		if len(privateKIDs) != 1 {
+			return nil, fmt.Errorf("expected exactly one private key in %s when no active key id is set, found %d", dir, len(privateKIDs))
+		}
+		activeKID = privateKIDs[0]
+	}
+
+	active, ok := ring.keys[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("active signing key %q not found in %s", activeKID, dir)
+	}
+	if active.private == nil {
+		return nil, fmt.Errorf("active signing key %q has no private key", activeKID)
+	}
+	ring.active = active
+
+	return ring, nil
+}
+
+func parseSigningKey(kid string, raw []byte) (*signingKey, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{kid: kid}
+	switch k := parsed.(type) {
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, k, k.Public()
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, k, k.Public()
+	case ed25519.PublicKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, k
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	return key, nil
+}
+
+func (k *Keyring) sign(claims jwt.Claims) (string, error) {
+	if k.active == nil {
+		if len(k.secret) == 0 {
+			return "", errMissingSecret
+		}
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(k.secret)
+	}
+
+	token := jwt.NewWithClaims(k.active.method, claims)
+	token.Header["kid"] = k.active.kid
+	return token.SignedString(k.active.private)
+}
+
+// validMethods lists the algorithms the keyring is willing to verify.
+func (k *Keyring) validMethods() []string {
+	methods := []string{}
+	seen := map[string]bool{}
+	for _, key := range k.keys {
+		if alg := key.method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	if len(k.secret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	return methods
+}
+
+func (k *Keyring) keyFunc(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok || len(k.secret) == 0 {
+			return nil, errInvalidAccessToken
+		}
+		return k.secret, nil
+	}
+
+	key, ok := k.keys[kid]
+	if !ok || t.Method.Alg() != key.method.Alg() {
+		return nil, errInvalidAccessToken
+	}
+	return key.public, nil
+}
+
+// JSONWebKey is the public half of a signing key in RFC 7517 form.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// JSONWebKeySet is the document served from /.well-known/jwks.json.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS returns the public verification keys as a JSON Web Key Set. HMAC secrets are never published.
+func (k *Keyring) JWKS() JSONWebKeySet {
+	kids := make([]string, 0, len(k.keys))
+	for kid := range k.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	keys := make([]JSONWebKey, 0, len(kids))
+	for _, kid := range kids {
+		key := k.keys[kid]
+		entry := JSONWebKey{Kid: kid, Use: "sig", Alg: key.method.Alg()}
+		switch pub := key.public.(type) {
+		case ed25519.PublicKey:
+			entry.Kty = "OKP"
+			entry.Crv = "Ed25519"
+			entry.X = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			entry.Kty = "RSA"
+			entry.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			entry.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		default:
+			continue
+		}
+		keys = append(keys, entry)
+	}
+
+	return JSONWebKeySet{Keys: keys}
+}
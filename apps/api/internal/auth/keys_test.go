@@ -0,0 +1,292 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// testRSAKey is generated once; RSA key generation is slow.
+var testRSAKey = sync.OnceValue(func() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+})
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ed25519 key: %v", err)
+	}
+	return key
+}
+
+func pkcs8PEM(t *testing.T, key any) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal private key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func publicPEM(t *testing.T, key any) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// writeKeyDir writes each PEM to <kid>.pem in a temporary directory.
+func writeKeyDir(t *testing.T, keys map[string][]byte) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for kid, data := range keys {
+		if err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600); err != nil {
+			t.Fatalf("write key %s: %v", kid, err)
+		}
+	}
+	return dir
+}
+
+func loadTestKeyring(t *testing.T, keys map[string][]byte, activeKID, legacySecret string) *Keyring {
+	t.Helper()
+
+	ring, err := LoadKeyring(writeKeyDir(t, keys), activeKID, legacySecret)
+	if err != nil {
+		t.Fatalf("load keyring: %v", err)
+	}
+	return ring
+}
+
+func signTestToken(t *testing.T, ring *Keyring) string {
+	t.Helper()
+
+	token, err := generateAccessToken(ring, uuid.New(), RoleSeeker, uuid.New(), uuid.New(), []string{amrPassword}, time.Minute, time.Now())
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return token
+}
+
+func TestKeyringSignAndVerify(t *testing.T) {
+	rsaKey := testRSAKey()
+
+	tests := []struct {
+		name    string
+		pem     []byte
+		wantAlg string
+	}{
+		{name: "ed25519", pem: pkcs8PEM(t, newEd25519Key(t)), wantAlg: "EdDSA"},
+		{name: "rsa pkcs8", pem: pkcs8PEM(t, rsaKey), wantAlg: "RS256"},
+		{
+			name:    "rsa pkcs1",
+			pem:     pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}),
+			wantAlg: "RS256",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring := loadTestKeyring(t, map[string][]byte{"2026-10": tt.pem}, "", "")
+			token := signTestToken(t, ring)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &tokenClaims{})
+			if err != nil {
+				t.Fatalf("parse header: %v", err)
+			}
+			if parsed.Header["kid"] != "2026-10" || parsed.Header["alg"] != tt.wantAlg {
+				t.Errorf("header = %v, want kid 2026-10 and alg %s", parsed.Header, tt.wantAlg)
+			}
+
+			if _, err := parseAccessToken(ring, token); err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+		})
+	}
+}
+
+func TestKeyringVerifiesRetiredKeys(t *testing.T) {
+	retired := newEd25519Key(t)
+	oldRing := loadTestKeyring(t, map[string][]byte{"old": pkcs8PEM(t, retired)}, "", "")
+	oldToken := signTestToken(t, oldRing)
+
+	// After rotation only the public half of the old key is kept.
+	ring := loadTestKeyring(t, map[string][]byte{
+		"new": pkcs8PEM(t, testRSAKey()),
+		"old": publicPEM(t, retired.Public()),
+	}, "", "")
+
+	if _, err := parseAccessToken(ring, oldToken); err != nil {
+		t.Fatalf("token of the retired key: %v", err)
+	}
+	if _, err := parseAccessToken(ring, signTestToken(t, ring)); err != nil {
+		t.Fatalf("token of the active key: %v", err)
+	}
+}
+
+func TestKeyringRejectsForeignTokens(t *testing.T) {
+	ed25519Key := newEd25519Key(t)
+	ring := loadTestKeyring(t, map[string][]byte{
+		"ed":  pkcs8PEM(t, ed25519Key),
+		"rsa": publicPEM(t, &testRSAKey().PublicKey),
+	}, "ed", "legacy-secret")
+
+	sign := func(method jwt.SigningMethod, kid string, key any) string {
+		t.Helper()
+		token := jwt.NewWithClaims(method, tokenClaims{RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		}})
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "unknown kid", token: sign(jwt.SigningMethodEdDSA, "other", ed25519Key)},
+		{name: "key of another keyring", token: sign(jwt.SigningMethodEdDSA, "ed", newEd25519Key(t))},
+		{name: "alg of another key", token: sign(jwt.SigningMethodEdDSA, "rsa", ed25519Key)},
+		// HMAC signed with the public key, the classic algorithm confusion attack.
+		{name: "hmac with a key id", token: sign(jwt.SigningMethodHS256, "rsa", publicPEM(t, &testRSAKey().PublicKey))},
+		{name: "hmac with a kid and the secret", token: sign(jwt.SigningMethodHS256, "ed", []byte("legacy-secret"))},
+		{name: "asymmetric without a kid", token: sign(jwt.SigningMethodEdDSA, "", ed25519Key)},
+		{name: "unsigned", token: sign(jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseAccessToken(ring, tt.token); err == nil {
+				t.Fatal("foreign token accepted")
+			}
+		})
+	}
+}
+
+func TestKeyringLegacyHMACTokens(t *testing.T) {
+	legacy := signTestToken(t, NewHMACKeyring("legacy-secret"))
+	keys := map[string][]byte{"2026-10": pkcs8PEM(t, newEd25519Key(t))}
+
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr bool
+	}{
+		{name: "secret kept for migration", secret: "legacy-secret"},
+		{name: "no secret", secret: "", wantErr: true},
+		{name: "other secret", secret: "rotated-secret", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring := loadTestKeyring(t, keys, "", tt.secret)
+			_, err := parseAccessToken(ring, legacy)
+			if tt.wantErr && err == nil {
+				t.Fatal("legacy token accepted")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("legacy token rejected: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadKeyringErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      map[string][]byte
+		activeKID string
+	}{
+		{name: "no keys", keys: map[string][]byte{}},
+		{name: "two private keys without an active kid", keys: map[string][]byte{
+			"a": pkcs8PEM(t, newEd25519Key(t)),
+			"b": pkcs8PEM(t, newEd25519Key(t)),
+		}},
+		{name: "unknown active kid", keys: map[string][]byte{"a": pkcs8PEM(t, newEd25519Key(t))}, activeKID: "b"},
+		{name: "public-only active key", keys: map[string][]byte{
+			"a": pkcs8PEM(t, newEd25519Key(t)),
+			"b": publicPEM(t, newEd25519Key(t).Public()),
+		}, activeKID: "b"},
+		{name: "not PEM", keys: map[string][]byte{"a": []byte("not a key")}},
+		{name: "unsupported block", keys: map[string][]byte{"a": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{1}})}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadKeyring(writeKeyDir(t, tt.keys), tt.activeKID, ""); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestKeyringJWKS(t *testing.T) {
+	ed25519Key := newEd25519Key(t)
+	rsaKey := testRSAKey()
+	ring := loadTestKeyring(t, map[string][]byte{
+		"ed":  pkcs8PEM(t, ed25519Key),
+		"rsa": publicPEM(t, &rsaKey.PublicKey),
+	}, "ed", "legacy-secret")
+
+	set := ring.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2 and no HMAC secret: %+v", len(set.Keys), set.Keys)
+	}
+
+	ed, rsaJWK := set.Keys[0], set.Keys[1]
+	wantEd := JSONWebKey{
+		Kty: "OKP",
+		Kid: "ed",
+		Use: "sig",
+		Alg: "EdDSA",
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(ed25519Key.Public().(ed25519.PublicKey)),
+	}
+	if ed != wantEd {
+		t.Errorf("ed25519 JWK = %+v, want %+v", ed, wantEd)
+	}
+
+	if rsaJWK.Kty != "RSA" || rsaJWK.Kid != "rsa" || rsaJWK.Alg != "RS256" || rsaJWK.Use != "sig" || rsaJWK.X != "" {
+		t.Errorf("rsa JWK = %+v", rsaJWK)
+	}
+	// 65537 is AQAB in every JWKS.
+	if rsaJWK.E != "AQAB" {
+		t.Errorf("e = %q, want AQAB", rsaJWK.E)
+	}
+	n, err := base64.RawURLEncoding.DecodeString(rsaJWK.N)
+	if err != nil {
+		t.Fatalf("decode n: %v", err)
+	}
+	if new(big.Int).SetBytes(n).Cmp(rsaKey.N) != 0 || n[0] == 0 {
+		t.Error("n does not encode the modulus as unpadded big-endian bytes")
+	}
+}
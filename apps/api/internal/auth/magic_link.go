@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+var (
+	// ErrInvalidMagicLink signals the login link could not be validated.
+	ErrInvalidMagicLink = errors.New("invalid login link")
+	// ErrExpiredMagicLink indicates the login link is no longer valid.
+	ErrExpiredMagicLink = errors.New("login link expired")
+)
+
+// RequestMagicLink emails a single-use login link when the account exists. Like ForgotPassword
+// it reports success regardless, and requests beyond the hourly limit for an address are
+// dropped silently so the limit cannot be used to probe for accounts.
+func (s *Service) RequestMagicLink(ctx context.Context, email, userAgent string) error {
+	normalized := strings.TrimSpace(strings.ToLower(email))
+	if normalized == "" {
+		return ErrInvalidEmail
+	}
+
+	now := s.now()
+	var recipient, loginToken string
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		user, err := q.GetUserByEmail(ctx, normalized)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+
+		switch strings.ToLower(user.Status) {
+		case statusActive, statusPendingVerification:
+		default:
+			return nil
+		}
+
+		recent, err := q.CountMagicLinkTokensSince(ctx, queries.CountMagicLinkTokensSinceParams{
+			UserID: user.ID,
+			Since:  now.Add(-time.Hour),
+		})
+		if err != nil {
+			return err
+		}
+		if recent >= int64(s.config.MagicLinkHourlyLimit) {
+			s.logger.Warn().
+				Str("event", "magic_link_rate_limited").
+				Str("user_id", user.ID.String()).
+				Msg("magic link request dropped")
+			return nil
+		}
+
+		token, hashed, expiresAt, err := generateOpaqueToken(s.config.MagicLinkTTL, now)
+		if err != nil {
+			return err
+		}
+
+		if _, err := q.CreateMagicLinkToken(ctx, queries.CreateMagicLinkTokenParams{
+			UserID:        user.ID,
+			TokenHash:     hashed,
+			UserAgentHash: hashUserAgent(userAgent),
+			ExpiresAt:     expiresAt,
+		}); err != nil {
+			return err
+		}
+
+		recipient = user.Email
+		loginToken = token
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if loginToken != "" {
+		// Deliver in the background so response timing does not reveal whether the account exists.
+		go s.sendMagicLinkEmail(context.WithoutCancel(ctx), recipient, loginToken)
+	}
+	return nil
+}
+
+// ConsumeMagicLink exchanges a login link for a session. Links requested from a browser only
+// work in a browser presenting the same User-Agent.
+func (s *Service) ConsumeMagicLink(ctx context.Context, loginToken string, meta SessionMetadata) (AuthResult, error) {
+	result := AuthResult{}
+	token := strings.TrimSpace(loginToken)
+	if token == "" {
+		return result, ErrInvalidMagicLink
+	}
+
+	hashed := hashOpaqueToken(token)
+
+	var userID uuid.UUID
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		record, err := q.GetMagicLinkTokenByHash(ctx, hashed)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidMagicLink
+			}
+			return err
+		}
+
+		if record.ConsumedAt.Valid {
+			return ErrInvalidMagicLink
+		}
+		if record.ExpiresAt.Before(s.now()) {
+			return ErrExpiredMagicLink
+		}
+		if record.UserAgentHash.Valid && record.UserAgentHash != hashUserAgent(meta.UserAgent) {
+			return ErrInvalidMagicLink
+		}
+
+		user, err := q.GetUserByID(ctx, record.UserID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidMagicLink
+			}
+			return err
+		}
+
+		consumed, err := q.ConsumeMagicLinkToken(ctx, record.ID)
+		if err != nil {
+			return err
+		}
+		if consumed == 0 {
+			return ErrInvalidMagicLink
+		}
+
+		if err := q.DeletePendingMagicLinkTokens(ctx, user.ID); err != nil {
+			return err
+		}
+
+		switch strings.ToLower(user.Status) {
+		case statusActive:
+		case statusPendingVerification:
+			// Following the emailed link proves ownership of the address.
+			user, err = q.UpdateUserStatus(ctx, queries.UpdateUserStatusParams{
+				ID:     user.ID,
+				Status: statusActive,
+			})
+			if err != nil {
+				return err
+			}
+		default:
+			return ErrInactiveAccount
+		}
+
+		userID = user.ID
+		result, err = s.completeLogin(ctx, q, user, meta, []string{amrEmail})
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	s.invalidatePrincipal(ctx, userID)
+	return result, nil
+}
+
+func hashUserAgent(userAgent string) sql.NullString {
+	userAgent = strings.TrimSpace(userAgent)
+	if userAgent == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: hashOpaqueToken(userAgent), Valid: true}
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/synergyvets/platform/internal/clientip"
+)
+
+type magicLinkRequest struct {
+	Email string `json:"email"`
+}
+
+type consumeMagicLinkRequest struct {
+	Token string `json:"token"`
+}
+
+func (h *Handler) handleRequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req magicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if err := h.service.RequestMagicLink(r.Context(), req.Email, r.UserAgent()); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidEmail):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to send login link")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Handler) handleConsumeMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req consumeMagicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	result, err := h.service.ConsumeMagicLink(r.Context(), req.Token, SessionMetadata{
+		UserAgent: r.UserAgent(),
+		IP:        clientip.FromRequest(r),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidMagicLink):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrExpiredMagicLink):
+			writeError(w, http.StatusGone, err.Error())
+		case errors.Is(err, ErrInactiveAccount):
+			writeError(w, http.StatusForbidden, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to log in with link")
+		}
+		return
+	}
+
+	h.writeLoginResponse(w, result)
+}
@@ -0,0 +1,544 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+const (
+	recoveryCodeCount = 10
+	recoveryCodeLen   = 10
+	// maxMFAAttempts bounds how many codes may be tried against a single login challenge.
+	maxMFAAttempts = 5
+)
+
+var (
+	// ErrMFAAlreadyEnabled indicates the user has already confirmed a TOTP factor.
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	// ErrMFANotEnabled indicates the user has no confirmed TOTP factor.
+	ErrMFANotEnabled = errors.New("two-factor authentication not enabled")
+	// ErrMFAEnrollmentNotStarted indicates confirmation was attempted before enrollment began.
+	ErrMFAEnrollmentNotStarted = errors.New("two-factor enrollment not started")
+	// ErrInvalidMFACode signals the TOTP or recovery code was rejected.
+	ErrInvalidMFACode = errors.New("invalid authentication code")
+	// ErrInvalidMFAChallenge signals the MFA login challenge could not be validated.
+	ErrInvalidMFAChallenge = errors.New("invalid mfa challenge")
+	// ErrExpiredMFAChallenge indicates the MFA login challenge is no longer valid.
+	ErrExpiredMFAChallenge = errors.New("mfa challenge expired")
+	// ErrMFARequired indicates policy requires a second factor the session has not provided.
+	ErrMFARequired = errors.New("two-factor authentication required for this account")
+)
+
+// MFAChallenge is returned by Login in place of tokens when the user has a second factor.
+type MFAChallenge struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// MFALoginInput completes a login that was answered with an MFAChallenge.
+// Code accepts either a current TOTP code or an unused recovery code.
+type MFALoginInput struct {
+	ChallengeToken string
+	Code           string
+	UserAgent      string
+	IP             string
+}
+
+// MFAEnrollment carries the secret a user adds to their authenticator app.
+type MFAEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// MFAStatus summarises a user's second-factor configuration.
+type MFAStatus struct {
+	Enabled                bool
+	Pending                bool
+	Required               bool
+	RecoveryCodesRemaining int
+}
+
+// mfaRequiredForRole reports whether policy forces a second factor for the role.
+func (s *Service) mfaRequiredForRole(role string) bool {
+	return s.config.RequireStaffMFA && IsStaffRole(role)
+}
+
+// MFAStatus reports whether the user has enabled, or is enrolling, a TOTP factor.
+func (s *Service) MFAStatus(ctx context.Context, userID uuid.UUID) (MFAStatus, error) {
+	q := s.store.Queries()
+
+	user, err := q.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return MFAStatus{}, ErrUserNotFound
+		}
+		return MFAStatus{}, err
+	}
+
+	status := MFAStatus{Required: s.mfaRequiredForRole(user.Role)}
+
+	mfa, err := q.GetUserMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return status, nil
+		}
+		return MFAStatus{}, err
+	}
+
+	status.Enabled = mfa.ConfirmedAt.Valid
+	status.Pending = !mfa.ConfirmedAt.Valid
+	if status.Enabled {
+		remaining, err := q.CountUnusedMFARecoveryCodes(ctx, userID)
+		if err != nil {
+			return MFAStatus{}, err
+		}
+		status.RecoveryCodesRemaining = int(remaining)
+	}
+	return status, nil
+}
+
+// BeginMFAEnrollment generates a new TOTP secret, replacing any unconfirmed enrollment.
+func (s *Service) BeginMFAEnrollment(ctx context.Context, userID uuid.UUID) (MFAEnrollment, error) {
+	var enrollment MFAEnrollment
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		user, err := q.GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		existing, err := q.GetUserMFA(ctx, userID)
+		if err == nil && existing.ConfirmedAt.Valid {
+			return ErrMFAAlreadyEnabled
+		} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		secret, err := generateTOTPSecret()
+		if err != nil {
+			return err
+		}
+
+		if _, err := q.UpsertPendingUserMFA(ctx, queries.UpsertPendingUserMFAParams{
+			UserID:     userID,
+			TotpSecret: secret,
+		}); err != nil {
+			return err
+		}
+
+		enrollment = MFAEnrollment{
+			Secret: secret,
+			URI:    totpURI(s.config.MFAIssuer, user.Email, secret),
+		}
+		return nil
+	})
+	return enrollment, err
+}
+
+// ConfirmMFAEnrollment activates the pending factor once the user proves possession with a
+// valid code, returning a fresh set of single-use recovery codes. Every session except the
+// current one is signed out, since none of them proved the new factor.
+func (s *Service) ConfirmMFAEnrollment(ctx context.Context, userID, currentSessionID uuid.UUID, code string) ([]string, error) {
+	var codes []string
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		mfa, err := q.GetUserMFA(ctx, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrMFAEnrollmentNotStarted
+			}
+			return err
+		}
+		if mfa.ConfirmedAt.Valid {
+			return ErrMFAAlreadyEnabled
+		}
+
+		now := s.now()
+		step, ok := validateTOTP(mfa.TotpSecret, normalizeMFACode(code), now, mfa.LastUsedStep)
+		if !ok {
+			return ErrInvalidMFACode
+		}
+
+		if err := q.ConfirmUserMFA(ctx, queries.ConfirmUserMFAParams{
+			UserID:       userID,
+			ConfirmedAt:  sql.NullTime{Time: now, Valid: true},
+			LastUsedStep: step,
+		}); err != nil {
+			return err
+		}
+
+		codes, err = s.replaceRecoveryCodes(ctx, q, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.revokeOtherAccessTokens(ctx, q, userID, currentSessionID); err != nil {
+			return err
+		}
+		return q.DeleteOtherUserSessions(ctx, queries.DeleteOtherUserSessionsParams{
+			UserID:       userID,
+			KeepFamilyID: currentSessionID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.revocations.markStale()
+
+	s.logger.Info().Str("event", "mfa_enabled").Str("user_id", userID.String()).Msg("two-factor authentication enabled")
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes invalidates the existing recovery codes and issues new ones.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	var codes []string
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		if err := s.requireSecondFactor(ctx, q, userID, code); err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = s.replaceRecoveryCodes(ctx, q, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableMFA removes the user's second factor after verifying a current code. Users whose role
+// requires MFA by policy cannot disable it themselves.
+func (s *Service) DisableMFA(ctx context.Context, userID uuid.UUID, code string) error {
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		user, err := q.GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		if s.mfaRequiredForRole(user.Role) {
+			return ErrMFARequired
+		}
+
+		if err := s.requireSecondFactor(ctx, q, userID, code); err != nil {
+			return err
+		}
+
+		return deleteMFA(ctx, q, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().Str("event", "mfa_disabled").Str("user_id", userID.String()).Msg("two-factor authentication disabled")
+	return nil
+}
+
+// ResetMFA removes a user's second factor on an administrator's behalf, e.g. after a lost
+// device, and signs the user out everywhere.
+func (s *Service) ResetMFA(ctx context.Context, userID uuid.UUID) error {
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		if _, err := q.GetUserByID(ctx, userID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		if err := deleteMFA(ctx, q, userID); err != nil {
+			return err
+		}
+		return s.revokeAllSessions(ctx, q, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.revocations.markStale()
+
+	s.logger.Warn().Str("event", "mfa_reset").Str("user_id", userID.String()).Msg("two-factor authentication reset by administrator")
+	return nil
+}
+
+// CompleteMFALogin exchanges an MFA challenge and a valid code for tokens. Wrong codes count
+// towards the same failed-login throttle as wrong passwords.
+func (s *Service) CompleteMFALogin(ctx context.Context, input MFALoginInput) (AuthResult, error) {
+	result := AuthResult{}
+
+	token := strings.TrimSpace(input.ChallengeToken)
+	if token == "" {
+		return result, ErrInvalidMFAChallenge
+	}
+	code := normalizeMFACode(input.Code)
+	if code == "" {
+		return result, ErrInvalidMFACode
+	}
+
+	now := s.now()
+	hashed := hashOpaqueToken(token)
+
+	var email string
+	failed := false
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		challenge, err := q.GetMFAChallengeByHash(ctx, hashed)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidMFAChallenge
+			}
+			return err
+		}
+
+		if challenge.ConsumedAt.Valid || challenge.Attempts >= maxMFAAttempts {
+			return ErrInvalidMFAChallenge
+		}
+		if challenge.ExpiresAt.Before(now) {
+			return ErrExpiredMFAChallenge
+		}
+
+		user, err := q.GetUserByID(ctx, challenge.UserID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidMFAChallenge
+			}
+			return err
+		}
+		email = user.Email
+
+		switch strings.ToLower(user.Status) {
+		case statusActive, statusPendingVerification:
+		default:
+			return ErrInactiveAccount
+		}
+
+		if err := s.checkLoginThrottle(ctx, email, input.IP, now); err != nil {
+			return err
+		}
+
+		mfa, err := q.GetUserMFA(ctx, user.ID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidMFAChallenge
+			}
+			return err
+		}
+		if !mfa.ConfirmedAt.Valid {
+			return ErrInvalidMFAChallenge
+		}
+
+		ok, err := s.verifySecondFactor(ctx, q, mfa, code, now)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// Keep the attempt count: the transaction must commit for it to stick.
+			if _, err := q.IncrementMFAChallengeAttempts(ctx, challenge.ID); err != nil {
+				return err
+			}
+			failed = true
+			return nil
+		}
+
+		consumed, err := q.ConsumeMFAChallenge(ctx, challenge.ID)
+		if err != nil {
+			return err
+		}
+		if consumed == 0 {
+			return ErrInvalidMFAChallenge
+		}
+
+		meta := SessionMetadata{UserAgent: input.UserAgent, IP: input.IP}
+		amr := append(slices.Clone(challenge.Amr), amrOTP)
+		ar, err := s.issueTokens(ctx, q, user, meta, amr)
+		if err != nil {
+			return err
+		}
+
+		if err := q.UpdateUserLastLogin(ctx, user.ID); err != nil {
+			s.logger.Warn().Err(err).Str("user_id", user.ID.String()).Msg("failed to update last login")
+		}
+
+		if err := q.DeleteLoginThrottle(ctx, queries.DeleteLoginThrottleParams{
+			Scope: throttleScopeAccount,
+			Key:   email,
+		}); err != nil {
+			return err
+		}
+
+		result = ar
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if failed {
+		if recErr := s.recordLoginFailure(ctx, email, input.IP, now); recErr != nil {
+			s.logger.Warn().Err(recErr).Msg("failed to record login failure")
+		}
+		return result, ErrInvalidMFACode
+	}
+
+	return result, nil
+}
+
+// createMFAChallenge issues the short-lived token that Login returns in place of session tokens,
+// remembering the first factor's amr for the session CompleteMFALogin starts.
+func (s *Service) createMFAChallenge(ctx context.Context, q *queries.Queries, userID uuid.UUID, amr []string) (*MFAChallenge, error) {
+	token, hashed, expiresAt, err := generateOpaqueToken(s.config.MFAChallengeTTL, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := q.CreateMFAChallenge(ctx, queries.CreateMFAChallengeParams{
+		UserID:    userID,
+		TokenHash: hashed,
+		ExpiresAt: expiresAt,
+		Amr:       amr,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &MFAChallenge{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// confirmedMFA reports whether the user has an active second factor.
+func confirmedMFA(ctx context.Context, q *queries.Queries, userID uuid.UUID) (bool, error) {
+	mfa, err := q.GetUserMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return mfa.ConfirmedAt.Valid, nil
+}
+
+// requireSecondFactor loads the user's confirmed factor and checks code against it.
+func (s *Service) requireSecondFactor(ctx context.Context, q *queries.Queries, userID uuid.UUID, code string) error {
+	mfa, err := q.GetUserMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrMFANotEnabled
+		}
+		return err
+	}
+	if !mfa.ConfirmedAt.Valid {
+		return ErrMFANotEnabled
+	}
+
+	ok, err := s.verifySecondFactor(ctx, q, mfa, normalizeMFACode(code), s.now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// verifySecondFactor accepts a TOTP code, recording its step to prevent replay, or burns an
+// unused recovery code.
+func (s *Service) verifySecondFactor(ctx context.Context, q *queries.Queries, mfa queries.UserMfa, code string, now time.Time) (bool, error) {
+	if isTOTPCode(code) {
+		step, ok := validateTOTP(mfa.TotpSecret, code, now, mfa.LastUsedStep)
+		if !ok {
+			return false, nil
+		}
+
+		advanced, err := q.AdvanceUserMFAStep(ctx, queries.AdvanceUserMFAStepParams{
+			UserID: mfa.UserID,
+			Step:   step,
+		})
+		if err != nil {
+			return false, err
+		}
+		return advanced > 0, nil
+	}
+
+	used, err := q.UseMFARecoveryCode(ctx, queries.UseMFARecoveryCodeParams{
+		UserID:   mfa.UserID,
+		CodeHash: hashOpaqueToken(code),
+	})
+	if err != nil {
+		return false, err
+	}
+	if used > 0 {
+		s.logger.Info().Str("event", "mfa_recovery_code_used").Str("user_id", mfa.UserID.String()).Msg("recovery code used")
+	}
+	return used > 0, nil
+}
+
+func (s *Service) replaceRecoveryCodes(ctx context.Context, q *queries.Queries, userID uuid.UUID) ([]string, error) {
+	if err := q.DeleteMFARecoveryCodes(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := q.CreateMFARecoveryCode(ctx, queries.CreateMFARecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: hashOpaqueToken(normalizeMFACode(code)),
+		}); err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+func deleteMFA(ctx context.Context, q *queries.Queries, userID uuid.UUID) error {
+	if err := q.DeleteUserMFA(ctx, userID); err != nil {
+		return err
+	}
+	if err := q.DeleteMFARecoveryCodes(ctx, userID); err != nil {
+		return err
+	}
+	return q.DeleteMFAChallengesByUserID(ctx, userID)
+}
+
+// generateRecoveryCode returns a code such as "k7qm2-xw4ph" drawn from an unambiguous alphabet.
+func generateRecoveryCode() (string, error) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+	// Reject bytes above the largest multiple of the alphabet size to avoid modulo bias.
+	const limit = 256 - 256%len(alphabet)
+
+	var b strings.Builder
+	buf := make([]byte, 1)
+	for n := 0; n < recoveryCodeLen; {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		if int(buf[0]) >= limit {
+			continue
+		}
+		if n == recoveryCodeLen/2 {
+			b.WriteByte('-')
+		}
+		b.WriteByte(alphabet[int(buf[0])%len(alphabet)])
+		n++
+	}
+	return b.String(), nil
+}
+
+// normalizeMFACode strips the separators users tend to type and lowercases recovery codes.
+func normalizeMFACode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code)))
+}
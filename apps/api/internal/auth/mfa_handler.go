@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type mfaCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type mfaStatusResponse struct {
+	Enabled                bool `json:"enabled"`
+	Pending                bool `json:"pending"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+type mfaEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+func (h *Handler) handleResetUserMFA(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, ErrUserNotFound.Error())
+		return
+	}
+
+	if err := h.service.ResetMFA(r.Context(), userID); err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to reset two-factor authentication")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleMFAStatus(w http.ResponseWriter, r *http.Request) {
+	user, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	status, err := h.service.MFAStatus(r.Context(), user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load two-factor status")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mfaStatusResponse{
+		Enabled:                status.Enabled,
+		Pending:                status.Pending,
+		Required:               status.Required,
+		RecoveryCodesRemaining: status.RecoveryCodesRemaining,
+	})
+}
+
+func (h *Handler) handleBeginMFAEnrollment(w http.ResponseWriter, r *http.Request) {
+	user, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	enrollment, err := h.service.BeginMFAEnrollment(r.Context(), user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrMFAAlreadyEnabled):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to start two-factor enrollment")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mfaEnrollmentResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.URI,
+	})
+}
+
+func (h *Handler) handleConfirmMFAEnrollment(w http.ResponseWriter, r *http.Request) {
+	user, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req mfaCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	codes, err := h.service.ConfirmMFAEnrollment(r.Context(), user.ID, user.SessionID, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidMFACode):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrMFAEnrollmentNotStarted):
+			writeError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, ErrMFAAlreadyEnabled):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to confirm two-factor enrollment")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"recovery_codes": codes})
+}
+
+func (h *Handler) handleRegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	user, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req mfaCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(r.Context(), user.ID, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidMFACode):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrMFANotEnabled):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to regenerate recovery codes")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"recovery_codes": codes})
+}
+
+func (h *Handler) handleDisableMFA(w http.ResponseWriter, r *http.Request) {
+	user, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req mfaCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if err := h.service.DisableMFA(r.Context(), user.ID, req.Code); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidMFACode):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrMFANotEnabled):
+			writeError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, ErrMFARequired):
+			writeError(w, http.StatusForbidden, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to disable two-factor authentication")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
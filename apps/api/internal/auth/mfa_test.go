@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+	seen := make(map[string]bool)
+	for range 200 {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			t.Fatalf("generateRecoveryCode: %v", err)
+		}
+		if len(code) != recoveryCodeLen+1 || code[recoveryCodeLen/2] != '-' {
+			t.Fatalf("code %q is not two groups of %d joined by a dash", code, recoveryCodeLen/2)
+		}
+		for _, r := range strings.Replace(code, "-", "", 1) {
+			if !strings.ContainsRune(alphabet, r) {
+				t.Fatalf("code %q contains %q outside the recovery alphabet", code, r)
+			}
+		}
+		if isTOTPCode(normalizeMFACode(code)) {
+			t.Fatalf("code %q could be mistaken for a TOTP code", code)
+		}
+		if seen[code] {
+			t.Fatalf("duplicate code %q", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestNormalizeMFACode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{code: "123456", want: "123456"},
+		{code: " 123 456 ", want: "123456"},
+		{code: "abcde-fghjk", want: "abcdefghjk"},
+		{code: "ABCDE-FGHJK", want: "abcdefghjk"},
+		{code: "abcde fghjk", want: "abcdefghjk"},
+		{code: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeMFACode(tt.code); got != tt.want {
+			t.Errorf("normalizeMFACode(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+// enrollMFA enrolls user in TOTP from the session of result and returns the secret and the
+// recovery codes.
+func enrollMFA(t *testing.T, s *Service, result AuthResult) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+
+	session, err := s.store.Queries().GetUserSessionByHash(ctx, hashRefreshToken(result.RefreshToken))
+	if err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	enrollment, err := s.BeginMFAEnrollment(ctx, result.User.ID)
+	if err != nil {
+		t.Fatalf("begin enrollment: %v", err)
+	}
+	codes, err := s.ConfirmMFAEnrollment(ctx, result.User.ID, session.FamilyID, currentTOTP(t, s, enrollment.Secret))
+	if err != nil {
+		t.Fatalf("confirm enrollment: %v", err)
+	}
+	return enrollment.Secret, codes
+}
+
+func currentTOTP(t *testing.T, s *Service, secret string) string {
+	t.Helper()
+
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("decode secret: %v", err)
+	}
+	return totpCode(key, totpStep(s.now()))
+}
+
+func accessTokenAMR(t *testing.T, s *Service, token string) []string {
+	t.Helper()
+
+	claims, err := parseAccessToken(s.keys, token)
+	if err != nil {
+		t.Fatalf("parse access token: %v", err)
+	}
+	return claims.AMR
+}
+
+func TestMFAEnrollmentRevokesOtherSessions(t *testing.T) {
+	s, clock := newTestService(t, Config{})
+	ctx := context.Background()
+	user := createTestUser(t, s, RoleSeeker)
+
+	before := login(t, s, user)
+	enrolling := login(t, s, user)
+	enrollMFA(t, s, enrolling)
+
+	clock.Advance(time.Minute)
+	if _, err := s.Refresh(ctx, before.RefreshToken, SessionMetadata{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("refresh of a pre-enrollment session: got %v, want %v", err, ErrInvalidRefreshToken)
+	}
+	if _, err := s.ValidateAccessToken(ctx, before.AccessToken); !errors.Is(err, ErrAccessTokenRevoked) {
+		t.Errorf("access token of a pre-enrollment session: got %v, want %v", err, ErrAccessTokenRevoked)
+	}
+
+	// The enrolling session stays signed in, but never proved the factor at login.
+	refreshed, err := s.Refresh(ctx, enrolling.RefreshToken, SessionMetadata{})
+	if err != nil {
+		t.Fatalf("refresh of the enrolling session: %v", err)
+	}
+	if amr := accessTokenAMR(t, s, refreshed.AccessToken); slices.Contains(amr, amrOTP) {
+		t.Errorf("refreshed password session claims amr %v", amr)
+	}
+}
+
+func TestSessionAMRSurvivesRotation(t *testing.T) {
+	s, clock := newTestService(t, Config{})
+	ctx := context.Background()
+	user := createTestUser(t, s, RoleSeeker)
+
+	secret, recoveryCodes := enrollMFA(t, s, login(t, s, user))
+
+	tests := []struct {
+		name string
+		code func() string
+	}{
+		{name: "totp", code: func() string { return currentTOTP(t, s, secret) }},
+		{name: "recovery code", code: func() string { return recoveryCodes[0] }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Move past the step used by enrollment or the previous subtest.
+			clock.Advance(time.Minute)
+
+			challenge, err := s.Login(ctx, LoginInput{Email: user.Email, Password: testPassword})
+			if err != nil {
+				t.Fatalf("login: %v", err)
+			}
+			if challenge.MFAChallenge == nil {
+				t.Fatal("login of an MFA user returned tokens without a challenge")
+			}
+
+			result, err := s.CompleteMFALogin(ctx, MFALoginInput{
+				ChallengeToken: challenge.MFAChallenge.Token,
+				Code:           tt.code(),
+			})
+			if err != nil {
+				t.Fatalf("complete MFA login: %v", err)
+			}
+			want := []string{amrPassword, amrOTP}
+			if amr := accessTokenAMR(t, s, result.AccessToken); !slices.Equal(amr, want) {
+				t.Errorf("login amr = %v, want %v", amr, want)
+			}
+
+			clock.Advance(time.Minute)
+			refreshed, err := s.Refresh(ctx, result.RefreshToken, SessionMetadata{})
+			if err != nil {
+				t.Fatalf("refresh: %v", err)
+			}
+			if amr := accessTokenAMR(t, s, refreshed.AccessToken); !slices.Equal(amr, want) {
+				t.Errorf("refreshed amr = %v, want %v", amr, want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/google/uuid"
@@ -18,6 +19,28 @@ type UserContext struct {
 	ID    uuid.UUID
 	Email string
 	Role  string
+	// SessionID is the refresh session family the access token belongs to, if known.
+	SessionID uuid.UUID
+	// Permissions are resolved from Role when the request is authenticated.
+	Permissions []Permission
+	// MFA reports whether the session was established with a second factor.
+	MFA bool
+	// APIKeyID is set when the request authenticated with an API key rather than a user
+	// session. ID then holds the key ID and Permissions the key's scopes.
+	APIKeyID uuid.UUID
+	// ImpersonatorID is set when an administrator is viewing the product as this user. Such
+	// requests are read-only and SessionID holds the impersonation session.
+	ImpersonatorID uuid.UUID
+}
+
+// IsAPIKey reports whether the principal is an API key rather than a user.
+func (u UserContext) IsAPIKey() bool {
+	return u.APIKeyID != uuid.Nil
+}
+
+// IsImpersonated reports whether an administrator is acting as the user.
+func (u UserContext) IsImpersonated() bool {
+	return u.ImpersonatorID != uuid.Nil
 }
 
 // UserFromContext extracts the authenticated user context if present.
@@ -31,44 +54,141 @@ func UserFromContext(ctx context.Context) (UserContext, bool) {
 	return user, ok
 }
 
-// RequireStaff validates the bearer token and enforces staff-level access.
-func (h *Handler) RequireStaff(next http.Handler) http.Handler {
+// RequireUser validates the bearer token and enforces an authenticated, active user. API keys
+// are refused because the routes behind it act on the caller's own account.
+func (h *Handler) RequireUser(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		header := strings.TrimSpace(r.Header.Get("Authorization"))
-		if header == "" {
-			writeError(w, http.StatusUnauthorized, "missing authorization header")
+		ctxUser, ok := h.authenticate(w, r)
+		if !ok {
 			return
 		}
-
-		if !strings.HasPrefix(strings.ToLower(header), "bearer ") {
-			writeError(w, http.StatusUnauthorized, "bearer token required")
+		if ctxUser.IsAPIKey() {
+			writeError(w, http.StatusForbidden, ErrHumanUserRequired.Error())
 			return
 		}
 
-		token := strings.TrimSpace(header[7:])
-		user, err := h.service.ValidateAccessToken(r.Context(), token)
-		if err != nil {
-			switch {
-			case errors.Is(err, ErrAccessTokenExpired):
-				writeError(w, http.StatusUnauthorized, err.Error())
-			case errors.Is(err, ErrInvalidAccessToken):
-				writeError(w, http.StatusUnauthorized, err.Error())
-			case errors.Is(err, ErrInactiveAccount):
-				writeError(w, http.StatusForbidden, err.Error())
-			default:
-				writeError(w, http.StatusUnauthorized, "unauthorized")
+		ctx := context.WithValue(r.Context(), userContextKey, ctxUser)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequireStaff validates the bearer token and enforces staff portal access.
+func (h *Handler) RequireStaff(next http.Handler) http.Handler {
+	return h.RequirePermission(PermStaffAccess)(next)
+}
+
+// RequirePermission authenticates the request, unless an upstream middleware already did,
+// and enforces that the user holds every listed permission. When the MFA policy applies to the
+// user's role, sessions without a second factor are refused. API keys are checked against their
+// scopes and are not subject to the MFA policy.
+func (h *Handler) RequirePermission(perms ...Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctxUser, ok := UserFromContext(r.Context())
+			if !ok {
+				ctxUser, ok = h.authenticate(w, r)
+				if !ok {
+					return
+				}
+				r = r.WithContext(context.WithValue(r.Context(), userContextKey, ctxUser))
 			}
-			return
+
+			if !ctxUser.Can(perms...) {
+				writeError(w, http.StatusForbidden, ErrForbidden.Error())
+				return
+			}
+
+			if !ctxUser.MFA && h.service.mfaRequiredForRole(ctxUser.Role) {
+				writeError(w, http.StatusForbidden, ErrMFARequired.Error())
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authenticate resolves the bearer token or API key into a UserContext, writing an error
+// response on failure.
+func (h *Handler) authenticate(w http.ResponseWriter, r *http.Request) (UserContext, bool) {
+	header := strings.TrimSpace(r.Header.Get("Authorization"))
+	if header == "" {
+		writeError(w, http.StatusUnauthorized, "missing authorization header")
+		return UserContext{}, false
+	}
+
+	scheme, credential, _ := strings.Cut(header, " ")
+	credential = strings.TrimSpace(credential)
+	switch strings.ToLower(scheme) {
+	case "bearer":
+	case "apikey":
+		return h.authenticateAPIKey(w, r, credential)
+	default:
+		writeError(w, http.StatusUnauthorized, "bearer token or api key required")
+		return UserContext{}, false
+	}
+
+	user, claims, err := h.service.validateAccessToken(r.Context(), credential)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAccessTokenExpired):
+			writeError(w, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, ErrAccessTokenRevoked):
+			writeError(w, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, ErrInvalidAccessToken):
+			writeError(w, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, ErrInactiveAccount):
+			writeError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, ErrEmailNotVerified):
+			writeError(w, http.StatusForbidden, err.Error())
+		default:
+			writeError(w, http.StatusUnauthorized, "unauthorized")
 		}
+		return UserContext{}, false
+	}
 
-		role := strings.ToLower(user.Role)
-		if role != "staff" && role != "admin" {
-			writeError(w, http.StatusForbidden, ErrForbidden.Error())
-			return
+	ctxUser := UserContext{
+		ID:          user.ID,
+		Email:       user.Email,
+		Role:        user.Role,
+		Permissions: PermissionsForRole(user.Role),
+		MFA:         slices.Contains(claims.AMR, amrOTP),
+	}
+	if sessionID, err := uuid.Parse(claims.SessionID); err == nil {
+		ctxUser.SessionID = sessionID
+	}
+	if claims.Actor != nil {
+		// validateAccessToken has already checked the actor.
+		ctxUser.ImpersonatorID, _ = uuid.Parse(claims.Actor.Subject)
+		if !readOnlyMethod(r.Method) {
+			writeError(w, http.StatusForbidden, ErrImpersonationReadOnly.Error())
+			return UserContext{}, false
 		}
+	}
+	return ctxUser, true
+}
 
-		ctxUser := UserContext{ID: user.ID, Email: user.Email, Role: user.Role}
-		ctx := context.WithValue(r.Context(), userContextKey, ctxUser)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+// readOnlyMethod reports whether a request method cannot change state, and so may be served
+// to an impersonation token.
+func readOnlyMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// authenticateAPIKey resolves an "Authorization: ApiKey <key>" credential.
+func (h *Handler) authenticateAPIKey(w http.ResponseWriter, r *http.Request, key string) (UserContext, bool) {
+	ctxUser, err := h.service.ValidateAPIKey(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, ErrInvalidAPIKey) {
+			writeError(w, http.StatusUnauthorized, err.Error())
+		} else {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+		}
+		return UserContext{}, false
+	}
+	return ctxUser, true
 }
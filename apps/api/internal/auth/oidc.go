@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/oidc"
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+// oidcStateTTL bounds how long a user may spend at the identity provider.
+const oidcStateTTL = 10 * time.Minute
+
+var (
+	// ErrUnknownIdentityProvider indicates no OIDC provider is configured under that name.
+	ErrUnknownIdentityProvider = errors.New("unknown identity provider")
+	// ErrInvalidOIDCState signals the sign-in state was unknown, expired or already used.
+	ErrInvalidOIDCState = errors.New("invalid or expired sign-in state")
+	// ErrOIDCEmailUnverified indicates the provider did not assert a verified email address.
+	ErrOIDCEmailUnverified = errors.New("identity provider did not supply a verified email address")
+	// ErrOIDCFailed signals the code exchange or ID token validation failed.
+	ErrOIDCFailed = errors.New("identity provider sign-in failed")
+)
+
+// OIDCStart is returned when a social sign-in begins. Clients should keep State (e.g. in
+// sessionStorage) and only forward callbacks whose state matches.
+type OIDCStart struct {
+	AuthorizationURL string
+	State            string
+	ExpiresAt        time.Time
+}
+
+// OIDCCallbackInput carries the parameters the provider redirected back with.
+type OIDCCallbackInput struct {
+	Provider  string
+	Code      string
+	State     string
+	UserAgent string
+	IP        string
+}
+
+// WithOIDCProviders registers the identity providers available for social sign-in.
+func (s *Service) WithOIDCProviders(providers ...*oidc.Provider) *Service {
+	if s.identityProviders == nil {
+		s.identityProviders = make(map[string]*oidc.Provider, len(providers))
+	}
+	for _, provider := range providers {
+		if provider != nil {
+			s.identityProviders[provider.Name()] = provider
+		}
+	}
+	return s
+}
+
+// OIDCProviders lists the configured identity provider names.
+func (s *Service) OIDCProviders() []string {
+	names := make([]string, 0, len(s.identityProviders))
+	for name := range s.identityProviders {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// StartOIDC records a new authorization request and returns the provider URL to redirect to.
+func (s *Service) StartOIDC(ctx context.Context, providerName string) (OIDCStart, error) {
+	provider, ok := s.identityProviders[providerName]
+	if !ok {
+		return OIDCStart{}, ErrUnknownIdentityProvider
+	}
+
+	now := s.now()
+	state, hashedState, expiresAt, err := generateOpaqueToken(oidcStateTTL, now)
+	if err != nil {
+		return OIDCStart{}, err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return OIDCStart{}, err
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return OIDCStart{}, err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return OIDCStart{}, err
+	}
+
+	q := s.store.Queries()
+	if err := q.DeleteExpiredOIDCAuthRequests(ctx, now); err != nil {
+		s.logger.Warn().Err(err).Msg("failed to prune expired oidc auth requests")
+	}
+
+	if err := q.CreateOIDCAuthRequest(ctx, queries.CreateOIDCAuthRequestParams{
+		Provider:     providerName,
+		StateHash:    hashedState,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    expiresAt,
+	}); err != nil {
+		return OIDCStart{}, err
+	}
+
+	return OIDCStart{AuthorizationURL: authURL, State: state, ExpiresAt: expiresAt}, nil
+}
+
+// CompleteOIDC redeems the provider callback and signs the user in, producing the same
+// AuthResult as Login. Unknown identities are linked to an existing account with the same
+// verified email, or a new seeker account is created.
+func (s *Service) CompleteOIDC(ctx context.Context, input OIDCCallbackInput) (AuthResult, error) {
+	provider, ok := s.identityProviders[input.Provider]
+	if !ok {
+		return AuthResult{}, ErrUnknownIdentityProvider
+	}
+
+	state := strings.TrimSpace(input.State)
+	code := strings.TrimSpace(input.Code)
+	if state == "" || code == "" {
+		return AuthResult{}, ErrInvalidOIDCState
+	}
+
+	// Taking the request deletes it, so each state can be redeemed at most once.
+	request, err := s.store.Queries().TakeOIDCAuthRequest(ctx, hashOpaqueToken(state))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return AuthResult{}, ErrInvalidOIDCState
+		}
+		return AuthResult{}, err
+	}
+	if request.Provider != input.Provider || request.ExpiresAt.Before(s.now()) {
+		return AuthResult{}, ErrInvalidOIDCState
+	}
+
+	claims, err := provider.Exchange(ctx, code, request.CodeVerifier, request.Nonce)
+	if err != nil {
+		s.logger.Warn().Err(err).Str("provider", input.Provider).Msg("oidc sign-in failed")
+		return AuthResult{}, fmt.Errorf("%w: %v", ErrOIDCFailed, err)
+	}
+
+	var result AuthResult
+	var userID uuid.UUID
+	err = s.store.WithTx(ctx, func(q *queries.Queries) error {
+		user, err := s.resolveIdentity(ctx, q, input.Provider, claims)
+		if err != nil {
+			return err
+		}
+		userID = user.ID
+
+		switch strings.ToLower(user.Status) {
+		case statusActive:
+		default:
+			return ErrInactiveAccount
+		}
+
+		meta := SessionMetadata{UserAgent: input.UserAgent, IP: input.IP}
+		result, err = s.completeLogin(ctx, q, user, meta, []string{amrFederated})
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// Linking a pending account activates it and revokes whatever tokens it had.
+	s.invalidatePrincipal(ctx, userID)
+	s.revocations.markStale()
+	return result, nil
+}
+
+// resolveIdentity finds or creates the local user for an external identity.
+func (s *Service) resolveIdentity(ctx context.Context, q *queries.Queries, providerName string, claims oidc.Claims) (queries.User, error) {
+	email := sql.NullString{String: claims.Email, Valid: claims.Email != ""}
+
+	identity, err := q.GetUserIdentity(ctx, queries.GetUserIdentityParams{
+		Provider: providerName,
+		Subject:  claims.Subject,
+	})
+	if err == nil {
+		if err := q.TouchUserIdentity(ctx, queries.TouchUserIdentityParams{ID: identity.ID, Email: email}); err != nil {
+			return queries.User{}, err
+		}
+		return q.GetUserByID(ctx, identity.UserID)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return queries.User{}, err
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return queries.User{}, ErrOIDCEmailUnverified
+	}
+
+	user, err := q.GetUserByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		user, err = s.linkExistingUser(ctx, q, user)
+		if err != nil {
+			return queries.User{}, err
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		// Social-only accounts have no password until the user sets one via password reset.
+		user, err = q.CreateUser(ctx, queries.CreateUserParams{
+			Email:        claims.Email,
+			PasswordHash: "",
+			Status:       sql.NullString{String: statusActive, Valid: true},
+		})
+		if err != nil {
+			return queries.User{}, err
+		}
+	default:
+		return queries.User{}, err
+	}
+
+	if _, err := q.CreateUserIdentity(ctx, queries.CreateUserIdentityParams{
+		UserID:   user.ID,
+		Provider: providerName,
+		Subject:  claims.Subject,
+		Email:    email,
+	}); err != nil {
+		return queries.User{}, err
+	}
+
+	s.logger.Info().
+		Str("event", "identity_linked").
+		Str("user_id", user.ID.String()).
+		Str("provider", providerName).
+		Msg("external identity linked")
+	return user, nil
+}
+
+// linkExistingUser prepares an account with a matching verified email for linking. An account
+// that never verified its email may have been registered by someone else to squat the
+// address, so its password and sessions are discarded and the provider's verification counts.
+func (s *Service) linkExistingUser(ctx context.Context, q *queries.Queries, user queries.User) (queries.User, error) {
+	if strings.ToLower(user.Status) != statusPendingVerification {
+		return user, nil
+	}
+
+	if err := q.UpdateUserPassword(ctx, queries.UpdateUserPasswordParams{ID: user.ID, PasswordHash: ""}); err != nil {
+		return queries.User{}, err
+	}
+	if err := s.revokeAllAccessTokens(ctx, q, user.ID); err != nil {
+		return queries.User{}, err
+	}
+	if err := q.DeleteUserSessionsByUserID(ctx, user.ID); err != nil {
+		return queries.User{}, err
+	}
+	return q.UpdateUserStatus(ctx, queries.UpdateUserStatusParams{ID: user.ID, Status: statusActive})
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/synergyvets/platform/internal/clientip"
+)
+
+type oidcCallbackRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+type oidcStartResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+	ExpiresAt        string `json:"expires_at"`
+}
+
+func (h *Handler) handleOIDCProviders(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"providers": h.service.OIDCProviders()})
+}
+
+func (h *Handler) handleStartOIDC(w http.ResponseWriter, r *http.Request) {
+	start, err := h.service.StartOIDC(r.Context(), chi.URLParam(r, "provider"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUnknownIdentityProvider):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusBadGateway, "failed to start sign-in with identity provider")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, oidcStartResponse{
+		AuthorizationURL: start.AuthorizationURL,
+		State:            start.State,
+		ExpiresAt:        start.ExpiresAt.UTC().Format(time.RFC3339),
+	})
+}
+
+func (h *Handler) handleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	var req oidcCallbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	result, err := h.service.CompleteOIDC(r.Context(), OIDCCallbackInput{
+		Provider:  chi.URLParam(r, "provider"),
+		Code:      req.Code,
+		State:     req.State,
+		UserAgent: r.UserAgent(),
+		IP:        clientip.FromRequest(r),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUnknownIdentityProvider):
+			writeError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, ErrInvalidOIDCState):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrOIDCEmailUnverified):
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, ErrOIDCFailed):
+			writeError(w, http.StatusUnauthorized, ErrOIDCFailed.Error())
+		case errors.Is(err, ErrInactiveAccount):
+			writeError(w, http.StatusForbidden, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to sign in with identity provider")
+		}
+		return
+	}
+
+	h.writeLoginResponse(w, result)
+}
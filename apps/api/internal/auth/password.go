@@ -9,64 +9,143 @@ import (
 	"strings"
 
 	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
 )
 
 const (
-	argonTime    uint32 = 1
-	argonMemory  uint32 = 64 * 1024
-	argonThreads uint8  = 1
 	argonKeyLen  uint32 = 32
 	argonSaltLen        = 16
 )
 
-var errInvalidHash = errors.New("invalid password hash")
+var (
+	errInvalidHash      = errors.New("invalid password hash")
+	errPasswordMismatch = errors.New("password mismatch")
+)
+
+// PasswordParams holds the argon2id cost parameters used for new hashes.
+type PasswordParams struct {
+	Time    uint32
+	Memory  uint32 // KiB
+	Threads uint8
+}
+
+// DefaultPasswordParams returns the baseline argon2id parameters (t=1, m=64MiB, p=1).
+func DefaultPasswordParams() PasswordParams {
+	return PasswordParams{Time: 1, Memory: 64 * 1024, Threads: 1}
+}
+
+func (p PasswordParams) withDefaults() PasswordParams {
+	defaults := DefaultPasswordParams()
+	if p.Time == 0 {
+		p.Time = defaults.Time
+	}
+	if p.Memory == 0 {
+		p.Memory = defaults.Memory
+	}
+	if p.Threads == 0 {
+		p.Threads = defaults.Threads
+	}
+	return p
+}
 
-func hashPassword(password string) (string, error) {
+func hashPassword(password string, params PasswordParams) (string, error) {
 	salt := make([]byte, argonSaltLen)
 	if _, err := rand.Read(salt); err != nil {
 		return "", err
 	}
 
-	hash := argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	hash := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, argonKeyLen)
 
 	b64Salt := base64.RawStdEncoding.EncodeToString(salt)
 	b64Hash := base64.RawStdEncoding.EncodeToString(hash)
 
-	encoded := fmt.Sprintf("$argon2id$v=19$m=%d,t=%d,p=%d$%s$%s", argonMemory, argonTime, argonThreads, b64Salt, b64Hash)
+	encoded := fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, params.Memory, params.Time, params.Threads, b64Salt, b64Hash)
 	return encoded, nil
 }
 
 func verifyPassword(encoded, password string) error {
-	parts := strings.Split(encoded, "$")
-	if len(parts) != 6 {
-		return errInvalidHash
+	if isBcryptHash(encoded) {
+		if err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return errPasswordMismatch
+			}
+			return errInvalidHash
+		}
+		return nil
 	}
 
-	var memory uint32
-	var time uint32
-	var threads uint8
-	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
-		return errInvalidHash
+	parsed, err := parseArgonHash(encoded)
+	if err != nil {
+		return err
+	}
+
+	computed := argon2.IDKey([]byte(password), parsed.salt, parsed.params.Time, parsed.params.Memory, parsed.params.Threads, uint32(len(parsed.sum)))
+	if len(computed) != len(parsed.sum) {
+		return errPasswordMismatch
+	}
+
+	if subtle.ConstantTimeCompare(computed, parsed.sum) == 1 {
+		return nil
 	}
 
-	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	return errPasswordMismatch
+}
+
+// needsRehash reports whether a stored hash should be replaced with one using the current parameters,
+// either because it comes from a legacy format or because the argon2id costs have changed.
+func needsRehash(encoded string, params PasswordParams) bool {
+	parsed, err := parseArgonHash(encoded)
 	if err != nil {
-		return errInvalidHash
+		return true
 	}
 
-	sum, err := base64.RawStdEncoding.DecodeString(parts[5])
+	return parsed.version != argon2.Version ||
+		parsed.params != params ||
+		len(parsed.sum) != int(argonKeyLen)
+}
+
+func isBcryptHash(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+type argonHash struct {
+	version int
+	params  PasswordParams
+	salt    []byte
+	sum     []byte
+}
+
+func parseArgonHash(encoded string) (argonHash, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return argonHash{}, errInvalidHash
+	}
+
+	var parsed argonHash
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &parsed.version); err != nil {
+		return argonHash{}, errInvalidHash
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &parsed.params.Memory, &parsed.params.Time, &parsed.params.Threads); err != nil {
+		return argonHash{}, errInvalidHash
+	}
+
+	var err error
+	parsed.salt, err = base64.RawStdEncoding.DecodeString(parts[4])
 	if err != nil {
-		return errInvalidHash
+		return argonHash{}, errInvalidHash
 	}
 
-	computed := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(sum)))
-	if len(computed) != len(sum) {
-		return errors.New("password mismatch")
+	parsed.sum, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return argonHash{}, errInvalidHash
 	}
 
-	if subtle.ConstantTimeCompare(computed, sum) == 1 {
-		return nil
+	// An empty sum would match every password, and argon2 panics on zero costs.
+	if len(parsed.salt) == 0 || len(parsed.sum) == 0 ||
+		parsed.params.Time == 0 || parsed.params.Memory == 0 || parsed.params.Threads == 0 {
+		return argonHash{}, errInvalidHash
 	}
 
-	return errors.New("password mismatch")
+	return parsed, nil
 }
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+// ForgotPassword emails a password reset link when the account exists.
+// It reports success regardless of whether the email exists to avoid account enumeration.
+func (s *Service) ForgotPassword(ctx context.Context, email string) error {
+	normalized := strings.TrimSpace(strings.ToLower(email))
+	if normalized == "" {
+		return ErrInvalidEmail
+	}
+
+	var recipient, resetToken string
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		user, err := q.GetUserByEmail(ctx, normalized)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+
+		switch strings.ToLower(user.Status) {
+		case statusActive, statusPendingVerification:
+		default:
+			return nil
+		}
+
+		if err := q.DeletePendingPasswordResetTokens(ctx, user.ID); err != nil {
+			return err
+		}
+
+		token, hashed, expiresAt, err := generateOpaqueToken(s.config.PasswordResetTokenTTL, s.now())
+		if err != nil {
+			return err
+		}
+
+		if _, err := q.CreatePasswordResetToken(ctx, queries.CreatePasswordResetTokenParams{
+			UserID:    user.ID,
+			TokenHash: hashed,
+			ExpiresAt: expiresAt,
+		}); err != nil {
+			return err
+		}
+
+		recipient = user.Email
+		resetToken = token
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if resetToken != "" {
+		// Deliver in the background so response timing does not reveal whether the account exists.
+		go s.sendPasswordResetEmail(context.WithoutCancel(ctx), recipient, resetToken)
+	}
+	return nil
+}
+
+// ResetPassword consumes a reset token, sets the new password and revokes every session.
+func (s *Service) ResetPassword(ctx context.Context, resetToken, newPassword string) error {
+	token := strings.TrimSpace(resetToken)
+	if token == "" {
+		return ErrInvalidResetToken
+	}
+
+	password, err := normalizePassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	hashed := hashOpaqueToken(token)
+
+	var userID uuid.UUID
+	err = s.store.WithTx(ctx, func(q *queries.Queries) error {
+		record, err := q.GetPasswordResetTokenByHash(ctx, hashed)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidResetToken
+			}
+			return err
+		}
+
+		if record.ConsumedAt.Valid {
+			return ErrInvalidResetToken
+		}
+		if record.ExpiresAt.Before(s.now()) {
+			return ErrExpiredResetToken
+		}
+
+		user, err := q.GetUserByID(ctx, record.UserID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidResetToken
+			}
+			return err
+		}
+
+		consumed, err := q.ConsumePasswordResetToken(ctx, record.ID)
+		if err != nil {
+			return err
+		}
+		if consumed == 0 {
+			return ErrInvalidResetToken
+		}
+
+		passwordHash, err := hashPassword(password, s.config.Password)
+		if err != nil {
+			return err
+		}
+
+		if err := q.UpdateUserPassword(ctx, queries.UpdateUserPasswordParams{
+			ID:           user.ID,
+			PasswordHash: passwordHash,
+		}); err != nil {
+			return err
+		}
+
+		// Following the emailed link proves ownership of the address.
+		if strings.ToLower(user.Status) == statusPendingVerification {
+			if _, err := q.UpdateUserStatus(ctx, queries.UpdateUserStatusParams{
+				ID:     user.ID,
+				Status: statusActive,
+			}); err != nil {
+				return err
+			}
+		}
+
+		userID = user.ID
+		return s.revokeAllSessions(ctx, q, user.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidatePrincipal(ctx, userID)
+	s.revocations.markStale()
+	return nil
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testParams are cheap argon2id costs; testChangedParams differs from them only in Time.
+var (
+	testParams        = PasswordParams{Time: 1, Memory: 1024, Threads: 1}
+	testChangedParams = PasswordParams{Time: 2, Memory: 1024, Threads: 1}
+)
+
+func bcryptHash(t *testing.T, password, prefix string) string {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt: %v", err)
+	}
+	// The Go package writes $2a$; the other prefixes come from PHP and OpenBSD.
+	return prefix + strings.TrimPrefix(string(hash), "$2a$")
+}
+
+func newArgonHash(t *testing.T, password string, params PasswordParams) string {
+	t.Helper()
+
+	hash, err := hashPassword(password, params)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	return hash
+}
+
+func TestVerifyPassword(t *testing.T) {
+	argon := newArgonHash(t, testPassword, testParams)
+	parts := strings.Split(argon, "$")
+
+	tests := []struct {
+		name     string
+		encoded  string
+		password string
+		wantErr  error
+	}{
+		{name: "argon2id", encoded: argon, password: testPassword},
+		{name: "argon2id wrong password", encoded: argon, password: "wrong-password", wantErr: errPasswordMismatch},
+		{name: "argon2id other params", encoded: newArgonHash(t, testPassword, testChangedParams), password: testPassword},
+		{name: "bcrypt $2a$", encoded: bcryptHash(t, testPassword, "$2a$"), password: testPassword},
+		{name: "bcrypt $2b$", encoded: bcryptHash(t, testPassword, "$2b$"), password: testPassword},
+		{name: "bcrypt $2y$", encoded: bcryptHash(t, testPassword, "$2y$"), password: testPassword},
+		{name: "bcrypt wrong password", encoded: bcryptHash(t, testPassword, "$2a$"), password: "wrong-password", wantErr: errPasswordMismatch},
+		{name: "bcrypt truncated", encoded: bcryptHash(t, testPassword, "$2a$")[:20], password: testPassword, wantErr: errInvalidHash},
+		{name: "empty", encoded: "", password: testPassword, wantErr: errInvalidHash},
+		{name: "unknown scheme", encoded: "$argon2i$v=19$m=1024,t=1,p=1$" + parts[4] + "$" + parts[5], password: testPassword, wantErr: errInvalidHash},
+		{name: "missing part", encoded: strings.Join(parts[:5], "$"), password: testPassword, wantErr: errInvalidHash},
+		{name: "bad version", encoded: "$argon2id$v=x$" + strings.Join(parts[3:], "$"), password: testPassword, wantErr: errInvalidHash},
+		{name: "bad params", encoded: "$argon2id$v=19$m=1024$" + parts[4] + "$" + parts[5], password: testPassword, wantErr: errInvalidHash},
+		{name: "zero params", encoded: "$argon2id$v=19$m=0,t=0,p=0$" + parts[4] + "$" + parts[5], password: testPassword, wantErr: errInvalidHash},
+		{name: "bad salt", encoded: "$argon2id$v=19$m=1024,t=1,p=1$!!$" + parts[5], password: testPassword, wantErr: errInvalidHash},
+		{name: "empty sum", encoded: "$argon2id$v=19$m=1024,t=1,p=1$" + parts[4] + "$", password: "anything", wantErr: errInvalidHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyPassword(tt.encoded, tt.password)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	current := newArgonHash(t, testPassword, testParams)
+	parts := strings.Split(current, "$")
+
+	tests := []struct {
+		name    string
+		encoded string
+		want    bool
+	}{
+		{name: "current params", encoded: current, want: false},
+		{name: "changed time", encoded: newArgonHash(t, testPassword, testChangedParams), want: true},
+		{name: "changed memory", encoded: newArgonHash(t, testPassword, PasswordParams{Time: 1, Memory: 2048, Threads: 1}), want: true},
+		{name: "changed threads", encoded: newArgonHash(t, testPassword, PasswordParams{Time: 1, Memory: 1024, Threads: 2}), want: true},
+		{name: "older argon2 version", encoded: "$argon2id$v=16$" + strings.Join(parts[3:], "$"), want: true},
+		{name: "shorter key", encoded: strings.Join(parts[:5], "$") + "$" + parts[5][:22], want: true},
+		{name: "legacy bcrypt", encoded: bcryptHash(t, testPassword, "$2a$"), want: true},
+		{name: "malformed", encoded: "not-a-hash", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := needsRehash(tt.encoded, testParams); got != tt.want {
+				t.Errorf("needsRehash(%q) = %v, want %v", tt.encoded, got, tt.want)
+			}
+		})
+	}
+}
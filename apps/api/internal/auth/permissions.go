@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"slices"
+	"strings"
+)
+
+// Permission names a capability checked by RequirePermission, in "resource:action" form.
+type Permission string
+
+// Permissions understood by the API.
+const (
+	PermStaffAccess        Permission = "staff:access"
+	PermAnnouncementsRead  Permission = "announcements:read"
+	PermAnnouncementsWrite Permission = "announcements:write"
+	PermJobsWrite          Permission = "jobs:write"
+	PermJobsPublish        Permission = "jobs:publish"
+	PermArticlesWrite      Permission = "articles:write"
+	PermArticlesPublish    Permission = "articles:publish"
+	PermApplicationsRead   Permission = "applications:read"
+	PermApplicationsWrite  Permission = "applications:write"
+	PermUsersRead          Permission = "users:read"
+	PermUsersManage        Permission = "users:manage"
+	PermUsersImpersonate   Permission = "users:impersonate"
+	PermPrivacyManage      Permission = "privacy:manage"
+	PermAuditRead          Permission = "audit:read"
+	PermAPIKeysManage      Permission = "api_keys:manage"
+	PermMetricsRead        Permission = "metrics:read"
+)
+
+// allPermissions lists every permission, in declaration order.
+var allPermissions = []Permission{
+	PermStaffAccess,
+	PermAnnouncementsRead,
+	PermAnnouncementsWrite,
+	PermJobsWrite,
+	PermJobsPublish,
+	PermArticlesWrite,
+	PermArticlesPublish,
+	PermApplicationsRead,
+	PermApplicationsWrite,
+	PermUsersRead,
+	PermUsersManage,
+	PermUsersImpersonate,
+	PermPrivacyManage,
+	PermAuditRead,
+	PermAPIKeysManage,
+	PermMetricsRead,
+}
+
+// Roles stored in users.role.
+const (
+	RoleSeeker      = "seeker"
+	RoleStaffEditor = "staff_editor"
+	RoleStaffAdmin  = "staff_admin"
+)
+
+var editorPermissions = []Permission{
+	PermStaffAccess,
+	PermAnnouncementsRead,
+	PermAnnouncementsWrite,
+	PermJobsWrite,
+	PermJobsPublish,
+	PermArticlesWrite,
+	PermArticlesPublish,
+	PermApplicationsRead,
+}
+
+// rolePermissions maps each role to the permissions it grants. Unknown roles grant nothing.
+var rolePermissions = map[string][]Permission{
+	RoleSeeker:      {},
+	RoleStaffEditor: editorPermissions,
+	RoleStaffAdmin: append(slices.Clone(editorPermissions),
+		PermApplicationsWrite,
+		PermUsersRead,
+		PermUsersManage,
+		PermUsersImpersonate,
+		PermPrivacyManage,
+		PermAuditRead,
+		PermAPIKeysManage,
+		PermMetricsRead,
+	),
+}
+
+// PermissionsForRole resolves the permission set granted by a role.
+func PermissionsForRole(role string) []Permission {
+	return slices.Clone(rolePermissions[strings.ToLower(strings.TrimSpace(role))])
+}
+
+// ValidRole reports whether role is one of the known roles.
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// ValidPermission reports whether perm is one of the known permissions.
+func ValidPermission(perm Permission) bool {
+	return slices.Contains(allPermissions, perm)
+}
+
+// IsStaffRole reports whether the role grants access to the staff portal.
+func IsStaffRole(role string) bool {
+	return slices.Contains(PermissionsForRole(role), PermStaffAccess)
+}
+
+// Can reports whether the user holds every one of the given permissions.
+func (u UserContext) Can(perms ...Permission) bool {
+	for _, perm := range perms {
+		if !slices.Contains(u.Permissions, perm) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+// Principal is the part of a user record needed to authorize a request.
+type Principal struct {
+	ID     uuid.UUID
+	Email  string
+	Role   string
+	Status string
+}
+
+func principalFromUser(user queries.User) Principal {
+	return Principal{
+		ID:     user.ID,
+		Email:  user.Email,
+		Role:   user.Role,
+		Status: user.Status,
+	}
+}
+
+// PrincipalCache keeps recently authenticated principals so access token validation can skip
+// the user lookup. Implementations must be safe for concurrent use; a store shared between
+// instances can be plugged in with Service.WithPrincipalCache.
+type PrincipalCache interface {
+	Get(ctx context.Context, id uuid.UUID) (Principal, bool)
+	Set(ctx context.Context, principal Principal)
+	Delete(ctx context.Context, id uuid.UUID)
+}
+
+// PrincipalCacheStats reports how effective the principal cache has been since startup.
+type PrincipalCacheStats struct {
+	Hits    uint64  `json:"hits"`
+	Misses  uint64  `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// MemoryPrincipalCache is an in-process PrincipalCache bounded by entry count, evicting the
+// least recently used principal when full. Entries expire after the TTL so changes made by
+// other instances are eventually observed.
+type MemoryPrincipalCache struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[uuid.UUID]*list.Element
+}
+
+type principalEntry struct {
+	principal Principal
+	expiresAt time.Time
+}
+
+// NewMemoryPrincipalCache builds a cache holding at most size principals for ttl each.
+func NewMemoryPrincipalCache(size int, ttl time.Duration) *MemoryPrincipalCache {
+	if size <= 0 {
+		size = 10000
+	}
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+
+	return &MemoryPrincipalCache{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[uuid.UUID]*list.Element, size),
+	}
+}
+
+// Get returns the cached principal unless it is missing or expired.
+func (c *MemoryPrincipalCache) Get(_ context.Context, id uuid.UUID) (Principal, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[id]
+	if !ok {
+		return Principal{}, false
+	}
+
+	entry := elem.Value.(*principalEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.entries, id)
+		return Principal{}, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.principal, true
+}
+
+// Set stores a principal, evicting the least recently used entry when the cache is full.
+func (c *MemoryPrincipalCache) Set(_ context.Context, principal Principal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if elem, ok := c.entries[principal.ID]; ok {
+		elem.Value = &principalEntry{principal: principal, expiresAt: expiresAt}
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[principal.ID] = c.order.PushFront(&principalEntry{principal: principal, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*principalEntry).principal.ID)
+	}
+}
+
+// Delete drops a principal so the next request reloads it.
+func (c *MemoryPrincipalCache) Delete(_ context.Context, id uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[id]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, id)
+	}
+}
+
+// WithPrincipalCache replaces the default in-process principal cache.
+func (s *Service) WithPrincipalCache(cache PrincipalCache) *Service {
+	if cache != nil {
+		s.principals = cache
+	}
+	return s
+}
+
+// PrincipalCacheStats returns hit and miss counts for access token validation lookups.
+func (s *Service) PrincipalCacheStats() PrincipalCacheStats {
+	stats := PrincipalCacheStats{
+		Hits:   s.principalHits.Load(),
+		Misses: s.principalMisses.Load(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// loadPrincipal returns the principal for a user, reading through the cache.
+func (s *Service) loadPrincipal(ctx context.Context, userID uuid.UUID) (Principal, error) {
+	if principal, ok := s.principals.Get(ctx, userID); ok {
+		s.principalHits.Add(1)
+		return principal, nil
+	}
+	s.principalMisses.Add(1)
+
+	user, err := s.store.Queries().GetUserByID(ctx, userID)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	principal := principalFromUser(user)
+	s.principals.Set(ctx, principal)
+	return principal, nil
+}
+
+// invalidatePrincipal drops a cached principal. Call it once a change to the user's status,
+// role or email has committed.
+func (s *Service) invalidatePrincipal(ctx context.Context, userID uuid.UUID) {
+	s.principals.Delete(ctx, userID)
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// newTestPrincipalCache returns a cache whose clock only moves when advanced.
+func newTestPrincipalCache(size int, ttl time.Duration) (*MemoryPrincipalCache, *testClock) {
+	clock := &testClock{now: time.Now()}
+	cache := NewMemoryPrincipalCache(size, ttl)
+	cache.now = clock.Now
+	return cache, clock
+}
+
+func testPrincipal() Principal {
+	return Principal{ID: uuid.New(), Role: RoleSeeker, Status: statusActive}
+}
+
+func TestMemoryPrincipalCacheGetSetDelete(t *testing.T) {
+	cache, _ := newTestPrincipalCache(10, time.Minute)
+	ctx := context.Background()
+	principal := testPrincipal()
+
+	if _, ok := cache.Get(ctx, principal.ID); ok {
+		t.Fatal("empty cache returned a principal")
+	}
+
+	cache.Set(ctx, principal)
+	if got, ok := cache.Get(ctx, principal.ID); !ok || got != principal {
+		t.Fatalf("Get = %+v, %v, want %+v", got, ok, principal)
+	}
+
+	updated := principal
+	updated.Role = RoleStaffEditor
+	cache.Set(ctx, updated)
+	if got, _ := cache.Get(ctx, principal.ID); got != updated {
+		t.Errorf("Get after update = %+v, want %+v", got, updated)
+	}
+
+	cache.Delete(ctx, principal.ID)
+	if _, ok := cache.Get(ctx, principal.ID); ok {
+		t.Error("deleted principal still cached")
+	}
+}
+
+func TestMemoryPrincipalCacheExpiry(t *testing.T) {
+	cache, clock := newTestPrincipalCache(10, time.Minute)
+	ctx := context.Background()
+	principal := testPrincipal()
+	cache.Set(ctx, principal)
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		want    bool
+	}{
+		{name: "before ttl", advance: time.Minute - time.Second, want: true},
+		{name: "at ttl", advance: time.Second, want: false},
+	}
+
+	for _, tt := range tests {
+		clock.Advance(tt.advance)
+		if _, ok := cache.Get(ctx, principal.ID); ok != tt.want {
+			t.Errorf("%s: cached = %v, want %v", tt.name, ok, tt.want)
+		}
+	}
+	if len(cache.entries) != 0 || cache.order.Len() != 0 {
+		t.Errorf("expired entry kept: %d entries, %d in order", len(cache.entries), cache.order.Len())
+	}
+}
+
+func TestMemoryPrincipalCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache, _ := newTestPrincipalCache(2, time.Minute)
+	ctx := context.Background()
+	first, second, third := testPrincipal(), testPrincipal(), testPrincipal()
+
+	cache.Set(ctx, first)
+	cache.Set(ctx, second)
+	// Reading first makes second the least recently used.
+	cache.Get(ctx, first.ID)
+	cache.Set(ctx, third)
+
+	tests := []struct {
+		name string
+		id   uuid.UUID
+		want bool
+	}{
+		{name: "recently read", id: first.ID, want: true},
+		{name: "least recently used", id: second.ID, want: false},
+		{name: "newest", id: third.ID, want: true},
+	}
+	for _, tt := range tests {
+		if _, ok := cache.Get(ctx, tt.id); ok != tt.want {
+			t.Errorf("%s: cached = %v, want %v", tt.name, ok, tt.want)
+		}
+	}
+	if len(cache.entries) != 2 || cache.order.Len() != 2 {
+		t.Errorf("cache holds %d entries, %d in order, want 2", len(cache.entries), cache.order.Len())
+	}
+}
+
+// TestMemoryPrincipalCacheConcurrentUse is meant for go test -race.
+func TestMemoryPrincipalCacheConcurrentUse(t *testing.T) {
+	cache, clock := newTestPrincipalCache(16, time.Minute)
+	ctx := context.Background()
+
+	principals := make([]Principal, 64)
+	for i := range principals {
+		principals[i] = testPrincipal()
+	}
+
+	var wg sync.WaitGroup
+	for worker := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 500 {
+				principal := principals[(worker*7+i)%len(principals)]
+				switch i % 4 {
+				case 0:
+					cache.Delete(ctx, principal.ID)
+				case 1:
+					clock.Advance(time.Millisecond)
+					fallthrough
+				default:
+					cache.Set(ctx, principal)
+				}
+				if got, ok := cache.Get(ctx, principal.ID); ok && got.ID != principal.ID {
+					t.Errorf("Get(%s) returned %s", principal.ID, got.ID)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if len(cache.entries) > 16 || len(cache.entries) != cache.order.Len() {
+		t.Errorf("cache holds %d entries and %d in order, want at most 16 of each", len(cache.entries), cache.order.Len())
+	}
+}
+
+func TestPrincipalCacheStats(t *testing.T) {
+	tests := []struct {
+		name         string
+		hits, misses uint64
+		want         PrincipalCacheStats
+	}{
+		{name: "unused", want: PrincipalCacheStats{}},
+		{name: "all misses", misses: 4, want: PrincipalCacheStats{Misses: 4}},
+		{name: "mixed", hits: 3, misses: 1, want: PrincipalCacheStats{Hits: 3, Misses: 1, HitRate: 0.75}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Service
+			s.principalHits.Store(tt.hits)
+			s.principalMisses.Store(tt.misses)
+			if got := s.PrincipalCacheStats(); got != tt.want {
+				t.Errorf("PrincipalCacheStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPrincipalCountsHitsAndMisses(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+	ctx := context.Background()
+	user := createTestUser(t, s, RoleSeeker)
+
+	for range 3 {
+		if _, err := s.loadPrincipal(ctx, user.ID); err != nil {
+			t.Fatalf("load principal: %v", err)
+		}
+	}
+	s.invalidatePrincipal(ctx, user.ID)
+	if _, err := s.loadPrincipal(ctx, user.ID); err != nil {
+		t.Fatalf("load principal after invalidation: %v", err)
+	}
+
+	if stats := s.PrincipalCacheStats(); stats.Hits != 2 || stats.Misses != 2 {
+		t.Errorf("stats = %+v, want 2 hits and 2 misses", stats)
+	}
+}
@@ -0,0 +1,381 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+// Erasure request states reported by ErasureRequest.Status.
+const (
+	erasurePending   = "pending"
+	erasureCompleted = "completed"
+	erasureRejected  = "rejected"
+)
+
+// erasureStatuses lists every value erasure_requests.status takes.
+var erasureStatuses = []string{erasurePending, erasureCompleted, erasureRejected}
+
+var (
+	// ErrErasureRequestPending indicates the user already has an erasure request awaiting review.
+	ErrErasureRequestPending = errors.New("an erasure request is already pending")
+	// ErrErasureRequestNotFound indicates no erasure request matched.
+	ErrErasureRequestNotFound = errors.New("erasure request not found")
+	// ErrErasureRequestReviewed indicates the request was already completed or rejected.
+	ErrErasureRequestReviewed = errors.New("erasure request has already been reviewed")
+	// ErrErasureNoteRequired indicates a rejection did not explain why.
+	ErrErasureNoteRequired = errors.New("a note explaining the rejection is required")
+)
+
+// DataExport is a machine-readable copy of the data held about a user, as one JSON array of
+// rows per table. Credentials such as password and token hashes are left out.
+type DataExport struct {
+	UserID      uuid.UUID
+	GeneratedAt time.Time
+	Tables      map[string]json.RawMessage
+}
+
+// ErasureRequest describes a user's request to have their account and personal data erased.
+type ErasureRequest struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Status      string
+	Reason      string
+	RequestedAt time.Time
+	DueAt       time.Time
+	ReviewedBy  uuid.NullUUID
+	ReviewNote  string
+	ReviewedAt  sql.NullTime
+	// Overdue reports a pending request past its deadline.
+	Overdue bool
+}
+
+// ExportData collects everything stored about the user, keyed by table name.
+func (s *Service) ExportData(ctx context.Context, userID uuid.UUID) (DataExport, error) {
+	export := DataExport{UserID: userID, Tables: map[string]json.RawMessage{}}
+
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		if _, err := q.GetUserByID(ctx, userID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		tables := []struct {
+			name   string
+			export func(context.Context, uuid.UUID) (json.RawMessage, error)
+		}{
+			{"users", q.ExportUser},
+			{"user_profiles", q.ExportUserProfile},
+			{"user_identities", q.ExportUserIdentities},
+			{"user_sessions", q.ExportUserSessions},
+			{"job_applications", q.ExportJobApplications},
+			{"application_events", q.ExportApplicationEvents},
+			{"erasure_requests", q.ExportErasureRequests},
+		}
+		for _, table := range tables {
+			rows, err := table.export(ctx, userID)
+			if err != nil {
+				return err
+			}
+			export.Tables[table.name] = rows
+		}
+
+		return s.recordAudit(ctx, q, auditEntry{
+			ActorID:    userID,
+			Action:     auditDataExported,
+			TargetType: auditTargetUser,
+			TargetID:   userID,
+		})
+	})
+	if err != nil {
+		return DataExport{}, err
+	}
+
+	export.GeneratedAt = s.now()
+	s.logger.Info().Str("event", "data_exported").Str("user_id", userID.String()).Msg("personal data exported")
+	return export, nil
+}
+
+// RequestErasure files a right-to-erasure request for staff to review before the configured
+// deadline. The account stays usable until the request is completed. Accounts with a password
+// must confirm it; passwordless accounts need a current session that signed in within
+// ReauthWindow.
+func (s *Service) RequestErasure(ctx context.Context, userID, currentSessionID uuid.UUID, currentPassword, reason string) (ErasureRequest, error) {
+	var (
+		record queries.ErasureRequest
+		email  string
+	)
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		user, err := q.GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		if user.PasswordHash != "" {
+			if err := verifyPassword(user.PasswordHash, currentPassword); err != nil {
+				return ErrInvalidCurrentPassword
+			}
+		} else if err := s.requireRecentSignIn(ctx, q, user.ID, currentSessionID); err != nil {
+			return err
+		}
+
+		latest, err := q.GetLatestErasureRequest(ctx, user.ID)
+		switch {
+		case err == nil && latest.Status == erasurePending:
+			return ErrErasureRequestPending
+		case err != nil && !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		reason = strings.TrimSpace(reason)
+		record, err = q.CreateErasureRequest(ctx, queries.CreateErasureRequestParams{
+			UserID: user.ID,
+			Reason: nullString(reason),
+			DueAt:  s.now().Add(s.config.ErasureDeadline),
+		})
+		if err != nil {
+			return err
+		}
+		email = user.Email
+
+		return s.recordAudit(ctx, q, auditEntry{
+			ActorID:    user.ID,
+			Action:     auditErasureRequested,
+			TargetType: auditTargetUser,
+			TargetID:   user.ID,
+			Metadata:   map[string]any{"erasure_request_id": record.ID.String()},
+		})
+	})
+	if err != nil {
+		return ErasureRequest{}, err
+	}
+
+	s.logger.Info().
+		Str("event", "erasure_requested").
+		Str("erasure_request_id", record.ID.String()).
+		Str("user_id", userID.String()).
+		Time("due_at", record.DueAt).
+		Msg("erasure requested")
+
+	s.sendErasureRequestedEmail(ctx, email, record.DueAt)
+	return s.toErasureRequest(record), nil
+}
+
+// LatestErasureRequest returns the user's most recent erasure request.
+func (s *Service) LatestErasureRequest(ctx context.Context, userID uuid.UUID) (ErasureRequest, error) {
+	record, err := s.store.Queries().GetLatestErasureRequest(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErasureRequest{}, ErrErasureRequestNotFound
+		}
+		return ErasureRequest{}, err
+	}
+	return s.toErasureRequest(record), nil
+}
+
+// ListErasureRequests returns erasure requests with the closest deadline first, optionally
+// limited to one status.
+func (s *Service) ListErasureRequests(ctx context.Context, status string) ([]ErasureRequest, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status != "" && !slices.Contains(erasureStatuses, status) {
+		return nil, ErrInvalidStatus
+	}
+
+	records, err := s.store.Queries().ListErasureRequests(ctx, nullString(status))
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make([]ErasureRequest, 0, len(records))
+	for _, record := range records {
+		requests = append(requests, s.toErasureRequest(record))
+	}
+	return requests, nil
+}
+
+// CompleteErasure approves a pending erasure request and erases the user. The users row and
+// everything that cascades from it (profile, sessions, identities, tokens, MFA) is deleted.
+// Applications are kept without an owner and with their free-text fields cleared so
+// per-job statistics stay accurate, and the status history of each application is kept with
+// its comments removed. The audit trail only refers to users by identifier and is left intact.
+func (s *Service) CompleteErasure(ctx context.Context, actor UserContext, id uuid.UUID, note string) (ErasureRequest, error) {
+	var (
+		record queries.ErasureRequest
+		email  string
+	)
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		var err error
+		record, err = s.reviewErasureRequest(ctx, q, actor, id, erasureCompleted, note)
+		if err != nil {
+			return err
+		}
+
+		// The account may already be gone, for example if it was deleted by other means
+		// after the request was filed; the request is still recorded as completed.
+		user, err := q.GetUserByID(ctx, record.UserID)
+		switch {
+		case err == nil:
+			email = user.Email
+			if err := s.eraseUser(ctx, q, user); err != nil {
+				return err
+			}
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		return s.recordAudit(ctx, q, auditEntry{
+			ActorID:    actor.ID,
+			Action:     auditErasureCompleted,
+			TargetType: auditTargetUser,
+			TargetID:   record.UserID,
+			Metadata:   map[string]any{"erasure_request_id": record.ID.String()},
+		})
+	})
+	if err != nil {
+		return ErasureRequest{}, err
+	}
+
+	s.invalidatePrincipal(ctx, record.UserID)
+	s.logger.Info().
+		Str("event", "user_erased").
+		Str("erasure_request_id", record.ID.String()).
+		Str("user_id", record.UserID.String()).
+		Str("actor_id", actor.ID.String()).
+		Msg("user erased")
+
+	if email != "" {
+		s.sendErasureCompletedEmail(ctx, email)
+	}
+	return s.toErasureRequest(record), nil
+}
+
+// RejectErasure declines a pending erasure request, for example when the data must be
+// retained for legal reasons. The note is sent to the user.
+func (s *Service) RejectErasure(ctx context.Context, actor UserContext, id uuid.UUID, note string) (ErasureRequest, error) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return ErasureRequest{}, ErrErasureNoteRequired
+	}
+
+	var (
+		record queries.ErasureRequest
+		email  string
+	)
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		var err error
+		record, err = s.reviewErasureRequest(ctx, q, actor, id, erasureRejected, note)
+		if err != nil {
+			return err
+		}
+
+		user, err := q.GetUserByID(ctx, record.UserID)
+		switch {
+		case err == nil:
+			email = user.Email
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		return s.recordAudit(ctx, q, auditEntry{
+			ActorID:    actor.ID,
+			Action:     auditErasureRejected,
+			TargetType: auditTargetUser,
+			TargetID:   record.UserID,
+			Metadata:   map[string]any{"erasure_request_id": record.ID.String(), "note": note},
+		})
+	})
+	if err != nil {
+		return ErasureRequest{}, err
+	}
+
+	s.logger.Info().
+		Str("event", "erasure_rejected").
+		Str("erasure_request_id", record.ID.String()).
+		Str("user_id", record.UserID.String()).
+		Str("actor_id", actor.ID.String()).
+		Msg("erasure request rejected")
+
+	if email != "" {
+		s.sendErasureRejectedEmail(ctx, email, note)
+	}
+	return s.toErasureRequest(record), nil
+}
+
+// reviewErasureRequest moves a pending request to its final status. Staff cannot review their
+// own request.
+func (s *Service) reviewErasureRequest(ctx context.Context, q *queries.Queries, actor UserContext, id uuid.UUID, status, note string) (queries.ErasureRequest, error) {
+	if actor.IsAPIKey() {
+		return queries.ErasureRequest{}, ErrHumanUserRequired
+	}
+
+	existing, err := q.GetErasureRequest(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return queries.ErasureRequest{}, ErrErasureRequestNotFound
+		}
+		return queries.ErasureRequest{}, err
+	}
+	if existing.UserID == actor.ID {
+		return queries.ErasureRequest{}, ErrSelfManagement
+	}
+
+	record, err := q.ReviewErasureRequest(ctx, queries.ReviewErasureRequestParams{
+		ID:         id,
+		Status:     status,
+		ReviewedBy: uuid.NullUUID{UUID: actor.ID, Valid: true},
+		ReviewNote: nullString(strings.TrimSpace(note)),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return queries.ErasureRequest{}, ErrErasureRequestReviewed
+		}
+		return queries.ErasureRequest{}, err
+	}
+	return record, nil
+}
+
+// eraseUser removes the user's personal data, see CompleteErasure.
+func (s *Service) eraseUser(ctx context.Context, q *queries.Queries, user queries.User) error {
+	owner := uuid.NullUUID{UUID: user.ID, Valid: true}
+	if err := q.RedactUserApplicationEvents(ctx, owner); err != nil {
+		return err
+	}
+	if err := q.AnonymiseUserApplications(ctx, owner); err != nil {
+		return err
+	}
+	if err := q.DeleteLoginThrottle(ctx, queries.DeleteLoginThrottleParams{
+		Scope: throttleScopeAccount,
+		Key:   user.Email,
+	}); err != nil {
+		return err
+	}
+	return q.DeleteUser(ctx, user.ID)
+}
+
+func (s *Service) toErasureRequest(record queries.ErasureRequest) ErasureRequest {
+	return ErasureRequest{
+		ID:          record.ID,
+		UserID:      record.UserID,
+		Status:      record.Status,
+		Reason:      record.Reason.String,
+		RequestedAt: record.RequestedAt,
+		DueAt:       record.DueAt,
+		ReviewedBy:  record.ReviewedBy,
+		ReviewNote:  record.ReviewNote.String,
+		ReviewedAt:  record.ReviewedAt,
+		Overdue:     record.Status == erasurePending && record.DueAt.Before(s.now()),
+	}
+}
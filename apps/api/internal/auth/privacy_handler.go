@@ -0,0 +1,255 @@
+package auth
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type requestErasureRequest struct {
+	CurrentPassword string `json:"current_password"`
+	Reason          string `json:"reason"`
+}
+
+type reviewErasureRequest struct {
+	Note string `json:"note"`
+}
+
+type erasureRequestResponse struct {
+	ID          string  `json:"id"`
+	UserID      string  `json:"user_id"`
+	Status      string  `json:"status"`
+	Reason      string  `json:"reason,omitempty"`
+	RequestedAt string  `json:"requested_at"`
+	DueAt       string  `json:"due_at"`
+	Overdue     bool    `json:"overdue"`
+	ReviewedBy  *string `json:"reviewed_by"`
+	ReviewNote  string  `json:"review_note,omitempty"`
+	ReviewedAt  *string `json:"reviewed_at"`
+}
+
+type dataExportManifest struct {
+	UserID      string   `json:"user_id"`
+	GeneratedAt string   `json:"generated_at"`
+	Tables      []string `json:"tables"`
+}
+
+func (h *Handler) handleDataExport(w http.ResponseWriter, r *http.Request) {
+	user, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	export, err := h.service.ExportData(r.Context(), user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to export data")
+		}
+		return
+	}
+
+	var archive bytes.Buffer
+	if err := writeDataExportArchive(&archive, export); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to export data")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="synergyvets-data-%s.zip"`, export.GeneratedAt.UTC().Format("20060102")))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(archive.Bytes())
+}
+
+// writeDataExportArchive writes a zip archive holding one <table>.json file per table and a
+// manifest.json describing the export.
+func writeDataExportArchive(w io.Writer, export DataExport) error {
+	archive := zip.NewWriter(w)
+	add := func(name string, data []byte) error {
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: export.GeneratedAt,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = entry.Write(data)
+		return err
+	}
+
+	tables := slices.Sorted(maps.Keys(export.Tables))
+	manifest, err := json.MarshalIndent(dataExportManifest{
+		UserID:      export.UserID.String(),
+		GeneratedAt: export.GeneratedAt.UTC().Format(time.RFC3339),
+		Tables:      tables,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := add("manifest.json", manifest); err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		var rows bytes.Buffer
+		if err := json.Indent(&rows, export.Tables[table], "", "  "); err != nil {
+			return err
+		}
+		if err := add(table+".json", rows.Bytes()); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func (h *Handler) handleGetErasureRequest(w http.ResponseWriter, r *http.Request) {
+	user, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	request, err := h.service.LatestErasureRequest(r.Context(), user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrErasureRequestNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to load erasure request")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toErasureRequestResponse(request))
+}
+
+func (h *Handler) handleRequestErasure(w http.ResponseWriter, r *http.Request) {
+	user, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req requestErasureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	request, err := h.service.RequestErasure(r.Context(), user.ID, user.SessionID, req.CurrentPassword, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidCurrentPassword), errors.Is(err, ErrReauthenticationRequired):
+			writeError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, ErrErasureRequestPending):
+			writeError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, ErrUserNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to request erasure")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, toErasureRequestResponse(request))
+}
+
+func (h *Handler) handleListErasureRequests(w http.ResponseWriter, r *http.Request) {
+	requests, err := h.service.ListErasureRequests(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidStatus):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to list erasure requests")
+		}
+		return
+	}
+
+	resp := make([]erasureRequestResponse, 0, len(requests))
+	for _, request := range requests {
+		resp = append(resp, toErasureRequestResponse(request))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"erasure_requests": resp})
+}
+
+func (h *Handler) handleCompleteErasure(w http.ResponseWriter, r *http.Request) {
+	h.reviewErasure(w, r, h.service.CompleteErasure, "failed to complete erasure")
+}
+
+func (h *Handler) handleRejectErasure(w http.ResponseWriter, r *http.Request) {
+	h.reviewErasure(w, r, h.service.RejectErasure, "failed to reject erasure request")
+}
+
+// reviewErasure runs a review decision on the erasure request named in the URL. The note is
+// optional when completing, so an empty body is accepted.
+func (h *Handler) reviewErasure(w http.ResponseWriter, r *http.Request, review func(context.Context, UserContext, uuid.UUID, string) (ErasureRequest, error), fallback string) {
+	actor, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	requestID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, ErrErasureRequestNotFound.Error())
+		return
+	}
+
+	var req reviewErasureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	request, err := review(r.Context(), actor, requestID, req.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrErasureRequestNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, ErrErasureNoteRequired):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrHumanUserRequired), errors.Is(err, ErrSelfManagement):
+			writeError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, ErrErasureRequestReviewed):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, fallback)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toErasureRequestResponse(request))
+}
+
+func toErasureRequestResponse(request ErasureRequest) erasureRequestResponse {
+	return erasureRequestResponse{
+		ID:          request.ID.String(),
+		UserID:      request.UserID.String(),
+		Status:      request.Status,
+		Reason:      request.Reason,
+		RequestedAt: request.RequestedAt.UTC().Format(time.RFC3339),
+		DueAt:       request.DueAt.UTC().Format(time.RFC3339),
+		Overdue:     request.Overdue,
+		ReviewedBy:  formatNullUUID(request.ReviewedBy),
+		ReviewNote:  request.ReviewNote,
+		ReviewedAt:  formatNullTime(request.ReviewedAt),
+	}
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRequestErasurePasswordlessNeedsRecentSignIn(t *testing.T) {
+	s, clock := newTestService(t, Config{ReauthWindow: 10 * time.Minute})
+	ctx := context.Background()
+	user := createTestUser(t, s, RoleSeeker)
+	signedIn, session := signInPasswordless(t, s, user)
+
+	clock.Advance(9 * time.Minute)
+	if _, err := s.Refresh(ctx, signedIn.RefreshToken, SessionMetadata{}); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	clock.Advance(2 * time.Minute)
+
+	if _, err := s.RequestErasure(ctx, user.ID, session.FamilyID, "", ""); !errors.Is(err, ErrReauthenticationRequired) {
+		t.Fatalf("stale session: got %v, want %v", err, ErrReauthenticationRequired)
+	}
+	if _, err := s.store.Queries().GetLatestErasureRequest(ctx, user.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("erasure request filed by a stale session: %v", err)
+	}
+}
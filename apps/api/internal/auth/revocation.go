@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/queries"
+	"github.com/synergyvets/platform/internal/store"
+)
+
+// ErrAccessTokenRevoked indicates the bearer token was revoked before it expired.
+var ErrAccessTokenRevoked = errors.New("access token revoked")
+
+// revocationCache answers access token revocation checks from memory. The denylisted jtis and
+// per-user cutoffs are reloaded from the database at most once per sync interval, and on the
+// next check after this instance revokes tokens, so requests do not pay a query each. Other
+// instances observe a revocation within one sync interval.
+type revocationCache struct {
+	store    *store.Store
+	interval time.Duration
+	// lookback is the access token lifetime; older revocations cannot affect a live token.
+	lookback time.Duration
+
+	syncMu sync.Mutex
+
+	mu       sync.RWMutex
+	tokens   map[uuid.UUID]time.Time
+	cutoffs  map[uuid.UUID]time.Time
+	syncedAt time.Time
+	stale    bool
+}
+
+func newRevocationCache(store *store.Store, interval, lookback time.Duration) *revocationCache {
+	return &revocationCache{
+		store:    store,
+		interval: interval,
+		lookback: lookback,
+		stale:    true,
+	}
+}
+
+// revoked reports whether a token for userID carrying claims has been revoked.
+func (c *revocationCache) revoked(ctx context.Context, userID uuid.UUID, claims tokenClaims, now time.Time) (bool, error) {
+	if err := c.sync(ctx, now); err != nil {
+		return false, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if cutoff, ok := c.cutoffs[userID]; ok {
+		// iat has one-second precision, so cutoffs are stored truncated and a token issued in
+		// the same second survives; tokens issued just before are caught by their jti instead.
+		if claims.IssuedAt == nil || claims.IssuedAt.Time.Before(cutoff) {
+			return true, nil
+		}
+	}
+
+	if jti, err := uuid.Parse(claims.ID); err == nil {
+		if _, ok := c.tokens[jti]; ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// markStale forces a reload on the next check. Call it after committing a revocation.
+func (c *revocationCache) markStale() {
+	c.mu.Lock()
+	c.stale = true
+	c.mu.Unlock()
+}
+
+func (c *revocationCache) fresh(now time.Time) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.stale && now.Sub(c.syncedAt) < c.interval
+}
+
+// sync reloads the cache when it is stale. A failed reload keeps serving the previous data
+// unless nothing has been loaded yet.
+func (c *revocationCache) sync(ctx context.Context, now time.Time) error {
+	if c.fresh(now) {
+		return nil
+	}
+
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	if c.fresh(now) {
+		return nil
+	}
+
+	c.mu.Lock()
+	// Revocations committed while the reload runs must trigger another one.
+	c.stale = false
+	c.mu.Unlock()
+
+	tokens, cutoffs, err := c.load(ctx, now)
+	if err != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.stale = true
+		if c.tokens == nil {
+			return err
+		}
+		return nil
+	}
+
+	c.mu.Lock()
+	c.tokens = tokens
+	c.cutoffs = cutoffs
+	c.syncedAt = now
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *revocationCache) load(ctx context.Context, now time.Time) (map[uuid.UUID]time.Time, map[uuid.UUID]time.Time, error) {
+	q := c.store.Queries()
+
+	revocations, err := q.ListActiveAccessTokenRevocations(ctx, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	tokens := make(map[uuid.UUID]time.Time, len(revocations))
+	for _, revocation := range revocations {
+		tokens[revocation.Jti] = revocation.ExpiresAt
+	}
+
+	rows, err := q.ListUserTokenCutoffs(ctx, sql.NullTime{Time: now.Add(-c.lookback), Valid: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	cutoffs := make(map[uuid.UUID]time.Time, len(rows))
+	for _, row := range rows {
+		cutoffs[row.ID] = row.TokensValidAfter.Time
+	}
+
+	return tokens, cutoffs, nil
+}
+
+// The revoke helpers below run inside the transaction that deletes the sessions, before the
+// delete, since the jtis are read from the session rows. Callers mark the cache stale once the
+// transaction commits.
+
+// revokeFamilyAccessTokens denylists the access tokens issued for a session family.
+func (s *Service) revokeFamilyAccessTokens(ctx context.Context, q *queries.Queries, familyID uuid.UUID) error {
+	now := s.now()
+	if err := q.RevokeFamilyAccessTokens(ctx, queries.RevokeFamilyAccessTokensParams{
+		FamilyID:    familyID,
+		ExpiresAt:   now.Add(s.config.AccessTokenTTL),
+		IssuedAfter: now.Add(-s.config.AccessTokenTTL),
+	}); err != nil {
+		return err
+	}
+	return q.DeleteExpiredAccessTokenRevocations(ctx, now)
+}
+
+// revokeOtherAccessTokens denylists the access tokens of every session of the user except one.
+func (s *Service) revokeOtherAccessTokens(ctx context.Context, q *queries.Queries, userID, keepFamilyID uuid.UUID) error {
+	now := s.now()
+	if err := q.RevokeOtherFamilyAccessTokens(ctx, queries.RevokeOtherFamilyAccessTokensParams{
+		UserID:       userID,
+		KeepFamilyID: keepFamilyID,
+		ExpiresAt:    now.Add(s.config.AccessTokenTTL),
+		IssuedAfter:  now.Add(-s.config.AccessTokenTTL),
+	}); err != nil {
+		return err
+	}
+	return q.DeleteExpiredAccessTokenRevocations(ctx, now)
+}
+
+// revokeAllAccessTokens invalidates every access token issued to the user so far.
+func (s *Service) revokeAllAccessTokens(ctx context.Context, q *queries.Queries, userID uuid.UUID) error {
+	now := s.now()
+	if err := q.RevokeUserAccessTokens(ctx, queries.RevokeUserAccessTokensParams{
+		UserID:      userID,
+		ExpiresAt:   now.Add(s.config.AccessTokenTTL),
+		IssuedAfter: now.Add(-s.config.AccessTokenTTL),
+	}); err != nil {
+		return err
+	}
+	if err := q.SetUserTokensValidAfter(ctx, queries.SetUserTokensValidAfterParams{
+		ID:         userID,
+		ValidAfter: sql.NullTime{Time: now.Truncate(time.Second), Valid: true},
+	}); err != nil {
+		return err
+	}
+	return q.DeleteExpiredAccessTokenRevocations(ctx, now)
+}
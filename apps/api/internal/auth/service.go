@@ -6,12 +6,15 @@ import (
 	"errors"
 	"net"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"github.com/sqlc-dev/pqtype"
 
+	"github.com/synergyvets/platform/internal/mailer"
+	"github.com/synergyvets/platform/internal/oidc"
 	"github.com/synergyvets/platform/internal/queries"
 	"github.com/synergyvets/platform/internal/store"
 )
@@ -21,16 +24,62 @@ type Service struct {
 	store  *store.Store
 	logger zerolog.Logger
 	config Config
+	keys   *Keyring
+	mailer mailer.Mailer
 	now    func() time.Time
+	// identityProviders are the OIDC providers available for social sign-in, keyed by name.
+	identityProviders map[string]*oidc.Provider
+	revocations       *revocationCache
+	principals        PrincipalCache
+	principalHits     atomic.Uint64
+	principalMisses   atomic.Uint64
 }
 
 // Config defines expiry and secret settings for token generation.
 type Config struct {
-	Secret          string
-	AccessTokenTTL  time.Duration
-	RefreshTokenTTL time.Duration
+	Secret string
+	// Keyring signs and verifies access tokens; when nil an HS256 keyring is built from Secret.
+	Keyring               *Keyring
+	AccessTokenTTL        time.Duration
+	RefreshTokenTTL       time.Duration
+	VerificationTokenTTL  time.Duration
+	PasswordResetTokenTTL time.Duration
+	MagicLinkTTL          time.Duration
+	InvitationTTL         time.Duration
+	// ImpersonationTTL bounds how long an administrator can act as a candidate per session.
+	ImpersonationTTL time.Duration
+	// ErasureDeadline is how long staff have to answer a right-to-erasure request.
+	ErasureDeadline time.Duration
+	// MagicLinkHourlyLimit caps login links emailed per account per hour.
+	MagicLinkHourlyLimit int
+	// AppURL is the public web origin used to build links in outbound email.
+	AppURL   string
+	Throttle ThrottleConfig
+	// Password sets the argon2id parameters for new hashes; outdated hashes are upgraded on login.
+	Password PasswordParams
+	// MFAIssuer labels the account in authenticator apps.
+	MFAIssuer string
+	// MFAChallengeTTL bounds the gap between the password and second-factor steps of login.
+	MFAChallengeTTL time.Duration
+	// RequireStaffMFA denies staff permissions to sessions that have not completed TOTP.
+	RequireStaffMFA bool
+	// PrincipalCacheSize caps the users whose status and role are cached in process.
+	PrincipalCacheSize int
+	// PrincipalCacheTTL bounds how long a cached status or role may be served.
+	PrincipalCacheTTL time.Duration
+	// ReauthWindow is how recently a passwordless session must have signed in to change the
+	// account's email address.
+	ReauthWindow time.Duration
+	// RevocationSyncInterval bounds how stale the in-memory access token revocation list may
+	// get before it is reloaded, i.e. how long other instances keep accepting a revoked token.
+	RevocationSyncInterval time.Duration
 }
 
+const (
+	statusActive              = "active"
+	statusPendingVerification = "pending_verification"
+)
+
 // RegisterInput holds the incoming payload for creating a user.
 type RegisterInput struct {
 	Email    string
@@ -51,12 +100,16 @@ type SessionMetadata struct {
 	IP        string
 }
 
-// AuthResult includes issued tokens along with user identity details.
+// AuthResult includes issued tokens along with user identity details. When MFAChallenge is set
+// no tokens were issued and the login must be completed with CompleteMFALogin.
 type AuthResult struct {
 	User               queries.User
 	AccessToken        string
 	RefreshToken       string
 	RefreshTokenExpiry time.Time
+	MFAChallenge       *MFAChallenge
+	// MFAEnrollmentRequired flags staff who must enroll a second factor before using staff routes.
+	MFAEnrollmentRequired bool
 }
 
 var (
@@ -72,14 +125,28 @@ var (
 	ErrInvalidRefreshToken = errors.New("invalid refresh token")
 	// ErrExpiredRefreshToken indicates the refresh token is no longer valid.
 	ErrExpiredRefreshToken = errors.New("refresh token expired")
+	// ErrRefreshTokenReused indicates an already-rotated refresh token was replayed.
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
 	// ErrInvalidAccessToken denotes problems validating the bearer token.
 	ErrInvalidAccessToken = errors.New("invalid access token")
 	// ErrAccessTokenExpired indicates the bearer token has expired.
 	ErrAccessTokenExpired = errors.New("access token expired")
 	// ErrInactiveAccount represents a user that is not active.
 	ErrInactiveAccount = errors.New("user is inactive")
+	// ErrUserNotFound indicates no user matched the provided identifier.
+	ErrUserNotFound = errors.New("user not found")
 	// ErrForbidden signals the user lacks the required permissions.
 	ErrForbidden = errors.New("insufficient privileges")
+	// ErrEmailNotVerified indicates the user has not confirmed their email address yet.
+	ErrEmailNotVerified = errors.New("email address not verified")
+	// ErrInvalidVerificationToken signals the verification token could not be validated.
+	ErrInvalidVerificationToken = errors.New("invalid verification token")
+	// ErrExpiredVerificationToken indicates the verification token is no longer valid.
+	ErrExpiredVerificationToken = errors.New("verification token expired")
+	// ErrInvalidResetToken signals the password reset token could not be validated.
+	ErrInvalidResetToken = errors.New("invalid password reset token")
+	// ErrExpiredResetToken indicates the password reset token is no longer valid.
+	ErrExpiredResetToken = errors.New("password reset token expired")
 )
 
 // NewService constructs an auth Service with sane defaults.
@@ -88,15 +155,59 @@ func NewService(store *store.Store, logger zerolog.Logger, cfg Config) *Service
 		store:  store,
 		logger: logger,
 		config: cfg,
+		keys:   cfg.Keyring,
+		mailer: mailer.NewLogMailer(logger),
 		now:    time.Now,
 	}
 
+	if service.keys == nil {
+		service.keys = NewHMACKeyring(cfg.Secret)
+	}
+
 	if service.config.AccessTokenTTL <= 0 {
 		service.config.AccessTokenTTL = 15 * time.Minute
 	}
 	if service.config.RefreshTokenTTL <= 0 {
 		service.config.RefreshTokenTTL = 720 * time.Hour // 30 days
 	}
+	if service.config.VerificationTokenTTL <= 0 {
+		service.config.VerificationTokenTTL = 48 * time.Hour
+	}
+	if service.config.PasswordResetTokenTTL <= 0 {
+		service.config.PasswordResetTokenTTL = time.Hour
+	}
+	if service.config.MagicLinkTTL <= 0 {
+		service.config.MagicLinkTTL = 15 * time.Minute
+	}
+	if service.config.InvitationTTL <= 0 {
+		service.config.InvitationTTL = 168 * time.Hour // 7 days
+	}
+	if service.config.ImpersonationTTL <= 0 {
+		service.config.ImpersonationTTL = 15 * time.Minute
+	}
+	if service.config.ErasureDeadline <= 0 {
+		service.config.ErasureDeadline = 30 * 24 * time.Hour
+	}
+	if service.config.MagicLinkHourlyLimit <= 0 {
+		service.config.MagicLinkHourlyLimit = 5
+	}
+	service.config.AppURL = strings.TrimRight(service.config.AppURL, "/")
+	service.config.Throttle = service.config.Throttle.withDefaults()
+	service.config.Password = service.config.Password.withDefaults()
+	if service.config.MFAIssuer == "" {
+		service.config.MFAIssuer = "Synergy Vets"
+	}
+	if service.config.MFAChallengeTTL <= 0 {
+		service.config.MFAChallengeTTL = 5 * time.Minute
+	}
+	if service.config.ReauthWindow <= 0 {
+		service.config.ReauthWindow = 10 * time.Minute
+	}
+	if service.config.RevocationSyncInterval <= 0 {
+		service.config.RevocationSyncInterval = 5 * time.Second
+	}
+	service.principals = NewMemoryPrincipalCache(service.config.PrincipalCacheSize, service.config.PrincipalCacheTTL)
+	service.revocations = newRevocationCache(store, service.config.RevocationSyncInterval, service.config.AccessTokenTTL)
 
 	return service
 }
@@ -109,27 +220,41 @@ func (s *Service) WithNow(now func() time.Time) *Service {
 	return s
 }
 
-// Register creates a new user and issues tokens.
+// JWKS returns the public keys used to verify access tokens.
+func (s *Service) JWKS() JSONWebKeySet {
+	return s.keys.JWKS()
+}
+
+// WithMailer overrides the mailer used for transactional email.
+func (s *Service) WithMailer(m mailer.Mailer) *Service {
+	if m != nil {
+		s.mailer = m
+	}
+	return s
+}
+
+// Register creates a new user pending email verification and issues tokens.
 func (s *Service) Register(ctx context.Context, input RegisterInput, meta SessionMetadata) (AuthResult, error) {
 	result := AuthResult{}
 
-	email := strings.TrimSpace(strings.ToLower(input.Email))
-	password := strings.TrimSpace(input.Password)
-	if email == "" {
-		return result, ErrInvalidEmail
+	email, err := normalizeEmail(input.Email)
+	if err != nil {
+		return result, err
 	}
-	if len(password) < 8 {
-		return result, ErrWeakPassword
+	password, err := normalizePassword(input.Password)
+	if err != nil {
+		return result, err
 	}
 
-	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+	var verificationToken string
+	err = s.store.WithTx(ctx, func(q *queries.Queries) error {
 		if _, err := q.GetUserByEmail(ctx, email); err == nil {
 			return ErrEmailInUse
 		} else if !errors.Is(err, sql.ErrNoRows) {
 			return err
 		}
 
-		hash, err := hashPassword(password)
+		hash, err := hashPassword(password, s.config.Password)
 		if err != nil {
 			return err
 		}
@@ -137,12 +262,18 @@ func (s *Service) Register(ctx context.Context, input RegisterInput, meta Sessio
 		user, err := q.CreateUser(ctx, queries.CreateUserParams{
 			Email:        email,
 			PasswordHash: hash,
+			Status:       sql.NullString{String: statusPendingVerification, Valid: true},
 		})
 		if err != nil {
 			return err
 		}
 
-		ar, err := s.issueTokens(ctx, q, user, meta)
+		verificationToken, err = s.createVerificationToken(ctx, q, user)
+		if err != nil {
+			return err
+		}
+
+		ar, err := s.issueTokens(ctx, q, user, meta, []string{amrPassword})
 		if err != nil {
 			return err
 		}
@@ -153,10 +284,31 @@ func (s *Service) Register(ctx context.Context, input RegisterInput, meta Sessio
 		return result, err
 	}
 
+	s.sendVerificationEmail(ctx, result.User.Email, verificationToken)
 	return result, nil
 }
 
-// Login authenticates a user and issues fresh tokens.
+// normalizeEmail applies the email rules shared by registration and email changes.
+func normalizeEmail(email string) (string, error) {
+	normalized := strings.TrimSpace(strings.ToLower(email))
+	if normalized == "" {
+		return "", ErrInvalidEmail
+	}
+	return normalized, nil
+}
+
+// normalizePassword applies the password rules shared by registration, resets and changes.
+func normalizePassword(password string) (string, error) {
+	trimmed := strings.TrimSpace(password)
+	if len(trimmed) < 8 {
+		return "", ErrWeakPassword
+	}
+	return trimmed, nil
+}
+
+// Login authenticates a user and issues fresh tokens, or an MFAChallenge when the user has a
+// second factor. Repeated failures lock the account and client IP with exponential backoff
+// before any password hashing is attempted.
 func (s *Service) Login(ctx context.Context, input LoginInput) (AuthResult, error) {
 	result := AuthResult{}
 
@@ -165,6 +317,11 @@ func (s *Service) Login(ctx context.Context, input LoginInput) (AuthResult, erro
 		return result, ErrInvalidCredentials
 	}
 
+	now := s.now()
+	if err := s.checkLoginThrottle(ctx, email, input.IP, now); err != nil {
+		return result, err
+	}
+
 	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
 		user, err := q.GetUserByEmail(ctx, email)
 		if err != nil {
@@ -178,27 +335,95 @@ func (s *Service) Login(ctx context.Context, input LoginInput) (AuthResult, erro
 			return ErrInvalidCredentials
 		}
 
+		switch strings.ToLower(user.Status) {
+		case statusActive, statusPendingVerification:
+		default:
+			return ErrInactiveAccount
+		}
+
+		if needsRehash(user.PasswordHash, s.config.Password) {
+			if err := s.rehashPassword(ctx, q, user, input.Password); err != nil {
+				return err
+			}
+		}
+
 		meta := SessionMetadata{UserAgent: input.UserAgent, IP: input.IP}
-		ar, err := s.issueTokens(ctx, q, user, meta)
+		ar, err := s.completeLogin(ctx, q, user, meta, []string{amrPassword})
 		if err != nil {
 			return err
 		}
 
-		if err := q.UpdateUserLastLogin(ctx, user.ID); err != nil {
-			s.logger.Warn().Err(err).Str("user_id", user.ID.String()).Msg("failed to update last login")
+		if err := q.DeleteLoginThrottle(ctx, queries.DeleteLoginThrottleParams{
+			Scope: throttleScopeAccount,
+			Key:   email,
+		}); err != nil {
+			return err
 		}
 
 		result = ar
 		return nil
 	})
 	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			if recErr := s.recordLoginFailure(ctx, email, input.IP, now); recErr != nil {
+				s.logger.Warn().Err(recErr).Msg("failed to record login failure")
+			}
+		}
 		return result, err
 	}
 
 	return result, nil
 }
 
+// completeLogin finishes a primary authentication made with the methods in amr: users with a
+// second factor receive an MFAChallenge, everyone else a new session.
+func (s *Service) completeLogin(ctx context.Context, q *queries.Queries, user queries.User, meta SessionMetadata, amr []string) (AuthResult, error) {
+	hasMFA, err := confirmedMFA(ctx, q, user.ID)
+	if err != nil {
+		return AuthResult{}, err
+	}
+	if hasMFA {
+		challenge, err := s.createMFAChallenge(ctx, q, user.ID, amr)
+		if err != nil {
+			return AuthResult{}, err
+		}
+
+		user.PasswordHash = ""
+		return AuthResult{User: user, MFAChallenge: challenge}, nil
+	}
+
+	ar, err := s.issueTokens(ctx, q, user, meta, amr)
+	if err != nil {
+		return AuthResult{}, err
+	}
+
+	if err := q.UpdateUserLastLogin(ctx, user.ID); err != nil {
+		s.logger.Warn().Err(err).Str("user_id", user.ID.String()).Msg("failed to update last login")
+	}
+	return ar, nil
+}
+
+// rehashPassword replaces an outdated or legacy hash after the plaintext has been verified.
+func (s *Service) rehashPassword(ctx context.Context, q *queries.Queries, user queries.User, password string) error {
+	hash, err := hashPassword(password, s.config.Password)
+	if err != nil {
+		s.logger.Warn().Err(err).Str("user_id", user.ID.String()).Msg("failed to rehash password")
+		return nil
+	}
+
+	if err := q.UpdateUserPassword(ctx, queries.UpdateUserPasswordParams{
+		ID:           user.ID,
+		PasswordHash: hash,
+	}); err != nil {
+		return err
+	}
+
+	s.logger.Info().Str("user_id", user.ID.String()).Msg("password hash upgraded")
+	return nil
+}
+
 // Refresh exchanges a valid refresh token for new access and refresh tokens.
+// Presenting a token that was already rotated revokes every session in its family.
 func (s *Service) Refresh(ctx context.Context, refreshToken string, meta SessionMetadata) (AuthResult, error) {
 	result := AuthResult{}
 	token := strings.TrimSpace(refreshToken)
@@ -208,6 +433,7 @@ func (s *Service) Refresh(ctx context.Context, refreshToken string, meta Session
 
 	hashed := hashRefreshToken(token)
 
+	var reused *queries.UserSession
 	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
 		session, err := q.GetUserSessionByHash(ctx, hashed)
 		if err != nil {
@@ -217,6 +443,14 @@ func (s *Service) Refresh(ctx context.Context, refreshToken string, meta Session
 			return err
 		}
 
+		if session.ConsumedAt.Valid {
+			reused = &session
+			if err := s.revokeFamilyAccessTokens(ctx, q, session.FamilyID); err != nil {
+				return err
+			}
+			return q.DeleteUserSessionsByFamilyID(ctx, session.FamilyID)
+		}
+
 		now := s.now()
 		if session.ExpiresAt.Before(now) {
 			if delErr := q.DeleteUserSession(ctx, session.ID); delErr != nil {
@@ -236,11 +470,28 @@ func (s *Service) Refresh(ctx context.Context, refreshToken string, meta Session
 			return err
 		}
 
-		if err := q.DeleteUserSession(ctx, session.ID); err != nil {
+		consumed, err := q.ConsumeUserSession(ctx, session.ID)
+		if err != nil {
+			return err
+		}
+		if consumed == 0 {
+			reused = &session
+			if err := s.revokeFamilyAccessTokens(ctx, q, session.FamilyID); err != nil {
+				return err
+			}
+			return q.DeleteUserSessionsByFamilyID(ctx, session.FamilyID)
+		}
+
+		// Rows consumed before their token expired still catch replays; older ones are pruned
+		// here so long-lived families do not grow without bound.
+		if err := q.DeleteExpiredConsumedUserSessions(ctx, queries.DeleteExpiredConsumedUserSessionsParams{
+			FamilyID: session.FamilyID,
+			Now:      now,
+		}); err != nil {
 			return err
 		}
 
-		ar, err := s.issueTokens(ctx, q, user, meta)
+		ar, err := s.issueTokensInFamily(ctx, q, user, meta, session.FamilyID, session.Amr, session.SignedInAt)
 		if err != nil {
 			return err
 		}
@@ -252,10 +503,24 @@ func (s *Service) Refresh(ctx context.Context, refreshToken string, meta Session
 		return result, err
 	}
 
+	if reused != nil {
+		s.revocations.markStale()
+		s.logger.Warn().
+			Str("event", "refresh_token_reuse").
+			Str("user_id", reused.UserID.String()).
+			Str("family_id", reused.FamilyID.String()).
+			Str("session_id", reused.ID.String()).
+			Str("ip", meta.IP).
+			Str("user_agent", meta.UserAgent).
+			Msg("refresh token reuse detected, session family revoked")
+		return result, ErrRefreshTokenReused
+	}
+
 	return result, nil
 }
 
-// Logout revokes the session tied to the provided refresh token.
+// Logout revokes the session family tied to the provided refresh token, along with the access
+// tokens issued for it.
 func (s *Service) Logout(ctx context.Context, refreshToken string) error {
 	token := strings.TrimSpace(refreshToken)
 	if token == "" {
@@ -264,7 +529,7 @@ func (s *Service) Logout(ctx context.Context, refreshToken string) error {
 
 	hashed := hashRefreshToken(token)
 
-	return s.store.WithTx(ctx, func(q *queries.Queries) error {
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
 		session, err := q.GetUserSessionByHash(ctx, hashed)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
@@ -273,55 +538,103 @@ func (s *Service) Logout(ctx context.Context, refreshToken string) error {
 			return err
 		}
 
-		return q.DeleteUserSession(ctx, session.ID)
+		if err := s.revokeFamilyAccessTokens(ctx, q, session.FamilyID); err != nil {
+			return err
+		}
+		return q.DeleteUserSessionsByFamilyID(ctx, session.FamilyID)
 	})
+	if err != nil {
+		return err
+	}
+
+	s.revocations.markStale()
+	return nil
+}
+
+// ValidateAccessToken parses and validates a bearer token, returning the associated principal.
+// The user's status and role come from the principal cache when possible.
+func (s *Service) ValidateAccessToken(ctx context.Context, token string) (Principal, error) {
+	principal, _, err := s.validateAccessToken(ctx, token)
+	return principal, err
 }
 
-// ValidateAccessToken parses and validates a bearer token, returning the associated user.
-func (s *Service) ValidateAccessToken(ctx context.Context, token string) (queries.User, error) {
-	result := queries.User{}
+func (s *Service) validateAccessToken(ctx context.Context, token string) (Principal, tokenClaims, error) {
+	result := Principal{}
 	bearer := strings.TrimSpace(token)
 	if bearer == "" {
-		return result, ErrInvalidAccessToken
+		return result, tokenClaims{}, ErrInvalidAccessToken
 	}
 
-	claims, err := parseAccessToken(s.config.Secret, bearer)
+	claims, err := parseAccessToken(s.keys, bearer)
 	if err != nil {
 		if errors.Is(err, errMissingSecret) {
-			return result, err
+			return result, claims, err
 		}
-		return result, ErrInvalidAccessToken
+		return result, claims, ErrInvalidAccessToken
 	}
 
 	if claims.ExpiresAt != nil && claims.ExpiresAt.Time.Before(s.now()) {
-		return result, ErrAccessTokenExpired
+		return result, claims, ErrAccessTokenExpired
 	}
 
 	userID, err := uuid.Parse(claims.Subject)
 	if err != nil {
-		return result, ErrInvalidAccessToken
+		return result, claims, ErrInvalidAccessToken
 	}
 
-	user, err := s.store.Queries().GetUserByID(ctx, userID)
+	revoked, err := s.revocations.revoked(ctx, userID, claims, s.now())
+	if err != nil {
+		return result, claims, err
+	}
+	if revoked {
+		return result, claims, ErrAccessTokenRevoked
+	}
+
+	principal, err := s.loadPrincipal(ctx, userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return result, ErrInvalidAccessToken
+			return result, claims, ErrInvalidAccessToken
 		}
-		return result, err
+		return result, claims, err
+	}
+
+	switch strings.ToLower(principal.Status) {
+	case statusActive:
+	case statusPendingVerification:
+		return result, claims, ErrEmailNotVerified
+	default:
+		return result, claims, ErrInactiveAccount
 	}
 
-	if strings.ToLower(user.Status) != "active" {
-		return result, ErrInactiveAccount
+	if claims.Actor != nil {
+		if err := s.checkImpersonation(ctx, principal, claims.Actor); err != nil {
+			return result, claims, err
+		}
 	}
 
-	user.PasswordHash = ""
-	return user, nil
+	return principal, claims, nil
+}
+
+// issueTokens starts a new refresh token family for the user, recording the authentication
+// methods the sign-in used.
+func (s *Service) issueTokens(ctx context.Context, q *queries.Queries, user queries.User, meta SessionMetadata, amr []string) (AuthResult, error) {
+	return s.issueTokensInFamily(ctx, q, user, meta, uuid.New(), amr, s.now())
 }
 
-func (s *Service) issueTokens(ctx context.Context, q *queries.Queries, user queries.User, meta SessionMetadata) (AuthResult, error) {
+// issueTokensInFamily issues tokens whose refresh session belongs to the given rotation family.
+// amr and signedInAt are the family's, never the user's current factors: a session signed in
+// before the user enrolled a second factor must not gain MFA credit by refreshing, nor count as
+// a recent sign-in.
+func (s *Service) issueTokensInFamily(ctx context.Context, q *queries.Queries, user queries.User, meta SessionMetadata, familyID uuid.UUID, amr []string, signedInAt time.Time) (AuthResult, error) {
 	now := s.now()
 
-	accessToken, err := generateAccessToken(s.config.Secret, user.ID, user.Role, s.config.AccessTokenTTL, now)
+	hasMFA, err := confirmedMFA(ctx, q, user.ID)
+	if err != nil {
+		return AuthResult{}, err
+	}
+
+	jti := uuid.New()
+	accessToken, err := generateAccessToken(s.keys, user.ID, user.Role, familyID, jti, amr, s.config.AccessTokenTTL, now)
 	if err != nil {
 		return AuthResult{}, err
 	}
@@ -336,6 +649,10 @@ func (s *Service) issueTokens(ctx context.Context, q *queries.Queries, user quer
 		RefreshTokenHash: hashedRefresh,
 		UserAgent:        sql.NullString{String: meta.UserAgent, Valid: strings.TrimSpace(meta.UserAgent) != ""},
 		ExpiresAt:        expiresAt,
+		FamilyID:         familyID,
+		AccessTokenJti:   uuid.NullUUID{UUID: jti, Valid: true},
+		Amr:              amr,
+		SignedInAt:       signedInAt,
 	}
 
 	if inet, ok := parseIP(meta.IP); ok {
@@ -350,10 +667,11 @@ func (s *Service) issueTokens(ctx context.Context, q *queries.Queries, user quer
 	safeUser.PasswordHash = ""
 
 	return AuthResult{
-		User:               safeUser,
-		AccessToken:        accessToken,
-		RefreshToken:       refreshToken,
-		RefreshTokenExpiry: expiresAt,
+		User:                  safeUser,
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiry:    expiresAt,
+		MFAEnrollmentRequired: s.mfaRequiredForRole(user.Role) && !hasMFA,
 	}, nil
 }
 
@@ -370,9 +688,25 @@ func parseIP(raw string) (pqtype.Inet, bool) {
 	return pqtype.Inet{IPNet: net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, Valid: true}, true
 }
 
-// RevokeAllSessions removes all refresh tokens for a user.
+// RevokeAllSessions removes all refresh tokens for a user and invalidates every access token
+// issued to them so far.
 func (s *Service) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
-	return s.store.WithTx(ctx, func(q *queries.Queries) error {
-		return q.DeleteUserSessionsByUserID(ctx, userID)
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		return s.revokeAllSessions(ctx, q, userID)
 	})
+	if err != nil {
+		return err
+	}
+
+	s.revocations.markStale()
+	return nil
+}
+
+// revokeAllSessions is RevokeAllSessions within the caller's transaction. The caller marks the
+// revocation cache stale once it commits.
+func (s *Service) revokeAllSessions(ctx context.Context, q *queries.Queries, userID uuid.UUID) error {
+	if err := s.revokeAllAccessTokens(ctx, q, userID); err != nil {
+		return err
+	}
+	return q.DeleteUserSessionsByUserID(ctx, userID)
 }
@@ -0,0 +1,282 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+func TestRefreshRotatesWithinFamily(t *testing.T) {
+	s, clock := newTestService(t, Config{})
+	ctx := context.Background()
+	user := createTestUser(t, s, RoleSeeker)
+
+	first := login(t, s, user)
+	firstSession, err := s.store.Queries().GetUserSessionByHash(ctx, hashRefreshToken(first.RefreshToken))
+	if err != nil {
+		t.Fatalf("load first session: %v", err)
+	}
+
+	clock.Advance(time.Minute)
+	second, err := s.Refresh(ctx, first.RefreshToken, SessionMetadata{})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh returned the same refresh token")
+	}
+	if !second.RefreshTokenExpiry.After(first.RefreshTokenExpiry) {
+		t.Errorf("expiry %v not extended past %v", second.RefreshTokenExpiry, first.RefreshTokenExpiry)
+	}
+
+	secondSession, err := s.store.Queries().GetUserSessionByHash(ctx, hashRefreshToken(second.RefreshToken))
+	if err != nil {
+		t.Fatalf("load second session: %v", err)
+	}
+	if secondSession.FamilyID != firstSession.FamilyID {
+		t.Errorf("family changed on rotation: %s -> %s", firstSession.FamilyID, secondSession.FamilyID)
+	}
+
+	consumed, err := s.store.Queries().GetUserSessionByHash(ctx, hashRefreshToken(first.RefreshToken))
+	if err != nil {
+		t.Fatalf("reload first session: %v", err)
+	}
+	if !consumed.ConsumedAt.Valid {
+		t.Error("rotated session not marked consumed")
+	}
+
+	if _, err := s.ValidateAccessToken(ctx, second.AccessToken); err != nil {
+		t.Errorf("rotated access token rejected: %v", err)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	s, clock := newTestService(t, Config{})
+	ctx := context.Background()
+	user := createTestUser(t, s, RoleSeeker)
+
+	stolen := login(t, s, user)
+	other := login(t, s, user)
+
+	clock.Advance(time.Minute)
+	current, err := s.Refresh(ctx, stolen.RefreshToken, SessionMetadata{})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	// Replaying the rotated token is treated as theft of the whole family.
+	clock.Advance(time.Minute)
+	if _, err := s.Refresh(ctx, stolen.RefreshToken, SessionMetadata{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("replayed refresh: got %v, want %v", err, ErrRefreshTokenReused)
+	}
+
+	tests := []struct {
+		name    string
+		check   func() error
+		wantErr error
+	}{
+		{
+			name: "latest refresh token of the family",
+			check: func() error {
+				_, err := s.Refresh(ctx, current.RefreshToken, SessionMetadata{})
+				return err
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "access token of the family",
+			check: func() error {
+				_, err := s.ValidateAccessToken(ctx, current.AccessToken)
+				return err
+			},
+			wantErr: ErrAccessTokenRevoked,
+		},
+		{
+			name: "access token of another session",
+			check: func() error {
+				_, err := s.ValidateAccessToken(ctx, other.AccessToken)
+				return err
+			},
+		},
+		{
+			name: "refresh token of another session",
+			check: func() error {
+				_, err := s.Refresh(ctx, other.RefreshToken, SessionMetadata{})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.check()
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRefreshExpiry(t *testing.T) {
+	const ttl = time.Hour
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		wantErr error
+	}{
+		{name: "before expiry", advance: ttl - time.Second},
+		{name: "after expiry", advance: ttl + time.Second, wantErr: ErrExpiredRefreshToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, clock := newTestService(t, Config{RefreshTokenTTL: ttl})
+			user := createTestUser(t, s, RoleSeeker)
+			result := login(t, s, user)
+
+			clock.Advance(tt.advance)
+			_, err := s.Refresh(context.Background(), result.RefreshToken, SessionMetadata{})
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRefreshPrunesExpiredConsumedSessions(t *testing.T) {
+	const ttl = time.Hour
+	s, clock := newTestService(t, Config{RefreshTokenTTL: ttl})
+	ctx := context.Background()
+	user := createTestUser(t, s, RoleSeeker)
+
+	first := login(t, s, user)
+	firstSession, err := s.store.Queries().GetUserSessionByHash(ctx, hashRefreshToken(first.RefreshToken))
+	if err != nil {
+		t.Fatalf("load first session: %v", err)
+	}
+
+	clock.Advance(time.Minute)
+	second, err := s.Refresh(ctx, first.RefreshToken, SessionMetadata{})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	// The first token has expired but the second has not.
+	clock.Advance(ttl - time.Second)
+	third, err := s.Refresh(ctx, second.RefreshToken, SessionMetadata{})
+	if err != nil {
+		t.Fatalf("second refresh: %v", err)
+	}
+
+	if _, err := s.store.Queries().GetUserSessionByHash(ctx, hashRefreshToken(first.RefreshToken)); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expired consumed session: got %v, want it pruned", err)
+	}
+	kept, err := s.store.Queries().GetUserSessionByHash(ctx, hashRefreshToken(second.RefreshToken))
+	if err != nil {
+		t.Fatalf("consumed session within its lifetime was pruned: %v", err)
+	}
+	if !kept.ConsumedAt.Valid {
+		t.Error("second session not marked consumed")
+	}
+
+	// Pruning the family's first row must not move its sign-in time.
+	current, err := s.store.Queries().GetUserSessionByHash(ctx, hashRefreshToken(third.RefreshToken))
+	if err != nil {
+		t.Fatalf("load current session: %v", err)
+	}
+	if !current.SignedInAt.Equal(firstSession.SignedInAt) {
+		t.Errorf("signed in at %v, want the family's %v", current.SignedInAt, firstSession.SignedInAt)
+	}
+
+	if _, err := s.Refresh(ctx, second.RefreshToken, SessionMetadata{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Errorf("replaying an unexpired consumed token: got %v, want %v", err, ErrRefreshTokenReused)
+	}
+}
+
+func TestRefreshRejectsUnknownTokens(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+
+	for _, token := range []string{"", "   ", "not-a-refresh-token"} {
+		if _, err := s.Refresh(context.Background(), token, SessionMetadata{}); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Errorf("Refresh(%q): got %v, want %v", token, err, ErrInvalidRefreshToken)
+		}
+	}
+}
+
+func TestCompleteLoginRecordsMethod(t *testing.T) {
+	s, clock := newTestService(t, Config{})
+	ctx := context.Background()
+
+	completeLogin := func(user queries.User, amr []string) AuthResult {
+		t.Helper()
+		var result AuthResult
+		err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+			var err error
+			result, err = s.completeLogin(ctx, q, user, SessionMetadata{}, amr)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("complete login: %v", err)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name string
+		amr  []string
+	}{
+		{name: "password", amr: []string{amrPassword}},
+		{name: "magic link", amr: []string{amrEmail}},
+		{name: "social sign-in", amr: []string{amrFederated}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := createTestUser(t, s, RoleSeeker)
+
+			result := completeLogin(user, tt.amr)
+			if amr := accessTokenAMR(t, s, result.AccessToken); !slices.Equal(amr, tt.amr) {
+				t.Errorf("amr = %v, want %v", amr, tt.amr)
+			}
+			session, err := s.store.Queries().GetUserSessionByHash(ctx, hashRefreshToken(result.RefreshToken))
+			if err != nil {
+				t.Fatalf("load session: %v", err)
+			}
+			if !slices.Equal(session.Amr, tt.amr) {
+				t.Errorf("session amr = %v, want %v", session.Amr, tt.amr)
+			}
+
+			// With a second factor the method is carried through the challenge.
+			secret, _ := enrollMFA(t, s, result)
+			clock.Advance(time.Minute)
+
+			challenge := completeLogin(user, tt.amr)
+			if challenge.MFAChallenge == nil {
+				t.Fatal("login of an MFA user returned tokens without a challenge")
+			}
+			completed, err := s.CompleteMFALogin(ctx, MFALoginInput{
+				ChallengeToken: challenge.MFAChallenge.Token,
+				Code:           currentTOTP(t, s, secret),
+			})
+			if err != nil {
+				t.Fatalf("complete MFA login: %v", err)
+			}
+			want := append(slices.Clone(tt.amr), amrOTP)
+			if amr := accessTokenAMR(t, s, completed.AccessToken); !slices.Equal(amr, want) {
+				t.Errorf("MFA amr = %v, want %v", amr, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+// ErrSessionNotFound indicates no active session matched for the user.
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionInfo describes an active sign-in for self-service session management.
+// ID is the stable session family identifier, which survives refresh token rotation.
+type SessionInfo struct {
+	ID         uuid.UUID
+	UserAgent  string
+	IP         string
+	Device     DeviceInfo
+	SignedInAt time.Time
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+}
+
+// ListSessions returns the user's active refresh sessions, most recently used first.
+func (s *Service) ListSessions(ctx context.Context, userID uuid.UUID) ([]SessionInfo, error) {
+	rows, err := s.store.Queries().ListActiveUserSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]SessionInfo, 0, len(rows))
+	for _, row := range rows {
+		info := SessionInfo{
+			ID:         row.FamilyID,
+			Device:     parseUserAgent(row.UserAgent.String),
+			SignedInAt: row.SignedInAt,
+			LastUsedAt: row.LastUsedAt,
+			ExpiresAt:  row.ExpiresAt,
+		}
+		if row.UserAgent.Valid {
+			info.UserAgent = row.UserAgent.String
+		}
+		if row.Ip.Valid {
+			info.IP = row.Ip.IPNet.IP.String()
+		}
+		sessions = append(sessions, info)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession signs out a single session belonging to the user.
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		if err := s.revokeFamilyAccessTokens(ctx, q, sessionID); err != nil {
+			return err
+		}
+
+		deleted, err := q.DeleteUserSessionFamilyForUser(ctx, queries.DeleteUserSessionFamilyForUserParams{
+			FamilyID: sessionID,
+			UserID:   userID,
+		})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			// Rolls back the revocation too, so other users' sessions cannot be targeted.
+			return ErrSessionNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.revocations.markStale()
+	return nil
+}
+
+// RevokeOtherSessions signs out every session of the user except the current one.
+func (s *Service) RevokeOtherSessions(ctx context.Context, userID, currentSessionID uuid.UUID) error {
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		if err := s.revokeOtherAccessTokens(ctx, q, userID, currentSessionID); err != nil {
+			return err
+		}
+		return q.DeleteOtherUserSessions(ctx, queries.DeleteOtherUserSessionsParams{
+			UserID:       userID,
+			KeepFamilyID: currentSessionID,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.revocations.markStale()
+	return nil
+}
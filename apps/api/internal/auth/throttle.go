@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+const (
+	throttleScopeAccount = "account"
+	throttleScopeIP      = "ip"
+)
+
+var (
+	// ErrAccountLocked indicates the account is temporarily locked after repeated failed logins.
+	ErrAccountLocked = errors.New("account temporarily locked due to failed login attempts")
+	// ErrTooManyAttempts indicates the client IP is temporarily blocked after repeated failed logins.
+	ErrTooManyAttempts = errors.New("too many failed login attempts")
+)
+
+// ThrottleConfig controls failed-login backoff. Once a key reaches its threshold it is locked for
+// BaseLockout, doubling with each further failure up to MaxLockout. Counters reset after
+// ResetAfter without failures.
+type ThrottleConfig struct {
+	AccountThreshold int
+	IPThreshold      int
+	BaseLockout      time.Duration
+	MaxLockout       time.Duration
+	ResetAfter       time.Duration
+}
+
+// LockoutError carries how long the caller must wait before trying again.
+type LockoutError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", e.Err.Error(), e.RetryAfter.Round(time.Second))
+}
+
+func (e *LockoutError) Unwrap() error {
+	return e.Err
+}
+
+func (c ThrottleConfig) withDefaults() ThrottleConfig {
+	if c.AccountThreshold <= 0 {
+		c.AccountThreshold = 5
+	}
+	if c.IPThreshold <= 0 {
+		c.IPThreshold = 20
+	}
+	if c.BaseLockout <= 0 {
+		c.BaseLockout = time.Minute
+	}
+	if c.MaxLockout <= 0 {
+		c.MaxLockout = time.Hour
+	}
+	if c.ResetAfter <= 0 {
+		c.ResetAfter = 24 * time.Hour
+	}
+	return c
+}
+
+// lockoutFor returns the lock duration after the given number of consecutive failures.
+func (c ThrottleConfig) lockoutFor(failures, threshold int) time.Duration {
+	if failures < threshold {
+		return 0
+	}
+
+	lockout := c.BaseLockout
+	for i := threshold; i < failures; i++ {
+		lockout *= 2
+		if lockout >= c.MaxLockout {
+			return c.MaxLockout
+		}
+	}
+	return lockout
+}
+
+// checkLoginThrottle refuses the attempt when the account or client IP is currently locked.
+func (s *Service) checkLoginThrottle(ctx context.Context, email, ip string, now time.Time) error {
+	q := s.store.Queries()
+
+	checks := []struct {
+		scope string
+		key   string
+		err   error
+	}{
+		{throttleScopeAccount, email, ErrAccountLocked},
+		{throttleScopeIP, ip, ErrTooManyAttempts},
+	}
+
+	for _, check := range checks {
+		if check.key == "" {
+			continue
+		}
+
+		throttle, err := q.GetLoginThrottle(ctx, queries.GetLoginThrottleParams{Scope: check.scope, Key: check.key})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return err
+		}
+
+		if throttle.LockedUntil.Valid && throttle.LockedUntil.Time.After(now) {
+			return &LockoutError{Err: check.err, RetryAfter: throttle.LockedUntil.Time.Sub(now)}
+		}
+	}
+
+	return nil
+}
+
+// recordLoginFailure increments the account and IP counters, locking them once over threshold.
+func (s *Service) recordLoginFailure(ctx context.Context, email, ip string, now time.Time) error {
+	cfg := s.config.Throttle
+
+	return s.store.WithTx(ctx, func(q *queries.Queries) error {
+		counters := []struct {
+			scope     string
+			key       string
+			threshold int
+		}{
+			{throttleScopeAccount, email, cfg.AccountThreshold},
+			{throttleScopeIP, ip, cfg.IPThreshold},
+		}
+
+		for _, counter := range counters {
+			if counter.key == "" {
+				continue
+			}
+
+			throttle, err := q.RecordLoginFailure(ctx, queries.RecordLoginFailureParams{
+				Scope:       counter.scope,
+				Key:         counter.key,
+				FailedAt:    now,
+				ResetBefore: now.Add(-cfg.ResetAfter),
+			})
+			if err != nil {
+				return err
+			}
+
+			lockout := cfg.lockoutFor(int(throttle.Failures), counter.threshold)
+			if lockout == 0 {
+				continue
+			}
+
+			if err := q.SetLoginThrottleLock(ctx, queries.SetLoginThrottleLockParams{
+				Scope:       counter.scope,
+				Key:         counter.key,
+				LockedUntil: sql.NullTime{Time: now.Add(lockout), Valid: true},
+			}); err != nil {
+				return err
+			}
+
+			s.logger.Warn().
+				Str("event", "login_lockout").
+				Str("scope", counter.scope).
+				Str("key", counter.key).
+				Int32("failures", throttle.Failures).
+				Dur("lockout", lockout).
+				Msg("login temporarily locked after repeated failures")
+		}
+
+		return nil
+	})
+}
+
+// UnlockAccount clears the failed-login counter and any lockout for the user's account.
+func (s *Service) UnlockAccount(ctx context.Context, userID uuid.UUID) error {
+	return s.store.WithTx(ctx, func(q *queries.Queries) error {
+		user, err := q.GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		return q.DeleteLoginThrottle(ctx, queries.DeleteLoginThrottleParams{
+			Scope: throttleScopeAccount,
+			Key:   user.Email,
+		})
+	})
+}
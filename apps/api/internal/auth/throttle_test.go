@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+func TestLockoutFor(t *testing.T) {
+	cfg := ThrottleConfig{
+		BaseLockout: time.Minute,
+		MaxLockout:  10 * time.Minute,
+	}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 0},
+		{failures: 4, want: 0},
+		{failures: 5, want: time.Minute},
+		{failures: 6, want: 2 * time.Minute},
+		{failures: 7, want: 4 * time.Minute},
+		{failures: 8, want: 8 * time.Minute},
+		{failures: 9, want: 10 * time.Minute},
+		{failures: 50, want: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d failures", tt.failures), func(t *testing.T) {
+			if got := cfg.lockoutFor(tt.failures, 5); got != tt.want {
+				t.Errorf("lockoutFor(%d, 5) = %v, want %v", tt.failures, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThrottleConfigDefaults(t *testing.T) {
+	got := ThrottleConfig{}.withDefaults()
+	want := ThrottleConfig{
+		AccountThreshold: 5,
+		IPThreshold:      20,
+		BaseLockout:      time.Minute,
+		MaxLockout:       time.Hour,
+		ResetAfter:       24 * time.Hour,
+	}
+	if got != want {
+		t.Errorf("withDefaults() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoginAccountLockout(t *testing.T) {
+	s, clock := newTestService(t, Config{Throttle: ThrottleConfig{
+		AccountThreshold: 3,
+		BaseLockout:      time.Minute,
+		MaxLockout:       4 * time.Minute,
+		ResetAfter:       time.Hour,
+	}})
+	ctx := context.Background()
+	user := createTestUser(t, s, RoleSeeker)
+
+	attempt := func(password string) error {
+		_, err := s.Login(ctx, LoginInput{Email: user.Email, Password: password})
+		return err
+	}
+
+	// Each step advances the clock, then tries to log in.
+	tests := []struct {
+		name       string
+		advance    time.Duration
+		password   string
+		wantErr    error
+		wantRetry  time.Duration
+		wantNoLock bool
+	}{
+		{name: "first failure", password: "wrong-password", wantErr: ErrInvalidCredentials},
+		{name: "second failure", password: "wrong-password", wantErr: ErrInvalidCredentials},
+		{name: "third failure locks", password: "wrong-password", wantErr: ErrInvalidCredentials},
+		{name: "correct password while locked", advance: 30 * time.Second, password: testPassword, wantErr: ErrAccountLocked, wantRetry: 30 * time.Second},
+		{name: "fourth failure doubles", advance: 31 * time.Second, password: "wrong-password", wantErr: ErrInvalidCredentials},
+		{name: "still locked", advance: 90 * time.Second, password: testPassword, wantErr: ErrAccountLocked, wantRetry: 30 * time.Second},
+		{name: "correct password after lockout", advance: 31 * time.Second, password: testPassword, wantNoLock: true},
+	}
+
+	for _, tt := range tests {
+		clock.Advance(tt.advance)
+		err := attempt(tt.password)
+
+		if tt.wantErr == nil {
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.name, err)
+			}
+		} else if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: got %v, want %v", tt.name, err, tt.wantErr)
+		}
+
+		if tt.wantRetry > 0 {
+			var lockout *LockoutError
+			if !errors.As(err, &lockout) {
+				t.Fatalf("%s: error %v is not a *LockoutError", tt.name, err)
+			}
+			if diff := lockout.RetryAfter - tt.wantRetry; diff < -time.Second || diff > time.Second {
+				t.Errorf("%s: retry after %v, want %v", tt.name, lockout.RetryAfter, tt.wantRetry)
+			}
+		}
+
+		if tt.wantNoLock {
+			_, err := s.store.Queries().GetLoginThrottle(ctx, queries.GetLoginThrottleParams{
+				Scope: throttleScopeAccount,
+				Key:   user.Email,
+			})
+			if err == nil {
+				t.Errorf("%s: throttle not cleared by a successful login", tt.name)
+			}
+		}
+	}
+}
+
+func TestLoginFailuresResetAfterQuietPeriod(t *testing.T) {
+	s, clock := newTestService(t, Config{Throttle: ThrottleConfig{
+		AccountThreshold: 2,
+		ResetAfter:       time.Hour,
+	}})
+	ctx := context.Background()
+	user := createTestUser(t, s, RoleSeeker)
+
+	if _, err := s.Login(ctx, LoginInput{Email: user.Email, Password: "wrong-password"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("first failure: got %v", err)
+	}
+
+	// A failure after the quiet period starts a new count instead of locking the account.
+	clock.Advance(time.Hour + time.Minute)
+	if _, err := s.Login(ctx, LoginInput{Email: user.Email, Password: "wrong-password"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("second failure: got %v", err)
+	}
+	if _, err := s.Login(ctx, LoginInput{Email: user.Email, Password: testPassword}); err != nil {
+		t.Fatalf("login after reset: %v", err)
+	}
+}
+
+func TestUnlockAccount(t *testing.T) {
+	s, _ := newTestService(t, Config{Throttle: ThrottleConfig{AccountThreshold: 1}})
+	ctx := context.Background()
+	user := createTestUser(t, s, RoleSeeker)
+
+	if _, err := s.Login(ctx, LoginInput{Email: user.Email, Password: "wrong-password"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("failure: got %v", err)
+	}
+	if _, err := s.Login(ctx, LoginInput{Email: user.Email, Password: testPassword}); !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("locked login: got %v, want %v", err, ErrAccountLocked)
+	}
+
+	if err := s.UnlockAccount(ctx, user.ID); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	if _, err := s.Login(ctx, LoginInput{Email: user.Email, Password: testPassword}); err != nil {
+		t.Fatalf("login after unlock: %v", err)
+	}
+}
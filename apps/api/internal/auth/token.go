@@ -17,56 +17,105 @@ var (
 	errInvalidAccessToken = errors.New("invalid access token")
 )
 
+// Authentication method references recorded in the amr claim. RFC 8176 has no value for magic
+// links or social sign-in, so those use "email" and "fed".
+const (
+	amrPassword  = "pwd"
+	amrOTP       = "otp"
+	amrEmail     = "email"
+	amrFederated = "fed"
+)
+
 type tokenClaims struct {
 	Role string `json:"role"`
+	// SessionID identifies the refresh token family the access token was issued for.
+	SessionID string `json:"sid,omitempty"`
+	// AMR lists the authentication methods behind the session.
+	AMR []string `json:"amr,omitempty"`
+	// Actor is set on impersonation tokens and names the administrator acting as the subject
+	// (RFC 8693 section 4.1).
+	Actor *actorClaim `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func generateAccessToken(secret string, userID uuid.UUID, role string, ttl time.Duration, now time.Time) (string, error) {
-	if secret == "" {
-		return "", errMissingSecret
+type actorClaim struct {
+	Subject string `json:"sub"`
+}
+
+func generateAccessToken(keys *Keyring, userID uuid.UUID, role string, sessionID, jti uuid.UUID, amr []string, ttl time.Duration, now time.Time) (string, error) {
+	claims := tokenClaims{
+		Role:      role,
+		SessionID: sessionID.String(),
+		AMR:       amr,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti.String(),
+			Subject:   userID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
 	}
 
+	return keys.sign(claims)
+}
+
+// generateImpersonationToken issues an access token for userID carrying an act claim for the
+// administrator. sessionID identifies the impersonation session rather than a refresh family.
+func generateImpersonationToken(keys *Keyring, userID uuid.UUID, role string, sessionID, jti, actorID uuid.UUID, ttl time.Duration, now time.Time) (string, error) {
 	claims := tokenClaims{
-		Role: role,
+		Role:      role,
+		SessionID: sessionID.String(),
+		Actor:     &actorClaim{Subject: actorID.String()},
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti.String(),
 			Subject:   userID.String(),
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return keys.sign(claims)
 }
 
 func generateRefreshToken(ttl time.Duration, now time.Time) (token string, hashed string, expires time.Time, err error) {
-	buf := make([]byte, 32)
-	if _, err = rand.Read(buf); err != nil {
+	return generateOpaqueToken(ttl, now)
+}
+
+func hashRefreshToken(token string) string {
+	return hashOpaqueToken(token)
+}
+
+// generateOpaqueToken returns a random URL-safe token along with the hash to persist and its expiry.
+func generateOpaqueToken(ttl time.Duration, now time.Time) (token string, hashed string, expires time.Time, err error) {
+	token, err = randomToken()
+	if err != nil {
 		return "", "", time.Time{}, err
 	}
 
-	token = base64.RawURLEncoding.EncodeToString(buf)
-	hashed = hashRefreshToken(token)
+	hashed = hashOpaqueToken(token)
 	return token, hashed, now.Add(ttl), nil
 }
 
-func hashRefreshToken(token string) string {
+// randomToken returns 32 bytes of randomness encoded as URL-safe base64.
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashOpaqueToken(token string) string {
 	sum := sha256.Sum256([]byte(token))
 	return hex.EncodeToString(sum[:])
 }
 
-func parseAccessToken(secret, token string) (tokenClaims, error) {
-	if secret == "" {
+func parseAccessToken(keys *Keyring, token string) (tokenClaims, error) {
+	methods := keys.validMethods()
+	if len(methods) == 0 {
 		return tokenClaims{}, errMissingSecret
 	}
 
-	parsed, err := jwt.ParseWithClaims(token, &tokenClaims{}, func(t *jwt.Token) (any, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errInvalidAccessToken
-		}
-		return []byte(secret), nil
-	})
+	parsed, err := jwt.ParseWithClaims(token, &tokenClaims{}, keys.keyFunc, jwt.WithValidMethods(methods))
 	if err != nil {
 		return tokenClaims{}, err
 	}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Key is the SHA-1 seed from RFC 6238 appendix B.
+var rfc6238Key = []byte("12345678901234567890")
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	// The RFC lists eight-digit codes; six-digit codes are their last six digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+
+	for _, tt := range tests {
+		step := totpStep(time.Unix(tt.unix, 0))
+		if got := totpCode(rfc6238Key, step); got != tt.want {
+			t.Errorf("totpCode at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := totpEncoding.EncodeToString(rfc6238Key)
+	now := time.Unix(1234567890, 0)
+	current := totpStep(now)
+	codeAt := func(step int64) string { return totpCode(rfc6238Key, step) }
+
+	tests := []struct {
+		name         string
+		secret       string
+		code         string
+		lastUsedStep int64
+		wantStep     int64
+		wantOK       bool
+	}{
+		{name: "current step", secret: secret, code: codeAt(current), wantStep: current, wantOK: true},
+		{name: "previous step", secret: secret, code: codeAt(current - 1), wantStep: current - 1, wantOK: true},
+		{name: "next step", secret: secret, code: codeAt(current + 1), wantStep: current + 1, wantOK: true},
+		{name: "outside skew", secret: secret, code: codeAt(current - 2)},
+		{name: "lowercase secret", secret: strings.ToLower(secret), code: codeAt(current), wantStep: current, wantOK: true},
+		{name: "replayed step", secret: secret, code: codeAt(current), lastUsedStep: current},
+		{name: "later step after use", secret: secret, code: codeAt(current + 1), lastUsedStep: current, wantStep: current + 1, wantOK: true},
+		{name: "wrong code", secret: secret, code: "000000"},
+		{name: "not a code", secret: secret, code: "12345a"},
+		{name: "invalid secret", secret: "not base32!", code: codeAt(current)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := validateTOTP(tt.secret, tt.code, now, tt.lastUsedStep)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("validateTOTP = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestIsTOTPCode(t *testing.T) {
+	tests := map[string]bool{
+		"123456":      true,
+		"000000":      true,
+		"12345":       false,
+		"1234567":     false,
+		"12 456":      false,
+		"abcde-fghjk": false,
+		"":            false,
+	}
+	for code, want := range tests {
+		if got := isTOTPCode(code); got != want {
+			t.Errorf("isTOTPCode(%q) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		t.Fatalf("generateTOTPSecret: %v", err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret %q is not unpadded base32: %v", secret, err)
+	}
+	if len(key) != totpSecretLen {
+		t.Errorf("key length = %d, want %d", len(key), totpSecretLen)
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	raw := totpURI("Synergy Vets", "vet@example.com", "JBSWY3DPEHPK3PXP")
+
+	uri, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("parse %q: %v", raw, err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" {
+		t.Errorf("uri %q is not an otpauth totp URI", raw)
+	}
+	if uri.Path != "/Synergy Vets:vet@example.com" {
+		t.Errorf("label = %q", uri.Path)
+	}
+
+	want := map[string]string{
+		"secret":    "JBSWY3DPEHPK3PXP",
+		"issuer":    "Synergy Vets",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	}
+	query := uri.Query()
+	for key, value := range want {
+		if got := query.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package auth
+
+import "strings"
+
+// DeviceInfo summarises a User-Agent string for display in session listings.
+type DeviceInfo struct {
+	Browser string `json:"browser"`
+	OS      string `json:"os"`
+	Type    string `json:"type"`
+}
+
+// parseUserAgent performs a best-effort classification of common browsers and platforms.
+// Order matters: many browsers include the tokens of the engines they derive from.
+func parseUserAgent(ua string) DeviceInfo {
+	info := DeviceInfo{Browser: "Unknown", OS: "Unknown", Type: "desktop"}
+	if strings.TrimSpace(ua) == "" {
+		info.Type = "unknown"
+		return info
+	}
+
+	lower := strings.ToLower(ua)
+
+	switch {
+	case strings.Contains(lower, "edg/") || strings.Contains(lower, "edga/") || strings.Contains(lower, "edgios/"):
+		info.Browser = "Edge"
+	case strings.Contains(lower, "opr/") || strings.Contains(lower, "opera"):
+		info.Browser = "Opera"
+	case strings.Contains(lower, "samsungbrowser/"):
+		info.Browser = "Samsung Internet"
+	case strings.Contains(lower, "firefox/") || strings.Contains(lower, "fxios/"):
+		info.Browser = "Firefox"
+	case strings.Contains(lower, "chrome/") || strings.Contains(lower, "crios/"):
+		info.Browser = "Chrome"
+	case strings.Contains(lower, "safari/"):
+		info.Browser = "Safari"
+	case strings.Contains(lower, "curl/"):
+		info.Browser = "curl"
+	case strings.Contains(lower, "okhttp") || strings.Contains(lower, "cfnetwork") || strings.Contains(lower, "dart:io"):
+		info.Browser = "App"
+	}
+
+	switch {
+	case strings.Contains(lower, "iphone") || strings.Contains(lower, "ipod"):
+		info.OS = "iOS"
+		info.Type = "mobile"
+	case strings.Contains(lower, "ipad"):
+		info.OS = "iPadOS"
+		info.Type = "tablet"
+	case strings.Contains(lower, "android"):
+		info.OS = "Android"
+		info.Type = "mobile"
+		if !strings.Contains(lower, "mobile") {
+			info.Type = "tablet"
+		}
+	case strings.Contains(lower, "windows"):
+		info.OS = "Windows"
+	case strings.Contains(lower, "cros"):
+		info.OS = "ChromeOS"
+	case strings.Contains(lower, "mac os x") || strings.Contains(lower, "macintosh"):
+		info.OS = "macOS"
+	case strings.Contains(lower, "linux"):
+		info.OS = "Linux"
+	}
+
+	return info
+}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+// VerifyEmail consumes a verification token and activates the associated account. Tokens
+// issued by RequestEmailChange switch the account to the address they were sent to.
+func (s *Service) VerifyEmail(ctx context.Context, verificationToken string) (queries.User, error) {
+	result := queries.User{}
+	token := strings.TrimSpace(verificationToken)
+	if token == "" {
+		return result, ErrInvalidVerificationToken
+	}
+
+	hashed := hashOpaqueToken(token)
+
+	var previousEmail string
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		record, err := q.GetEmailVerificationTokenByHash(ctx, hashed)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidVerificationToken
+			}
+			return err
+		}
+
+		if record.ConsumedAt.Valid {
+			return ErrInvalidVerificationToken
+		}
+		if record.ExpiresAt.Before(s.now()) {
+			return ErrExpiredVerificationToken
+		}
+
+		user, err := q.GetUserByID(ctx, record.UserID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidVerificationToken
+			}
+			return err
+		}
+
+		consumed, err := q.ConsumeEmailVerificationToken(ctx, record.ID)
+		if err != nil {
+			return err
+		}
+		if consumed == 0 {
+			return ErrInvalidVerificationToken
+		}
+
+		// The token is bound to the address it was sent to; a different address means the
+		// user asked to change their email and has now proven they own the new one.
+		if !strings.EqualFold(user.Email, record.Email) {
+			if strings.ToLower(user.Status) != statusActive {
+				return ErrInvalidVerificationToken
+			}
+			if _, err := q.GetUserByEmail(ctx, record.Email); err == nil {
+				return ErrEmailInUse
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+
+			previousEmail = user.Email
+			user, err = q.UpdateUserEmail(ctx, queries.UpdateUserEmailParams{ID: user.ID, Email: record.Email})
+			if err != nil {
+				return err
+			}
+			if err := q.DeletePendingEmailVerificationTokens(ctx, user.ID); err != nil {
+				return err
+			}
+		}
+
+		if strings.ToLower(user.Status) == statusPendingVerification {
+			user, err = q.UpdateUserStatus(ctx, queries.UpdateUserStatusParams{
+				ID:     user.ID,
+				Status: statusActive,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		user.PasswordHash = ""
+		result = user
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	s.invalidatePrincipal(ctx, result.ID)
+	if previousEmail != "" {
+		s.logger.Info().Str("event", "email_changed").Str("user_id", result.ID.String()).Msg("email address changed")
+		s.sendEmailChangedNotice(ctx, previousEmail, result.Email)
+	}
+	return result, nil
+}
+
+// ResendVerification issues a fresh verification email for an unverified account.
+// It reports success regardless of whether the email exists to avoid account enumeration.
+func (s *Service) ResendVerification(ctx context.Context, email string) error {
+	normalized := strings.TrimSpace(strings.ToLower(email))
+	if normalized == "" {
+		return ErrInvalidEmail
+	}
+
+	var recipient, verificationToken string
+	err := s.store.WithTx(ctx, func(q *queries.Queries) error {
+		user, err := q.GetUserByEmail(ctx, normalized)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+
+		if strings.ToLower(user.Status) != statusPendingVerification {
+			return nil
+		}
+
+		if err := q.DeletePendingEmailVerificationTokens(ctx, user.ID); err != nil {
+			return err
+		}
+
+		verificationToken, err = s.createVerificationToken(ctx, q, user)
+		if err != nil {
+			return err
+		}
+		recipient = user.Email
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if verificationToken != "" {
+		s.sendVerificationEmail(ctx, recipient, verificationToken)
+	}
+	return nil
+}
+
+func (s *Service) createVerificationToken(ctx context.Context, q *queries.Queries, user queries.User) (string, error) {
+	token, hashed, expiresAt, err := generateOpaqueToken(s.config.VerificationTokenTTL, s.now())
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := q.CreateEmailVerificationToken(ctx, queries.CreateEmailVerificationTokenParams{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: hashed,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type verifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type resendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+func (h *Handler) handleVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req verifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	user, err := h.service.VerifyEmail(r.Context(), req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidVerificationToken):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrExpiredVerificationToken):
+			writeError(w, http.StatusGone, err.Error())
+		case errors.Is(err, ErrEmailInUse):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to verify email")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toUserResponse(user))
+}
+
+func (h *Handler) handleResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req resendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if err := h.service.ResendVerification(r.Context(), req.Email); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidEmail):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to resend verification email")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
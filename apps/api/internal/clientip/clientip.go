@@ -0,0 +1,214 @@
+// Package clientip resolves the originating client address of a request, honouring
+// X-Forwarded-For and Forwarded (RFC 7239) headers only when they were appended by trusted proxies.
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type contextKey struct{}
+
+// Header names the forwarding header trusted proxies append the client address to.
+type Header string
+
+const (
+	// HeaderXForwardedFor is X-Forwarded-For, appended by nginx, load balancers and most CDNs.
+	HeaderXForwardedFor Header = "X-Forwarded-For"
+	// HeaderForwarded is the RFC 7239 Forwarded header.
+	HeaderForwarded Header = "Forwarded"
+)
+
+// ParseHeader accepts "X-Forwarded-For" or "Forwarded", case-insensitively.
+func ParseHeader(value string) (Header, error) {
+	switch {
+	case strings.EqualFold(value, string(HeaderXForwardedFor)):
+		return HeaderXForwardedFor, nil
+	case strings.EqualFold(value, string(HeaderForwarded)):
+		return HeaderForwarded, nil
+	default:
+		return "", fmt.Errorf("unknown forwarding header %q, want %s or %s", value, HeaderXForwardedFor, HeaderForwarded)
+	}
+}
+
+// Resolver determines client IPs given the set of proxies allowed to report them.
+// The zero value trusts no proxies and always returns the connection's peer address.
+type Resolver struct {
+	trusted []netip.Prefix
+	header  Header
+}
+
+// NewResolver builds a Resolver that trusts the given forwarding header when set by peers
+// within the given prefixes. Only that header is read: a proxy passes other forwarding headers
+// through from the client unchanged, so they cannot be trusted.
+func NewResolver(trusted []netip.Prefix, header Header) *Resolver {
+	if header == "" {
+		header = HeaderXForwardedFor
+	}
+	return &Resolver{trusted: trusted, header: header}
+}
+
+// ParsePrefixes parses CIDR ranges, accepting bare addresses as single-host prefixes.
+func ParsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if strings.Contains(value, "/") {
+			prefix, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// Middleware resolves the client IP once per request and stores it for FromRequest and
+// FromContext.
+func (res *Resolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), contextKey{}, res.Resolve(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// FromRequest returns the client IP resolved by Middleware, falling back to the peer address
+// when the middleware did not run.
+func FromRequest(r *http.Request) string {
+	if ip := FromContext(r.Context()); ip != "" {
+		return ip
+	}
+	return peerAddr(r.RemoteAddr)
+}
+
+// FromContext returns the client IP resolved by Middleware for the request that ctx belongs
+// to, or "" outside of such a request.
+func FromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(contextKey{}).(string)
+	return ip
+}
+
+// Resolve walks the forwarding chain of the configured header from the nearest hop outwards
+// and returns the first address not covered by a trusted prefix.
+func (res *Resolver) Resolve(r *http.Request) string {
+	peer := peerAddr(r.RemoteAddr)
+	if res == nil || len(res.trusted) == 0 {
+		return peer
+	}
+
+	addr, err := netip.ParseAddr(peer)
+	if err != nil || !res.isTrusted(addr.Unmap()) {
+		return peer
+	}
+
+	var hops []string
+	if res.header == HeaderForwarded {
+		hops = forwardedFor(r.Header.Values(string(HeaderForwarded)))
+	} else {
+		hops = forwardedList(r.Header.Values(string(HeaderXForwardedFor)))
+	}
+
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, ok := parseHop(hops[i])
+		if !ok {
+			// Obfuscated or malformed entries cannot be verified; keep the nearest known address.
+			return client
+		}
+
+		client = hop.String()
+		if !res.isTrusted(hop) {
+			return client
+		}
+	}
+	return client
+}
+
+func (res *Resolver) isTrusted(addr netip.Addr) bool {
+	for _, prefix := range res.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedList flattens comma separated X-Forwarded-For header lines in order.
+func forwardedList(values []string) []string {
+	var hops []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				hops = append(hops, part)
+			}
+		}
+	}
+	return hops
+}
+
+// forwardedFor extracts the for= parameter of each Forwarded element in order. Elements without
+// a for= parameter are recorded as empty so they halt the walk.
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, element := range forwardedList(values) {
+		var hop string
+		for _, pair := range strings.Split(element, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(strings.TrimSpace(name), "for") {
+				hop = strings.Trim(strings.TrimSpace(value), `"`)
+				break
+			}
+		}
+		hops = append(hops, hop)
+	}
+	return hops
+}
+
+// parseHop accepts bare addresses, bracketed IPv6 and host:port forms.
+func parseHop(value string) (netip.Addr, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return netip.Addr{}, false
+	}
+
+	if addr, err := netip.ParseAddr(value); err == nil {
+		return addr.Unmap(), true
+	}
+
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		if addr, err := netip.ParseAddr(value[1 : len(value)-1]); err == nil {
+			return addr.Unmap(), true
+		}
+	}
+
+	return netip.Addr{}, false
+}
+
+func peerAddr(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
@@ -0,0 +1,102 @@
+package clientip
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name       string
+		header     Header
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{
+			name:       "untrusted peer ignores headers",
+			header:     HeaderXForwardedFor,
+			remoteAddr: "198.51.100.7:4000",
+			headers:    map[string]string{"X-Forwarded-For": "1.2.3.4"},
+			want:       "198.51.100.7",
+		},
+		{
+			name:       "x-forwarded-for from trusted proxy",
+			header:     HeaderXForwardedFor,
+			remoteAddr: "10.0.0.2:4000",
+			headers:    map[string]string{"X-Forwarded-For": "1.2.3.4, 203.0.113.9, 10.0.0.3"},
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "client supplied forwarded is ignored when proxies append x-forwarded-for",
+			header:     HeaderXForwardedFor,
+			remoteAddr: "10.0.0.2:4000",
+			headers: map[string]string{
+				"Forwarded":       "for=1.2.3.4",
+				"X-Forwarded-For": "203.0.113.9",
+			},
+			want: "203.0.113.9",
+		},
+		{
+			name:       "client supplied x-forwarded-for is ignored when proxies append forwarded",
+			header:     HeaderForwarded,
+			remoteAddr: "10.0.0.2:4000",
+			headers: map[string]string{
+				"Forwarded":       `for="[2001:db8::1]:1234"`,
+				"X-Forwarded-For": "1.2.3.4",
+			},
+			want: "2001:db8::1",
+		},
+		{
+			name:       "missing configured header falls back to the peer",
+			header:     HeaderForwarded,
+			remoteAddr: "10.0.0.2:4000",
+			headers:    map[string]string{"X-Forwarded-For": "1.2.3.4"},
+			want:       "10.0.0.2",
+		},
+		{
+			name:       "obfuscated hop stops the walk",
+			header:     HeaderForwarded,
+			remoteAddr: "10.0.0.2:4000",
+			headers:    map[string]string{"Forwarded": "for=1.2.3.4, for=_hidden, for=10.0.0.3"},
+			want:       "10.0.0.3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+
+			if got := NewResolver(trusted, tt.header).Resolve(r); got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Header
+		wantErr bool
+	}{
+		{value: "X-Forwarded-For", want: HeaderXForwardedFor},
+		{value: "x-forwarded-for", want: HeaderXForwardedFor},
+		{value: "forwarded", want: HeaderForwarded},
+		{value: "X-Real-IP", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseHeader(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseHeader(%q) = %q, %v; want %q, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package geo
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Place kinds in a gazetteer file.
+const (
+	KindTown             = "town"
+	KindCounty           = "county"
+	KindPostcodeArea     = "postcode_area"
+	KindPostcodeDistrict = "postcode_district"
+)
+
+// ukPlaces lists UK towns, counties and postcode areas with approximate centre points. It has
+// a "kind,name,latitude,longitude" header; postcode rows are named by their area code (e.g. LS).
+// It has no postcode_district rows, so every postcode and district resolves to the centre of its
+// area, which can be tens of kilometres from the address.
+//
+//go:embed data/uk_places.csv
+var ukPlaces string
+
+// postcodePattern matches a full postcode or its outward code with spaces removed, capturing
+// the area, the district number and the optional inward code.
+var postcodePattern = regexp.MustCompile(`^([A-Z]{1,2})([0-9][A-Z0-9]?)([0-9][A-Z]{2})?$`)
+
+// postcodeAreaPattern matches a bare postcode area such as "LS".
+var postcodeAreaPattern = regexp.MustCompile(`^[A-Z]{1,2}$`)
+
+// Place is a named point in a gazetteer.
+type Place struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	Point
+}
+
+// Gazetteer resolves place names and postcodes offline.
+type Gazetteer struct {
+	names     map[string]Place
+	postcodes map[string]Place
+}
+
+var loadUK = sync.OnceValue(func() *Gazetteer {
+	gazetteer, err := ParseGazetteer(strings.NewReader(ukPlaces))
+	if err != nil {
+		panic(fmt.Sprintf("geo: embedded UK gazetteer: %v", err))
+	}
+	return gazetteer
+})
+
+// UK returns the bundled gazetteer of UK towns, counties and postcode areas.
+func UK() *Gazetteer {
+	return loadUK()
+}
+
+// ParseGazetteer reads a gazetteer in the format of the bundled data file.
+func ParseGazetteer(r io.Reader) (*Gazetteer, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("missing header")
+	}
+
+	gazetteer := &Gazetteer{
+		names:     make(map[string]Place, len(records)),
+		postcodes: make(map[string]Place),
+	}
+	for i, record := range records[1:] {
+		line := i + 2
+		place := Place{Kind: record[0], Name: strings.TrimSpace(record[1])}
+		if place.Latitude, err = strconv.ParseFloat(record[2], 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid latitude %q", line, record[2])
+		}
+		if place.Longitude, err = strconv.ParseFloat(record[3], 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid longitude %q", line, record[3])
+		}
+		if err := place.validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		var (
+			index map[string]Place
+			key   string
+		)
+		switch place.Kind {
+		case KindTown, KindCounty:
+			index, key = gazetteer.names, normalizeName(place.Name)
+		case KindPostcodeArea, KindPostcodeDistrict:
+			index, key = gazetteer.postcodes, normalizePostcode(place.Name)
+		default:
+			return nil, fmt.Errorf("line %d: unknown kind %q", line, place.Kind)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("line %d: missing name", line)
+		}
+		if _, exists := index[key]; exists {
+			return nil, fmt.Errorf("line %d: duplicate place %q", line, place.Name)
+		}
+		index[key] = place
+	}
+	return gazetteer, nil
+}
+
+// Resolve turns "latitude,longitude", a postcode or a place name into a point.
+func (g *Gazetteer) Resolve(value string) (Point, error) {
+	place, err := g.ResolvePlace(value)
+	return place.Point, err
+}
+
+// ResolvePlace is Resolve returning the matched place, so callers can tell how precise the
+// point is. Coordinates resolve to a Place with no Name or Kind.
+func (g *Gazetteer) ResolvePlace(value string) (Place, error) {
+	value = strings.TrimSpace(value)
+	if looksLikePoint(value) {
+		point, err := ParsePoint(value)
+		return Place{Point: point}, err
+	}
+	place, ok := g.Lookup(value)
+	if !ok {
+		return Place{}, ErrUnknownPlace
+	}
+	return place, nil
+}
+
+// Lookup finds a place by name or postcode. Postcodes and districts missing from the
+// gazetteer resolve to their postcode area. A name with a qualifier such as
+// "Leeds, West Yorkshire" falls back to its first known part.
+func (g *Gazetteer) Lookup(value string) (Place, bool) {
+	code := normalizePostcode(value)
+	if match := postcodePattern.FindStringSubmatch(code); match != nil {
+		if place, ok := g.postcodes[match[1]+match[2]]; ok {
+			return place, true
+		}
+		place, ok := g.postcodes[match[1]]
+		return place, ok
+	}
+	if postcodeAreaPattern.MatchString(code) {
+		place, ok := g.postcodes[code]
+		return place, ok
+	}
+
+	if place, ok := g.names[normalizeName(value)]; ok {
+		return place, true
+	}
+	for part := range strings.SplitSeq(value, ",") {
+		if place, ok := g.names[normalizeName(part)]; ok {
+			return place, true
+		}
+	}
+	return Place{}, false
+}
+
+// normalizeName lower-cases a place name, drops apostrophes and full stops and collapses other
+// punctuation to single spaces, so "St. Albans" and "st albans" compare equal.
+func normalizeName(value string) string {
+	value = strings.ToLower(value)
+	value = strings.NewReplacer("'", "", "’", "", ".", "").Replace(value)
+	return strings.Join(strings.FieldsFunc(value, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9')
+	}), " ")
+}
+
+func normalizePostcode(value string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(value), ""))
+}
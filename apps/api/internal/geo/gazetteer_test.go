@@ -0,0 +1,84 @@
+package geo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testGazetteer = `kind,name,latitude,longitude
+town,Leeds,53.7997,-1.5492
+county,West Yorkshire,53.7500,-1.6667
+postcode_area,LS,53.8200,-1.5800
+postcode_district,LS6,53.8190,-1.5760
+`
+
+func TestGazetteerResolvePlace(t *testing.T) {
+	gazetteer, err := ParseGazetteer(strings.NewReader(testGazetteer))
+	if err != nil {
+		t.Fatalf("ParseGazetteer: %v", err)
+	}
+
+	tests := []struct {
+		value    string
+		wantName string
+		wantKind string
+		wantErr  error
+	}{
+		{value: "53.80,-1.55"},
+		{value: "Leeds", wantName: "Leeds", wantKind: KindTown},
+		{value: "leeds, west yorkshire", wantName: "Leeds", wantKind: KindTown},
+		{value: "West Yorkshire", wantName: "West Yorkshire", wantKind: KindCounty},
+		{value: "LS", wantName: "LS", wantKind: KindPostcodeArea},
+		{value: "ls6 3ab", wantName: "LS6", wantKind: KindPostcodeDistrict},
+		{value: "LS6", wantName: "LS6", wantKind: KindPostcodeDistrict},
+		{value: "LS2 9JT", wantName: "LS", wantKind: KindPostcodeArea},
+		{value: "BD1", wantErr: ErrUnknownPlace},
+		{value: "Atlantis", wantErr: ErrUnknownPlace},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			place, err := gazetteer.ResolvePlace(tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResolvePlace(%q): got error %v, want %v", tt.value, err, tt.wantErr)
+			}
+			if place.Name != tt.wantName || place.Kind != tt.wantKind {
+				t.Errorf("ResolvePlace(%q) = %s %q, want %s %q", tt.value, place.Kind, place.Name, tt.wantKind, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestUKHasNoPostcodeDistricts(t *testing.T) {
+	// The bundled data only has areas, so a full postcode lands on its area centre.
+	place, err := UK().ResolvePlace("LS6 3AB")
+	if err != nil {
+		t.Fatalf("ResolvePlace: %v", err)
+	}
+	if place.Kind != KindPostcodeArea || place.Name != "LS" {
+		t.Errorf("ResolvePlace(%q) = %s %q, want %s %q", "LS6 3AB", place.Kind, place.Name, KindPostcodeArea, "LS")
+	}
+}
+
+func TestParseGazetteerErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "missing header", data: ""},
+		{name: "unknown kind", data: "kind,name,latitude,longitude\nvillage,Headingley,53.82,-1.58\n"},
+		{name: "invalid latitude", data: "kind,name,latitude,longitude\ntown,Leeds,north,-1.55\n"},
+		{name: "out of range", data: "kind,name,latitude,longitude\ntown,Leeds,95,-1.55\n"},
+		{name: "missing name", data: "kind,name,latitude,longitude\ntown, ,53.80,-1.55\n"},
+		{name: "duplicate", data: "kind,name,latitude,longitude\npostcode_area,LS,53.82,-1.58\npostcode_area,ls,53.82,-1.58\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseGazetteer(strings.NewReader(tt.data)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Package geo resolves place names, postcodes and coordinates to points on the map.
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnknownPlace indicates a place name or postcode is not in the gazetteer.
+var ErrUnknownPlace = errors.New("unknown place")
+
+// Point is a WGS84 coordinate in decimal degrees.
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// ParsePoint parses a "latitude,longitude" pair such as "51.5072,-0.1276".
+func ParsePoint(value string) (Point, error) {
+	lat, lng, ok := strings.Cut(value, ",")
+	if !ok {
+		return Point{}, fmt.Errorf("invalid coordinates %q: expected latitude,longitude", value)
+	}
+
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	if err != nil {
+		return Point{}, fmt.Errorf("invalid latitude %q", lat)
+	}
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(lng), 64)
+	if err != nil {
+		return Point{}, fmt.Errorf("invalid longitude %q", lng)
+	}
+
+	point := Point{Latitude: latitude, Longitude: longitude}
+	if err := point.validate(); err != nil {
+		return Point{}, err
+	}
+	return point, nil
+}
+
+func (p Point) validate() error {
+	if !(p.Latitude >= -90 && p.Latitude <= 90) {
+		return fmt.Errorf("latitude %v out of range", p.Latitude)
+	}
+	if !(p.Longitude >= -180 && p.Longitude <= 180) {
+		return fmt.Errorf("longitude %v out of range", p.Longitude)
+	}
+	return nil
+}
+
+// looksLikePoint reports whether value is a pair of numbers rather than a place name that
+// happens to contain a comma, such as "Leeds, West Yorkshire".
+func looksLikePoint(value string) bool {
+	lat, lng, ok := strings.Cut(value, ",")
+	if !ok {
+		return false
+	}
+	_, latErr := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	_, lngErr := strconv.ParseFloat(strings.TrimSpace(lng), 64)
+	return latErr == nil && lngErr == nil
+}
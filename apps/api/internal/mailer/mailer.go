@@ -0,0 +1,80 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Message describes a plain-text transactional email.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+}
+
+// Mailer delivers transactional email.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config selects and configures the mail delivery driver.
+type Config struct {
+	Driver       string
+	From         string
+	SMTPAddr     string
+	SMTPUsername string
+	SMTPPassword string
+	FileDir      string
+}
+
+// New builds a Mailer for the configured driver (smtp, file or log).
+func New(cfg Config, logger zerolog.Logger) (Mailer, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Driver)) {
+	case "", "log":
+		return NewLogMailer(logger), nil
+	case "smtp":
+		return NewSMTPMailer(cfg.SMTPAddr, cfg.From, cfg.SMTPUsername, cfg.SMTPPassword)
+	case "file":
+		return NewFileMailer(cfg.FileDir, cfg.From)
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
+
+// LogMailer writes messages to the logger instead of delivering them.
+type LogMailer struct {
+	logger zerolog.Logger
+}
+
+// NewLogMailer constructs a LogMailer.
+func NewLogMailer(logger zerolog.Logger) *LogMailer {
+	return &LogMailer{logger: logger}
+}
+
+// Send logs the message metadata and body.
+func (m *LogMailer) Send(_ context.Context, msg Message) error {
+	m.logger.Info().
+		Str("to", msg.To).
+		Str("subject", msg.Subject).
+		Str("body", msg.Text).
+		Msg("email not delivered (log mailer)")
+	return nil
+}
+
+func render(from string, msg Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(msg.Text)
+	return buf.Bytes()
+}
@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+)
+
+// SMTPMailer delivers messages through an SMTP relay such as Mailhog.
+type SMTPMailer struct {
+	addr string
+	// from is the From header, e.g. "Synergy Vets" <no-reply@synergyvets.com>, and sender the
+	// bare address used as the envelope sender.
+	from     string
+	sender   string
+	username string
+	password string
+}
+
+// NewSMTPMailer constructs an SMTPMailer for the given relay address. from may include a
+// display name.
+func NewSMTPMailer(addr, from, username, password string) (*SMTPMailer, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mail from address %q: %w", from, err)
+	}
+	return &SMTPMailer{
+		addr:     addr,
+		from:     sender.String(),
+		sender:   sender.Address,
+		username: username,
+		password: password,
+	}, nil
+}
+
+// Send delivers the message via SMTP.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		host, _, err := net.SplitHostPort(m.addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", m.username, m.password, host)
+	}
+
+	return smtp.SendMail(m.addr, auth, m.sender, []string{msg.To}, render(m.from, msg))
+}
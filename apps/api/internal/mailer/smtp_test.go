@@ -0,0 +1,121 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+// fakeSMTPServer accepts a single SMTP session and records its commands and message data.
+func fakeSMTPServer(t *testing.T) (addr string, session <-chan []string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	lines := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		text := textproto.NewConn(conn)
+		var received []string
+		defer func() { lines <- received }()
+
+		_ = text.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			received = append(received, line)
+
+			switch command := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); command {
+			case "EHLO", "HELO":
+				_ = text.PrintfLine("250 localhost")
+			case "DATA":
+				_ = text.PrintfLine("354 go ahead")
+				data, err := text.ReadDotLines()
+				if err != nil {
+					return
+				}
+				received = append(received, data...)
+				_ = text.PrintfLine("250 queued")
+			case "QUIT":
+				_ = text.PrintfLine("221 bye")
+				return
+			default:
+				_ = text.PrintfLine("250 ok")
+			}
+		}
+	}()
+	return listener.Addr().String(), lines
+}
+
+func TestSMTPMailerEnvelopeSender(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       string
+		wantSender string
+		wantHeader string
+	}{
+		{
+			name:       "display name",
+			from:       "Synergy Vets <no-reply@synergyvets.com>",
+			wantSender: "MAIL FROM:<no-reply@synergyvets.com>",
+			wantHeader: `From: "Synergy Vets" <no-reply@synergyvets.com>`,
+		},
+		{
+			name:       "bare address",
+			from:       "no-reply@synergyvets.com",
+			wantSender: "MAIL FROM:<no-reply@synergyvets.com>",
+			wantHeader: "From: <no-reply@synergyvets.com>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, session := fakeSMTPServer(t)
+
+			m, err := NewSMTPMailer(addr, tt.from, "", "")
+			if err != nil {
+				t.Fatalf("NewSMTPMailer: %v", err)
+			}
+			err = m.Send(context.Background(), Message{To: "vet@example.com", Subject: "Hello", Text: "Hi"})
+			if err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+
+			lines := <-session
+			if !containsLine(lines, tt.wantSender) {
+				t.Errorf("envelope sender %q not found in session %q", tt.wantSender, lines)
+			}
+			if !containsLine(lines, tt.wantHeader) {
+				t.Errorf("header %q not found in session %q", tt.wantHeader, lines)
+			}
+		})
+	}
+}
+
+func TestNewSMTPMailerRejectsInvalidFrom(t *testing.T) {
+	if _, err := NewSMTPMailer("localhost:25", "Synergy Vets", "", ""); err == nil {
+		t.Fatal("expected an error for a from value without an address")
+	}
+}
+
+func containsLine(lines []string, want string) bool {
+	for _, line := range lines {
+		if strings.HasPrefix(line, want) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,461 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID    = "synergy-vets"
+	testRedirectURL = "https://app.example.test/auth/callback"
+	testCode        = "auth-code"
+	testNonce       = "nonce-123"
+)
+
+// fakeProvider is an OIDC identity provider on an httptest.Server. Its token endpoint checks
+// the code and PKCE verifier and answers with an ID token built from idToken.
+type fakeProvider struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu sync.Mutex
+	// issuer overrides the issuer advertised by discovery.
+	issuer      string
+	keys        map[string]any
+	signingKID  string
+	verifier    string
+	idToken     func(p *fakeProvider) string
+	jwksFetches int
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	t.Helper()
+
+	p := &fakeProvider{t: t, keys: make(map[string]any)}
+	p.addRSAKey("key-1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		issuer := p.issuer
+		p.mu.Unlock()
+		if issuer == "" {
+			issuer = p.server.URL
+		}
+		writeJSON(w, discoveryDocument{
+			Issuer:                issuer,
+			AuthorizationEndpoint: p.server.URL + "/authorize",
+			TokenEndpoint:         p.server.URL + "/token",
+			JWKSURI:               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.jwksFetches++
+		writeJSON(w, p.keySet())
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		p.mu.Lock()
+		verifier := p.verifier
+		p.mu.Unlock()
+
+		if r.PostForm.Get("grant_type") != "authorization_code" ||
+			r.PostForm.Get("code") != testCode ||
+			r.PostForm.Get("client_id") != testClientID ||
+			r.PostForm.Get("redirect_uri") != testRedirectURL ||
+			r.PostForm.Get("code_verifier") != verifier {
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(w, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		writeJSON(w, map[string]string{"access_token": "opaque", "id_token": p.idToken(p)})
+	})
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *fakeProvider) provider() *Provider {
+	return NewProvider(ProviderConfig{
+		Name:        "fake",
+		Issuer:      p.server.URL + "/",
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+	}, p.server.Client())
+}
+
+// addRSAKey publishes a new RSA key and signs subsequent ID tokens with it.
+func (p *fakeProvider) addRSAKey(kid string) {
+	p.t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		p.t.Fatalf("generate rsa key: %v", err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys[kid] = key
+	p.signingKID = kid
+}
+
+func (p *fakeProvider) keySet() jsonWebKeySet {
+	var set jsonWebKeySet
+	for kid, key := range p.keys {
+		switch key := key.(type) {
+		case *rsa.PrivateKey:
+			set.Keys = append(set.Keys, jsonWebKey{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				N:   encodeBigInt(key.N),
+				E:   encodeBigInt(big.NewInt(int64(key.E))),
+			})
+		case *ecdsa.PrivateKey:
+			set.Keys = append(set.Keys, jsonWebKey{
+				Kty: "EC",
+				Kid: kid,
+				Crv: "P-256",
+				X:   encodeBigInt(key.X),
+				Y:   encodeBigInt(key.Y),
+			})
+		}
+	}
+	return set
+}
+
+// claims returns valid ID token claims for the test client, issued now.
+func (p *fakeProvider) claims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            p.server.URL,
+		"sub":            "provider-user-1",
+		"aud":            testClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          testNonce,
+		"email":          " Vet@Example.com ",
+		"email_verified": true,
+		"name":           "Vera Vet",
+	}
+}
+
+// sign signs claims with the current signing key.
+func (p *fakeProvider) sign(claims jwt.MapClaims) string {
+	p.t.Helper()
+
+	p.mu.Lock()
+	kid := p.signingKID
+	key := p.keys[kid]
+	p.mu.Unlock()
+
+	method := jwt.SigningMethod(jwt.SigningMethodRS256)
+	if _, ok := key.(*ecdsa.PrivateKey); ok {
+		method = jwt.SigningMethodES256
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		p.t.Fatalf("sign id token: %v", err)
+	}
+	return signed
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func encodeBigInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+// exchange runs the code exchange against fake with a fresh PKCE verifier.
+func exchange(t *testing.T, fake *fakeProvider, provider *Provider) (Claims, error) {
+	t.Helper()
+
+	verifier, err := NewCodeVerifier()
+	if err != nil {
+		t.Fatalf("code verifier: %v", err)
+	}
+	fake.mu.Lock()
+	fake.verifier = verifier
+	fake.mu.Unlock()
+
+	return provider.Exchange(context.Background(), testCode, verifier, testNonce)
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	fake := newFakeProvider(t)
+
+	raw, err := fake.provider().AuthCodeURL(context.Background(), "state-1", testNonce, "verifier-1")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("parse %q: %v", raw, err)
+	}
+	if got := u.Scheme + "://" + u.Host + u.Path; got != fake.server.URL+"/authorize" {
+		t.Errorf("endpoint = %q, want the discovered authorization endpoint", got)
+	}
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURL,
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 testNonce,
+		"code_challenge":        CodeChallenge("verifier-1"),
+		"code_challenge_method": "S256",
+	}
+	query := u.Query()
+	for key, value := range want {
+		if got := query.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestExchange(t *testing.T) {
+	tests := []struct {
+		name    string
+		idToken func(p *fakeProvider) string
+		want    Claims
+		wantErr error
+	}{
+		{
+			name:    "valid token",
+			idToken: func(p *fakeProvider) string { return p.sign(p.claims()) },
+			want:    Claims{Subject: "provider-user-1", Email: "vet@example.com", EmailVerified: true, Name: "Vera Vet"},
+		},
+		{
+			name: "email_verified as a string",
+			idToken: func(p *fakeProvider) string {
+				claims := p.claims()
+				claims["email_verified"] = "true"
+				return p.sign(claims)
+			},
+			want: Claims{Subject: "provider-user-1", Email: "vet@example.com", EmailVerified: true, Name: "Vera Vet"},
+		},
+		{
+			name: "unverified email",
+			idToken: func(p *fakeProvider) string {
+				claims := p.claims()
+				delete(claims, "email_verified")
+				return p.sign(claims)
+			},
+			want: Claims{Subject: "provider-user-1", Email: "vet@example.com", Name: "Vera Vet"},
+		},
+		{
+			name: "multiple audiences with matching azp",
+			idToken: func(p *fakeProvider) string {
+				claims := p.claims()
+				claims["aud"] = []string{testClientID, "other-client"}
+				claims["azp"] = testClientID
+				return p.sign(claims)
+			},
+			want: Claims{Subject: "provider-user-1", Email: "vet@example.com", EmailVerified: true, Name: "Vera Vet"},
+		},
+		{
+			name: "multiple audiences without azp",
+			idToken: func(p *fakeProvider) string {
+				claims := p.claims()
+				claims["aud"] = []string{testClientID, "other-client"}
+				return p.sign(claims)
+			},
+			wantErr: ErrInvalidIDToken,
+		},
+		{
+			name: "wrong nonce",
+			idToken: func(p *fakeProvider) string {
+				claims := p.claims()
+				claims["nonce"] = "replayed"
+				return p.sign(claims)
+			},
+			wantErr: ErrInvalidIDToken,
+		},
+		{
+			name: "wrong audience",
+			idToken: func(p *fakeProvider) string {
+				claims := p.claims()
+				claims["aud"] = "other-client"
+				return p.sign(claims)
+			},
+			wantErr: ErrInvalidIDToken,
+		},
+		{
+			name: "wrong issuer",
+			idToken: func(p *fakeProvider) string {
+				claims := p.claims()
+				claims["iss"] = "https://evil.example.test"
+				return p.sign(claims)
+			},
+			wantErr: ErrInvalidIDToken,
+		},
+		{
+			name: "expired",
+			idToken: func(p *fakeProvider) string {
+				claims := p.claims()
+				claims["exp"] = time.Now().Add(-2 * clockSkew).Unix()
+				return p.sign(claims)
+			},
+			wantErr: ErrInvalidIDToken,
+		},
+		{
+			name: "missing subject",
+			idToken: func(p *fakeProvider) string {
+				claims := p.claims()
+				delete(claims, "sub")
+				return p.sign(claims)
+			},
+			wantErr: ErrInvalidIDToken,
+		},
+		{
+			name: "signed by an unpublished key",
+			idToken: func(p *fakeProvider) string {
+				key, err := rsa.GenerateKey(rand.Reader, 2048)
+				if err != nil {
+					p.t.Fatalf("generate rsa key: %v", err)
+				}
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, p.claims())
+				token.Header["kid"] = p.signingKID
+				signed, err := token.SignedString(key)
+				if err != nil {
+					p.t.Fatalf("sign id token: %v", err)
+				}
+				return signed
+			},
+			wantErr: ErrInvalidIDToken,
+		},
+		{
+			name: "unsigned token",
+			idToken: func(p *fakeProvider) string {
+				signed, err := jwt.NewWithClaims(jwt.SigningMethodNone, p.claims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+				if err != nil {
+					p.t.Fatalf("sign id token: %v", err)
+				}
+				return signed
+			},
+			wantErr: ErrInvalidIDToken,
+		},
+		{
+			name:    "no id token",
+			idToken: func(p *fakeProvider) string { return "" },
+			wantErr: ErrExchangeFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeProvider(t)
+			fake.idToken = tt.idToken
+
+			got, err := exchange(t, fake, fake.provider())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("claims = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExchangeRejectedCode(t *testing.T) {
+	fake := newFakeProvider(t)
+	fake.idToken = func(p *fakeProvider) string { return p.sign(p.claims()) }
+	fake.verifier = "expected-verifier"
+
+	_, err := fake.provider().Exchange(context.Background(), testCode, "other-verifier", testNonce)
+	if !errors.Is(err, ErrExchangeFailed) {
+		t.Fatalf("got %v, want %v", err, ErrExchangeFailed)
+	}
+	if !strings.Contains(err.Error(), "invalid_grant") {
+		t.Errorf("error %q does not include the provider response", err)
+	}
+}
+
+func TestExchangeECDSAKey(t *testing.T) {
+	fake := newFakeProvider(t)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ecdsa key: %v", err)
+	}
+	fake.keys["ec-1"] = key
+	fake.signingKID = "ec-1"
+	fake.idToken = func(p *fakeProvider) string { return p.sign(p.claims()) }
+
+	if _, err := exchange(t, fake, fake.provider()); err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+}
+
+func TestSigningKeyRotation(t *testing.T) {
+	fake := newFakeProvider(t)
+	fake.idToken = func(p *fakeProvider) string { return p.sign(p.claims()) }
+
+	now := time.Now()
+	provider := fake.provider().WithNow(func() time.Time { return now })
+
+	if _, err := exchange(t, fake, provider); err != nil {
+		t.Fatalf("first exchange: %v", err)
+	}
+
+	// A token signed by a key published after the last fetch is refused until the refetch
+	// interval has passed, then the new key set is loaded.
+	fake.addRSAKey("key-2")
+	now = now.Add(keysetRefreshInterval / 2)
+	if _, err := exchange(t, fake, provider); !errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("exchange within the refetch interval: got %v, want %v", err, ErrInvalidIDToken)
+	}
+
+	now = now.Add(keysetRefreshInterval)
+	if _, err := exchange(t, fake, provider); err != nil {
+		t.Fatalf("exchange after rotation: %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.jwksFetches != 2 {
+		t.Errorf("jwks fetched %d times, want 2", fake.jwksFetches)
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	fake := newFakeProvider(t)
+	fake.issuer = "https://evil.example.test"
+
+	if _, err := fake.provider().AuthCodeURL(context.Background(), "state", testNonce, "verifier"); err == nil {
+		t.Fatal("expected discovery to fail for a mismatched issuer")
+	}
+}
@@ -0,0 +1,113 @@
+package jobs
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+// ErrInvalidCursor indicates the pagination cursor could not be decoded or belongs to a
+// different sort.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursor marks a position in the job ordering, see queries.ListPublishedJobs. Clients receive
+// it as an opaque string. Keying on the row rather than an offset means jobs inserted while
+// someone pages do not shift later pages.
+type cursor struct {
+	// Backward asks for the page before the position rather than after it.
+	Backward bool   `json:"b,omitempty"`
+	Sort     string `json:"o"`
+	// SortValue is nil for jobs that sort last, such as jobs without a salary.
+	SortValue *float64   `json:"s,omitempty"`
+	PostedAt  *time.Time `json:"p,omitempty"`
+	CreatedAt time.Time  `json:"c"`
+	ID        uuid.UUID  `json:"i"`
+	// PostedAfter is the posted_within cutoff of the first page, so later pages keep it
+	// rather than sliding it forward.
+	PostedAfter *time.Time `json:"a,omitempty"`
+}
+
+// cursorAfter returns the cursor for the page following row in the listing arg selects.
+func cursorAfter(row queries.ListPublishedJobsRow, arg queries.ListPublishedJobsParams) cursor {
+	c := cursor{
+		Sort:      arg.Sort,
+		CreatedAt: row.CreatedAt,
+		ID:        row.ID,
+	}
+	if arg.PostedAfter.Valid {
+		postedAfter := arg.PostedAfter.Time
+		c.PostedAfter = &postedAfter
+	}
+	if !math.IsInf(row.SortValue, -1) {
+		sortValue := row.SortValue
+		c.SortValue = &sortValue
+	}
+	if row.PostedAt.Valid {
+		postedAt := row.PostedAt.Time
+		c.PostedAt = &postedAt
+	}
+	return c
+}
+
+// cursorBefore returns the cursor for the page preceding row in the listing arg selects.
+func cursorBefore(row queries.ListPublishedJobsRow, arg queries.ListPublishedJobsParams) cursor {
+	c := cursorAfter(row, arg)
+	c.Backward = true
+	return c
+}
+
+// pageCursors returns the cursors of the pages either side of rows, the page arg selected in
+// display order. more reports whether the query found rows beyond the page.
+func pageCursors(rows []queries.ListPublishedJobsRow, arg queries.ListPublishedJobsParams, more bool) (next, prev string) {
+	if len(rows) == 0 {
+		return "", ""
+	}
+
+	first, last := rows[0], rows[len(rows)-1]
+	// Paging backward always leaves the page it started from to come back to.
+	if more || arg.Backward {
+		next = cursorAfter(last, arg).encode()
+	}
+	if (more && arg.Backward) || (!arg.Backward && (arg.CursorID.Valid || arg.OffsetRows > 0)) {
+		prev = cursorBefore(first, arg).encode()
+	}
+	return next, prev
+}
+
+func decodeCursor(value string) (cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil || c.CreatedAt.IsZero() {
+		return cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+func (c cursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// apply restricts the query to the rows after (or before) the cursor.
+func (c cursor) apply(arg *queries.ListPublishedJobsParams) {
+	arg.CursorID = uuid.NullUUID{UUID: c.ID, Valid: true}
+	arg.CursorCreatedAt = sql.NullTime{Time: c.CreatedAt, Valid: true}
+	if c.SortValue != nil {
+		arg.CursorSortValue = sql.NullFloat64{Float64: *c.SortValue, Valid: true}
+	}
+	if c.PostedAt != nil {
+		arg.CursorPostedAt = sql.NullTime{Time: *c.PostedAt, Valid: true}
+	}
+	arg.Backward = c.Backward
+}
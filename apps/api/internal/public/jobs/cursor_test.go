@@ -0,0 +1,187 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/synergyvets/platform/internal/geo"
+	"github.com/synergyvets/platform/internal/queries"
+)
+
+func TestCursorKeepsPostedAfter(t *testing.T) {
+	cutoff := time.Date(2026, 10, 10, 12, 0, 0, 0, time.UTC)
+	row := queries.ListPublishedJobsRow{ID: uuid.New(), CreatedAt: cutoff.Add(time.Hour)}
+	arg := queries.ListPublishedJobsParams{
+		Sort:        SortNewest,
+		PostedAfter: sql.NullTime{Time: cutoff, Valid: true},
+	}
+
+	for name, c := range map[string]cursor{"after": cursorAfter(row, arg), "before": cursorBefore(row, arg)} {
+		decoded, err := decodeCursor(c.encode())
+		if err != nil {
+			t.Fatalf("%s: decode: %v", name, err)
+		}
+		if decoded.PostedAfter == nil || !decoded.PostedAfter.Equal(cutoff) {
+			t.Errorf("%s: PostedAfter = %v, want %v", name, decoded.PostedAfter, cutoff)
+		}
+	}
+
+	arg.PostedAfter = sql.NullTime{}
+	if c := cursorAfter(row, arg); c.PostedAfter != nil {
+		t.Errorf("cursor without posted_within has PostedAfter %v", c.PostedAfter)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	postedAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	createdAt := postedAt.Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		row           queries.ListPublishedJobsRow
+		sort          string
+		wantSortValue *float64
+		wantPostedAt  *time.Time
+	}{
+		{
+			name:          "salary",
+			row:           queries.ListPublishedJobsRow{SortValue: 42000, PostedAt: sql.NullTime{Time: postedAt, Valid: true}},
+			sort:          SortSalaryDesc,
+			wantSortValue: ptr(42000.0),
+			wantPostedAt:  &postedAt,
+		},
+		{
+			name: "sorts last",
+			row:  queries.ListPublishedJobsRow{SortValue: math.Inf(-1), PostedAt: sql.NullTime{Time: postedAt, Valid: true}},
+			sort: SortSalaryAsc,
+			// -Inf does not survive JSON, so it travels as a missing value.
+			wantPostedAt: &postedAt,
+		},
+		{
+			name:          "never posted",
+			row:           queries.ListPublishedJobsRow{SortValue: 0.5},
+			sort:          SortRelevance,
+			wantSortValue: ptr(0.5),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.row.ID = uuid.New()
+			tt.row.CreatedAt = createdAt
+
+			c := cursorAfter(tt.row, queries.ListPublishedJobsParams{Sort: tt.sort})
+			decoded, err := decodeCursor(c.encode())
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if decoded.Sort != tt.sort || decoded.ID != tt.row.ID || !decoded.CreatedAt.Equal(createdAt) || decoded.Backward {
+				t.Errorf("decoded = %+v", decoded)
+			}
+			if !equalPtr(decoded.SortValue, tt.wantSortValue, func(a, b float64) bool { return a == b }) {
+				t.Errorf("SortValue = %v, want %v", decoded.SortValue, tt.wantSortValue)
+			}
+			if !equalPtr(decoded.PostedAt, tt.wantPostedAt, time.Time.Equal) {
+				t.Errorf("PostedAt = %v, want %v", decoded.PostedAt, tt.wantPostedAt)
+			}
+
+			var arg queries.ListPublishedJobsParams
+			decoded.apply(&arg)
+			if arg.CursorID.UUID != tt.row.ID || arg.CursorSortValue.Valid != (tt.wantSortValue != nil) || arg.CursorPostedAt.Valid != (tt.wantPostedAt != nil) {
+				t.Errorf("applied cursor = %+v", arg)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejectsMalformed(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("not json")),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"o":"newest","c":"2026-10-01T09:00:00Z"}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"o":"newest","i":"` + uuid.NewString() + `"}`)),
+	} {
+		if _, err := decodeCursor(value); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("decodeCursor(%q): got %v, want %v", value, err, ErrInvalidCursor)
+		}
+	}
+}
+
+func TestListPublishedJobsRejectsCursorOfOtherSort(t *testing.T) {
+	// The sort is checked before the database is queried.
+	s := &Service{places: geo.UK()}
+	row := queries.ListPublishedJobsRow{ID: uuid.New(), CreatedAt: time.Now()}
+	value := cursorAfter(row, queries.ListPublishedJobsParams{Sort: SortSalaryDesc}).encode()
+
+	_, err := s.ListPublishedJobs(context.Background(), ListParams{Cursor: value, Sort: SortNewest})
+	if !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("got %v, want %v", err, ErrInvalidCursor)
+	}
+}
+
+func TestPageCursors(t *testing.T) {
+	rows := []queries.ListPublishedJobsRow{
+		{ID: uuid.New(), CreatedAt: time.Now()},
+		{ID: uuid.New(), CreatedAt: time.Now()},
+	}
+	position := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+
+	tests := []struct {
+		name               string
+		rows               []queries.ListPublishedJobsRow
+		arg                queries.ListPublishedJobsParams
+		more               bool
+		wantNext, wantPrev bool
+	}{
+		{name: "only page", rows: rows},
+		{name: "first page", rows: rows, more: true, wantNext: true},
+		{name: "offset page", rows: rows, arg: queries.ListPublishedJobsParams{OffsetRows: 20}, wantPrev: true},
+		{name: "forward, more", rows: rows, arg: queries.ListPublishedJobsParams{CursorID: position}, more: true, wantNext: true, wantPrev: true},
+		{name: "forward, last", rows: rows, arg: queries.ListPublishedJobsParams{CursorID: position}, wantPrev: true},
+		{name: "backward, more", rows: rows, arg: queries.ListPublishedJobsParams{CursorID: position, Backward: true}, more: true, wantNext: true, wantPrev: true},
+		{name: "backward, first", rows: rows, arg: queries.ListPublishedJobsParams{CursorID: position, Backward: true}, wantNext: true},
+		{name: "empty", arg: queries.ListPublishedJobsParams{CursorID: position}, more: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.arg.Sort = SortNewest
+			next, prev := pageCursors(tt.rows, tt.arg, tt.more)
+			if (next != "") != tt.wantNext || (prev != "") != tt.wantPrev {
+				t.Fatalf("next %q, prev %q; want next %v, prev %v", next, prev, tt.wantNext, tt.wantPrev)
+			}
+
+			if next != "" {
+				c, err := decodeCursor(next)
+				if err != nil || c.Backward || c.ID != tt.rows[len(tt.rows)-1].ID {
+					t.Errorf("next cursor = %+v, %v; want forward from the last row", c, err)
+				}
+			}
+			if prev != "" {
+				c, err := decodeCursor(prev)
+				if err != nil || !c.Backward || c.ID != tt.rows[0].ID {
+					t.Errorf("prev cursor = %+v, %v; want backward from the first row", c, err)
+				}
+			}
+		})
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func equalPtr[T any](a, b *T, equal func(T, T) bool) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return equal(*a, *b)
+}
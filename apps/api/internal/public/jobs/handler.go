@@ -3,8 +3,12 @@ package jobs
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -27,32 +31,31 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 
 func (h *Handler) handleListJobs(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	query := r.URL.Query()
 
-	page := parseInt(query.Get("page"), 1)
-	pageSize := parseInt(query.Get("page_size"), 20)
-
-	params := ListParams{
-		Page:          page,
-		PageSize:      pageSize,
-		Search:        query.Get("q"),
-		Countries:     query["country"],
-		Regions:       query["region"],
-		ContractTypes: query["contract_type"],
-		Categories:    query["category"],
+	params, err := parseListParams(r.URL.Query())
+	var result ListResult
+	if err == nil {
+		result, err = h.service.ListPublishedJobs(ctx, params)
 	}
 
-	result, err := h.service.ListPublishedJobs(ctx, params)
-	if err != nil {
+	var invalid *ValidationError
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, result)
+	case errors.As(err, &invalid):
+		writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":  "invalid query parameters",
+			"fields": invalid.Fields,
+		})
+	case errors.Is(err, ErrInvalidCursor):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
 		// Log the error for debugging
 		// Ideally use a logger, but fmt.Println is fine for now if logger not available in struct
 		// But we don't have logger in Handler struct.
 		// Let's just return the error message in the response for now to see it in curl.
 		writeError(w, http.StatusInternalServerError, "failed to load jobs: "+err.Error())
-		return
 	}
-
-	writeJSON(w, http.StatusOK, result)
 }
 
 func (h *Handler) handleGetJob(w http.ResponseWriter, r *http.Request) {
@@ -72,16 +75,125 @@ func (h *Handler) handleGetJob(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, job)
 }
 
-func parseInt(value string, fallback int) int {
+// parseListParams reads the job list query string. Malformed values are reported as a
+// *ValidationError rather than replaced by defaults.
+func parseListParams(query url.Values) (ListParams, error) {
+	var problems ValidationError
+
+	params := ListParams{
+		Cursor:        query.Get("cursor"),
+		Search:        query.Get("q"),
+		Countries:     query["country"],
+		Regions:       query["region"],
+		ContractTypes: query["contract_type"],
+		Categories:    query["category"],
+		WorkPatterns:  query["work_pattern"],
+		Currency:      query.Get("currency"),
+		Near:          query.Get("near"),
+		Sort:          query.Get("sort"),
+	}
+
+	params.Page = parseIntParam(query, "page", 1, 1, 0, &problems)
+	params.PageSize = parseIntParam(query, "page_size", 20, 1, 100, &problems)
+
+	if value := query.Get("include_total"); value != "" {
+		includeTotal, err := strconv.ParseBool(value)
+		if err != nil {
+			problems.Add("include_total", "must be true or false")
+		}
+		params.IncludeTotal = includeTotal
+	}
+
+	for _, name := range []string{"salary_min", "salary_max"} {
+		value := strings.TrimSpace(query.Get(name))
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || parsed < 0 {
+			problems.Add(name, "must be a non-negative whole number")
+			continue
+		}
+		salary := int32(parsed)
+		if name == "salary_min" {
+			params.SalaryMin = &salary
+		} else {
+			params.SalaryMax = &salary
+		}
+	}
+
+	if value := strings.TrimSpace(query.Get("radius_km")); value != "" {
+		radius, err := strconv.ParseFloat(value, 64)
+		if err != nil || !(radius > 0) {
+			problems.Add("radius_km", "must be a positive number of kilometres")
+		}
+		params.RadiusKm = radius
+	}
+
+	if value := strings.TrimSpace(query.Get("posted_within")); value != "" {
+		within, err := parsePostedWithin(value)
+		if err != nil {
+			problems.Add("posted_within", err.Error())
+		}
+		params.PostedWithin = within
+	}
+
+	return params, problems.Err()
+}
+
+// parseIntParam reads an optional integer parameter, which must be at least min and, when max
+// is positive, at most max.
+func parseIntParam(query url.Values, name string, fallback, min, max int, problems *ValidationError) int {
+	value := strings.TrimSpace(query.Get(name))
 	if value == "" {
 		return fallback
 	}
 
 	parsed, err := strconv.Atoi(value)
-	if err != nil {
-		return fallback
+	switch {
+	case err != nil:
+		problems.Add(name, "must be a whole number")
+	case parsed < min:
+		problems.Add(name, fmt.Sprintf("must be at least %d", min))
+	case max > 0 && parsed > max:
+		problems.Add(name, fmt.Sprintf("must be at most %d", max))
+	default:
+		return parsed
+	}
+	return fallback
+}
+
+// maxPostedWithin bounds posted_within so the cutoff cannot overflow.
+const maxPostedWithin = 366 * 24 * time.Hour
+
+// parsePostedWithin parses an age such as "24h", "7d" or "2w".
+func parsePostedWithin(value string) (time.Duration, error) {
+	invalid := errors.New("must be a number of hours, days or weeks, such as 24h, 7d or 2w")
+	if len(value) < 2 {
+		return 0, invalid
+	}
+
+	count, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || count < 1 {
+		return 0, invalid
+	}
+
+	var unit time.Duration
+	switch value[len(value)-1] {
+	case 'h', 'H':
+		unit = time.Hour
+	case 'd', 'D':
+		unit = 24 * time.Hour
+	case 'w', 'W':
+		unit = 7 * 24 * time.Hour
+	default:
+		return 0, invalid
+	}
+
+	if count > int(maxPostedWithin/unit) {
+		return 0, errors.New("must be at most a year")
 	}
-	return parsed
+	return time.Duration(count) * unit, nil
 }
 
 func writeJSON(w http.ResponseWriter, status int, payload any) {
@@ -0,0 +1,125 @@
+package jobs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/synergyvets/platform/internal/geo"
+)
+
+func TestParsePostedWithin(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "24h", want: 24 * time.Hour},
+		{value: "7d", want: 7 * 24 * time.Hour},
+		{value: "2W", want: 14 * 24 * time.Hour},
+		{value: "366d", want: maxPostedWithin},
+		{value: "0d", wantErr: true},
+		{value: "-1d", wantErr: true},
+		{value: "400d", wantErr: true},
+		{value: "53w", wantErr: true},
+		{value: "7x", wantErr: true},
+		{value: "d", wantErr: true},
+		{value: "7", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parsePostedWithin(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parsePostedWithin(%q) = %v, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("parsePostedWithin(%q) = %v, %v, want %v", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
+
+// TestListJobsValidation checks that invalid query parameters are answered with 400 and the
+// offending fields. None of them reach the database.
+func TestListJobsValidation(t *testing.T) {
+	handler := NewHandler(&Service{places: geo.UK()})
+
+	tests := []struct {
+		name       string
+		query      string
+		wantFields []string
+	}{
+		{name: "bad salary_min", query: "salary_min=lots", wantFields: []string{"salary_min"}},
+		{name: "negative salary_max", query: "salary_max=-1", wantFields: []string{"salary_max"}},
+		{name: "salary_min above salary_max", query: "salary_min=50000&salary_max=40000", wantFields: []string{"salary_max"}},
+		{name: "invalid currency", query: "currency=pounds", wantFields: []string{"currency"}},
+		{name: "posted_within zero", query: "posted_within=0d", wantFields: []string{"posted_within"}},
+		{name: "posted_within over a year", query: "posted_within=400d", wantFields: []string{"posted_within"}},
+		{name: "posted_within unknown unit", query: "posted_within=7x", wantFields: []string{"posted_within"}},
+		{name: "unknown sort", query: "sort=cheapest", wantFields: []string{"sort"}},
+		{name: "distance without near", query: "sort=distance", wantFields: []string{"sort"}},
+		{name: "radius without near", query: "radius_km=10", wantFields: []string{"radius_km"}},
+		{name: "unknown place", query: "near=Atlantis", wantFields: []string{"near"}},
+		{name: "bad page", query: "page=0&page_size=500", wantFields: []string{"page", "page_size"}},
+		{name: "bad include_total", query: "include_total=maybe", wantFields: []string{"include_total"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.handleListJobs(recorder, httptest.NewRequest(http.MethodGet, "/jobs?"+tt.query, nil))
+
+			if recorder.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusBadRequest, recorder.Body)
+			}
+			var body struct {
+				Fields map[string]string `json:"fields"`
+			}
+			if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if len(body.Fields) != len(tt.wantFields) {
+				t.Errorf("fields = %v, want %v", body.Fields, tt.wantFields)
+			}
+			for _, field := range tt.wantFields {
+				if body.Fields[field] == "" {
+					t.Errorf("fields = %v, missing %s", body.Fields, field)
+				}
+			}
+		})
+	}
+}
+
+func TestParseListParams(t *testing.T) {
+	query := url.Values{
+		"q":             {"small animal"},
+		"country":       {"GB", "IE"},
+		"salary_min":    {"30000"},
+		"salary_max":    {"45000"},
+		"posted_within": {"2w"},
+		"radius_km":     {"12.5"},
+		"page":          {"3"},
+		"page_size":     {"50"},
+	}
+
+	params, err := parseListParams(query)
+	if err != nil {
+		t.Fatalf("parseListParams: %v", err)
+	}
+	if params.Search != "small animal" || len(params.Countries) != 2 || params.Page != 3 || params.PageSize != 50 {
+		t.Errorf("params = %+v", params)
+	}
+	if params.SalaryMin == nil || *params.SalaryMin != 30000 || params.SalaryMax == nil || *params.SalaryMax != 45000 {
+		t.Errorf("salary = %v..%v, want 30000..45000", params.SalaryMin, params.SalaryMax)
+	}
+	if params.PostedWithin != 14*24*time.Hour || params.RadiusKm != 12.5 {
+		t.Errorf("posted_within = %v, radius_km = %v", params.PostedWithin, params.RadiusKm)
+	}
+}
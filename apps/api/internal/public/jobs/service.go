@@ -4,34 +4,115 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"html"
+	"math"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/synergyvets/platform/internal/geo"
 	"github.com/synergyvets/platform/internal/queries"
 	"github.com/synergyvets/platform/internal/store"
 )
 
 // Service exposes read-only job listings for public consumers.
 type Service struct {
-	store *store.Store
+	store  *store.Store
+	places *geo.Gazetteer
 }
 
-// NewService constructs a Service backed by the shared Store.
+// NewService constructs a Service backed by the shared Store. Place names are resolved with
+// the bundled UK gazetteer.
 func NewService(store *store.Store) *Service {
-	return &Service{store: store}
+	return &Service{store: store, places: geo.UK()}
 }
 
-// ListParams controls pagination and filtering of published jobs.
+// Sort orders for ListParams.Sort.
+const (
+	SortRelevance  = "relevance"
+	SortNewest     = "newest"
+	SortSalaryDesc = "salary_desc"
+	SortSalaryAsc  = "salary_asc"
+	SortDistance   = "distance"
+)
+
+// MaxRadiusKm bounds ListParams.RadiusKm.
+const MaxRadiusKm = 1000
+
+// MinPostcodeAreaRadiusKm is the smallest ListParams.RadiusKm allowed when Near resolves to the
+// centre of a postcode area, which is too coarse to filter a few kilometres around.
+const MinPostcodeAreaRadiusKm = 25
+
+// DefaultCurrency is assumed for salary filters without a currency and for jobs imported
+// without one.
+const DefaultCurrency = "GBP"
+
+// ListParams controls pagination and filtering of published jobs. A Cursor from a previous
+// ListResult takes precedence over Page, and is only valid for the Sort it was issued for.
 type ListParams struct {
-	Page          int
-	PageSize      int
+	Page     int
+	PageSize int
+	Cursor   string
+	// IncludeTotal counts every matching job when paging by cursor; page-based requests always
+	// include the total.
+	IncludeTotal  bool
 	Search        string
 	Countries     []string
 	Regions       []string
 	ContractTypes []string
 	Categories    []string
+	WorkPatterns  []string
+	// SalaryMin and SalaryMax match jobs whose salary band overlaps the range, in Currency.
+	SalaryMin *int32
+	SalaryMax *int32
+	// Currency restricts jobs to those paid in the ISO 4217 code; it defaults to
+	// DefaultCurrency when a salary bound is set.
+	Currency string
+	// PostedWithin restricts jobs to those posted in the last duration.
+	PostedWithin time.Duration
+	// Near is "latitude,longitude", a UK postcode or a place name. Results then carry their
+	// distance from it, and RadiusKm, when positive, excludes jobs further away or without a
+	// known location.
+	Near     string
+	RadiusKm float64
+	// Sort defaults to SortRelevance when searching, SortDistance with Near and SortNewest
+	// otherwise.
+	Sort string
+}
+
+// ValidationError reports invalid list parameters, keyed by query parameter name.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return fmt.Sprintf("invalid parameters: %s", strings.Join(names, ", "))
+}
+
+// Add records a problem with the named parameter, keeping the first one reported.
+func (e *ValidationError) Add(field, message string) {
+	if e.Fields == nil {
+		e.Fields = make(map[string]string)
+	}
+	if _, exists := e.Fields[field]; !exists {
+		e.Fields[field] = message
+	}
+}
+
+// Err returns e, or nil when no problems were recorded.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
 }
 
 // Job represents a job listing for public consumption.
@@ -49,6 +130,30 @@ type Job struct {
 	PostedAt     *string   `json:"posted_at,omitempty"`
 	ExpiresAt    *string   `json:"expires_at,omitempty"`
 	Location     Location  `json:"location"`
+	// DistanceKm is only set when listing near a point and the job's location is known.
+	DistanceKm *float64 `json:"distance_km,omitempty"`
+	// Highlight is only set for search results.
+	Highlight *Highlight `json:"highlight,omitempty"`
+}
+
+// Highlight shows where a search matched a job, with matching words wrapped in <mark> tags.
+// The text is HTML-escaped, so the <mark> tags are the only markup.
+type Highlight struct {
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+}
+
+// Delimiters ListPublishedJobs puts around matches in its headlines.
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+)
+
+var highlightTags = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+// markHighlights HTML-escapes a headline and only then turns its match delimiters into tags.
+func markHighlights(headline string) string {
+	return highlightTags.Replace(html.EscapeString(headline))
 }
 
 // JobDetail adds metadata fields for a single job response.
@@ -70,17 +175,28 @@ type Location struct {
 	City    *string `json:"city,omitempty"`
 }
 
-// ListResult returns the paginated job listings.
+// ListResult returns the paginated job listings. NextCursor and PrevCursor are set when there
+// are more jobs after or before this page.
 type ListResult struct {
-	Jobs     []Job `json:"jobs"`
-	Page     int   `json:"page"`
-	PageSize int   `json:"page_size"`
-	Total    int64 `json:"total"`
-	HasMore  bool  `json:"has_more"`
+	Jobs       []Job  `json:"jobs"`
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	Total      *int64 `json:"total,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
-// ListPublishedJobs retrieves published jobs based on the provided filters.
+// ListPublishedJobs retrieves published jobs based on the provided filters. A search term is
+// matched against the jobs' full-text index and by default orders results by relevance, most
+// recent first among equals; without one jobs are ordered by posting date. Invalid filters
+// are reported as a *ValidationError.
 func (s *Service) ListPublishedJobs(ctx context.Context, params ListParams) (ListResult, error) {
+	filters, err := params.filters(s.places, time.Now())
+	if err != nil {
+		return ListResult{}, err
+	}
+
 	page := params.Page
 	if page < 1 {
 		page = 1
@@ -94,39 +210,67 @@ func (s *Service) ListPublishedJobs(ctx context.Context, params ListParams) (Lis
 		pageSize = 100
 	}
 
+	var (
+		position  cursor
+		hasCursor bool
+	)
+	if value := strings.TrimSpace(params.Cursor); value != "" {
+		if position, err = decodeCursor(value); err != nil {
+			return ListResult{}, err
+		}
+		if position.Sort != filters.Sort {
+			return ListResult{}, ErrInvalidCursor
+		}
+		if position.PostedAfter != nil && filters.PostedAfter.Valid {
+			filters.PostedAfter.Time = *position.PostedAfter
+		}
+		hasCursor = true
+	}
+
 	offset := int32((page - 1) * pageSize)
-	limit := int32(pageSize)
-
-	search := sql.NullString{}
-	if trimmed := strings.TrimSpace(params.Search); trimmed != "" {
-		search = sql.NullString{String: trimmed, Valid: true}
-	}
-
-	countries := normalizeList(params.Countries)
-	regions := normalizeList(params.Regions)
-	contractTypes := normalizeList(params.ContractTypes)
-	categories := normalizeList(params.Categories)
-
-	rows, err := s.store.Queries().ListPublishedJobs(ctx, queries.ListPublishedJobsParams{
-		Search:        search,
-		Countries:     countries,
-		Regions:       regions,
-		ContractTypes: contractTypes,
-		Categories:    categories,
-		OffsetRows:    offset,
-		LimitRows:     limit,
-	})
+	if hasCursor {
+		offset = 0
+	}
+
+	arg := filters
+	arg.OffsetRows = offset
+	// One extra row tells whether another page follows.
+	arg.LimitRows = int32(pageSize + 1)
+	if hasCursor {
+		position.apply(&arg)
+	}
+
+	rows, err := s.store.Queries().ListPublishedJobs(ctx, arg)
 	if err != nil {
 		return ListResult{}, err
 	}
 
-	jobs := make([]Job, 0, len(rows))
-	var total int64
-	for _, row := range rows {
-		if total == 0 {
-			total = row.TotalCount
+	result := ListResult{PageSize: pageSize}
+	if !hasCursor {
+		result.Page = page
+	}
+	if !hasCursor || params.IncludeTotal {
+		total, err := s.store.Queries().CountPublishedJobs(ctx, countParams(filters))
+		if err != nil {
+			return ListResult{}, err
 		}
+		result.Total = &total
+	}
 
+	more := len(rows) > pageSize
+	if more {
+		rows = rows[:pageSize]
+	}
+	if arg.Backward {
+		// Rows before the cursor come back closest first.
+		slices.Reverse(rows)
+	}
+
+	result.NextCursor, result.PrevCursor = pageCursors(rows, arg, more)
+	result.HasMore = result.NextCursor != ""
+
+	jobs := make([]Job, 0, len(rows))
+	for _, row := range rows {
 		job := Job{
 			ID:          row.ID,
 			Title:       row.Title,
@@ -171,19 +315,24 @@ func (s *Service) ListPublishedJobs(ctx context.Context, params ListParams) (Lis
 			value := row.ExpiresAt.Time.UTC().Format(time.RFC3339)
 			job.ExpiresAt = &value
 		}
+		if row.DistanceKm.Valid {
+			value := math.Round(row.DistanceKm.Float64*10) / 10
+			job.DistanceKm = &value
+		}
+		if arg.Search.Valid && row.TitleHighlight.Valid {
+			// The snippet comes from the HTML description with its tags stripped, so its
+			// entities are decoded before escaping instead of being escaped twice.
+			job.Highlight = &Highlight{
+				Title:   markHighlights(row.TitleHighlight.String),
+				Snippet: markHighlights(html.UnescapeString(row.Snippet.String)),
+			}
+		}
 
 		jobs = append(jobs, job)
 	}
 
-	hasMore := int64(offset)+int64(len(jobs)) < total
-
-	return ListResult{
-		Jobs:     jobs,
-		Page:     page,
-		PageSize: pageSize,
-		Total:    total,
-		HasMore:  hasMore,
-	}, nil
+	result.Jobs = jobs
+	return result, nil
 }
 
 // GetPublishedJob retrieves a single published job by its slug or ID.
@@ -287,6 +436,136 @@ func (s *Service) GetPublishedJob(ctx context.Context, slugOrID string) (JobDeta
 	return detail, nil
 }
 
+// countParams selects the filters of arg that CountPublishedJobs takes.
+func countParams(arg queries.ListPublishedJobsParams) queries.CountPublishedJobsParams {
+	return queries.CountPublishedJobsParams{
+		Search:        arg.Search,
+		NearLatitude:  arg.NearLatitude,
+		NearLongitude: arg.NearLongitude,
+		Countries:     arg.Countries,
+		Regions:       arg.Regions,
+		ContractTypes: arg.ContractTypes,
+		Categories:    arg.Categories,
+		WorkPatterns:  arg.WorkPatterns,
+		Currency:      arg.Currency,
+		SalaryMin:     arg.SalaryMin,
+		SalaryMax:     arg.SalaryMax,
+		PostedAfter:   arg.PostedAfter,
+		RadiusKm:      arg.RadiusKm,
+	}
+}
+
+// filters validates the search and filter parameters and converts them to query arguments,
+// resolving Near with places and counting PostedWithin back from now.
+func (p ListParams) filters(places *geo.Gazetteer, now time.Time) (queries.ListPublishedJobsParams, error) {
+	var problems ValidationError
+
+	arg := queries.ListPublishedJobsParams{
+		Countries:     normalizeList(p.Countries),
+		Regions:       normalizeList(p.Regions),
+		ContractTypes: normalizeList(p.ContractTypes),
+		Categories:    normalizeList(p.Categories),
+		WorkPatterns:  normalizeList(p.WorkPatterns),
+	}
+	if trimmed := strings.TrimSpace(p.Search); trimmed != "" {
+		arg.Search = sql.NullString{String: trimmed, Valid: true}
+	}
+
+	var nearArea bool
+	if near := strings.TrimSpace(p.Near); near != "" {
+		place, err := places.ResolvePlace(near)
+		switch {
+		case errors.Is(err, geo.ErrUnknownPlace):
+			problems.Add("near", "unknown place; use latitude,longitude, a UK postcode or a town")
+		case err != nil:
+			problems.Add("near", err.Error())
+		default:
+			nearArea = place.Kind == geo.KindPostcodeArea
+			arg.NearLatitude = sql.NullFloat64{Float64: place.Latitude, Valid: true}
+			arg.NearLongitude = sql.NullFloat64{Float64: place.Longitude, Valid: true}
+		}
+	}
+
+	switch {
+	case p.RadiusKm == 0:
+	case !(p.RadiusKm > 0 && p.RadiusKm <= MaxRadiusKm):
+		problems.Add("radius_km", fmt.Sprintf("must be greater than 0 and at most %d", MaxRadiusKm))
+	case strings.TrimSpace(p.Near) == "":
+		problems.Add("radius_km", "requires near")
+	case nearArea && p.RadiusKm < MinPostcodeAreaRadiusKm:
+		problems.Add("radius_km", fmt.Sprintf("must be at least %d when near is a postcode, which resolves to the centre of its postcode area; use latitude,longitude or a town for a smaller radius", MinPostcodeAreaRadiusKm))
+	default:
+		arg.RadiusKm = sql.NullFloat64{Float64: p.RadiusKm, Valid: true}
+	}
+
+	arg.Sort = strings.ToLower(strings.TrimSpace(p.Sort))
+	switch arg.Sort {
+	case "":
+		switch {
+		case arg.Search.Valid:
+			arg.Sort = SortRelevance
+		case arg.NearLatitude.Valid:
+			arg.Sort = SortDistance
+		default:
+			arg.Sort = SortNewest
+		}
+	case SortRelevance, SortNewest, SortSalaryDesc, SortSalaryAsc:
+	case SortDistance:
+		if strings.TrimSpace(p.Near) == "" {
+			problems.Add("sort", "distance requires near")
+		}
+	default:
+		problems.Add("sort", fmt.Sprintf("must be one of %s, %s, %s, %s or %s", SortRelevance, SortNewest, SortSalaryDesc, SortSalaryAsc, SortDistance))
+	}
+
+	if p.SalaryMin != nil {
+		if *p.SalaryMin < 0 {
+			problems.Add("salary_min", "must not be negative")
+		}
+		arg.SalaryMin = sql.NullInt32{Int32: *p.SalaryMin, Valid: true}
+	}
+	if p.SalaryMax != nil {
+		if *p.SalaryMax < 0 {
+			problems.Add("salary_max", "must not be negative")
+		}
+		arg.SalaryMax = sql.NullInt32{Int32: *p.SalaryMax, Valid: true}
+	}
+	if arg.SalaryMin.Valid && arg.SalaryMax.Valid && arg.SalaryMin.Int32 > arg.SalaryMax.Int32 {
+		problems.Add("salary_max", "must not be less than salary_min")
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(p.Currency))
+	if currency == "" && (arg.SalaryMin.Valid || arg.SalaryMax.Valid) {
+		currency = DefaultCurrency
+	}
+	if currency != "" {
+		if !isCurrencyCode(currency) {
+			problems.Add("currency", "must be a three-letter ISO 4217 code")
+		}
+		arg.Currency = sql.NullString{String: currency, Valid: true}
+	}
+
+	if p.PostedWithin < 0 {
+		problems.Add("posted_within", "must not be negative")
+	} else if p.PostedWithin > 0 {
+		arg.PostedAfter = sql.NullTime{Time: now.Add(-p.PostedWithin), Valid: true}
+	}
+
+	return arg, problems.Err()
+}
+
+func isCurrencyCode(value string) bool {
+	if len(value) != 3 {
+		return false
+	}
+	for _, r := range value {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
 func normalizeList(values []string) []string {
 	clean := make([]string, 0, len(values))
 	for _, value := range values {
@@ -0,0 +1,93 @@
+package jobs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/synergyvets/platform/internal/geo"
+)
+
+func TestMarkHighlights(t *testing.T) {
+	tests := []struct {
+		name     string
+		headline string
+		want     string
+	}{
+		{
+			name:     "match",
+			headline: "Small Animal " + highlightStart + "Vet" + highlightStop,
+			want:     "Small Animal <mark>Vet</mark>",
+		},
+		{
+			name:     "markup in the text",
+			headline: "<img src=x onerror=alert(1)> " + highlightStart + "Vet" + highlightStop,
+			want:     "&lt;img src=x onerror=alert(1)&gt; <mark>Vet</mark>",
+		},
+		{
+			name:     "mark tags in the text",
+			headline: "<mark>Vet</mark> & Nurse",
+			want:     "&lt;mark&gt;Vet&lt;/mark&gt; &amp; Nurse",
+		},
+		{
+			name:     "quotes",
+			headline: `"Locum" ` + highlightStart + "Vet's" + highlightStop,
+			want:     "&#34;Locum&#34; <mark>Vet&#39;s</mark>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markHighlights(tt.headline); got != tt.want {
+				t.Errorf("markHighlights(%q) = %q, want %q", tt.headline, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFiltersPostcodeRadius(t *testing.T) {
+	tests := []struct {
+		name    string
+		near    string
+		radius  float64
+		wantErr bool
+	}{
+		{name: "postcode with a small radius", near: "LS6 3AB", radius: 5, wantErr: true},
+		{name: "district with a small radius", near: "LS6", radius: 10, wantErr: true},
+		{name: "area with a small radius", near: "LS", radius: 10, wantErr: true},
+		{name: "postcode with an area-sized radius", near: "LS6 3AB", radius: MinPostcodeAreaRadiusKm},
+		{name: "town with a small radius", near: "Leeds", radius: 5},
+		{name: "coordinates with a small radius", near: "53.80,-1.55", radius: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arg, err := ListParams{Near: tt.near, RadiusKm: tt.radius}.filters(geo.UK(), time.Now())
+			if tt.wantErr {
+				var invalid *ValidationError
+				if !errors.As(err, &invalid) || invalid.Fields["radius_km"] == "" {
+					t.Fatalf("got %v, want a radius_km problem", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !arg.RadiusKm.Valid || arg.RadiusKm.Float64 != tt.radius {
+				t.Errorf("RadiusKm = %+v, want %v", arg.RadiusKm, tt.radius)
+			}
+		})
+	}
+}
+
+func TestFiltersPostedWithin(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	arg, err := ListParams{PostedWithin: 7 * 24 * time.Hour}.filters(geo.UK(), now)
+	if err != nil {
+		t.Fatalf("filters: %v", err)
+	}
+	if want := now.AddDate(0, 0, -7); !arg.PostedAfter.Valid || !arg.PostedAfter.Time.Equal(want) {
+		t.Errorf("PostedAfter = %+v, want %v", arg.PostedAfter, want)
+	}
+}
@@ -11,9 +11,203 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/sqlc-dev/pqtype"
 )
 
+const consumeEmailVerificationToken = `-- name: ConsumeEmailVerificationToken :execrows
+UPDATE email_verification_tokens
+SET consumed_at = NOW()
+WHERE id = $1
+  AND consumed_at IS NULL
+`
+
+func (q *Queries) ConsumeEmailVerificationToken(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, consumeEmailVerificationToken, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const consumeMagicLinkToken = `-- name: ConsumeMagicLinkToken :execrows
+UPDATE magic_link_tokens
+SET consumed_at = NOW()
+WHERE id = $1
+  AND consumed_at IS NULL
+`
+
+func (q *Queries) ConsumeMagicLinkToken(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, consumeMagicLinkToken, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const consumePasswordResetToken = `-- name: ConsumePasswordResetToken :execrows
+UPDATE password_reset_tokens
+SET consumed_at = NOW()
+WHERE id = $1
+  AND consumed_at IS NULL
+`
+
+func (q *Queries) ConsumePasswordResetToken(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, consumePasswordResetToken, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const consumeUserSession = `-- name: ConsumeUserSession :execrows
+UPDATE user_sessions
+SET consumed_at = NOW()
+WHERE id = $1
+  AND consumed_at IS NULL
+`
+
+func (q *Queries) ConsumeUserSession(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, consumeUserSession, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countMagicLinkTokensSince = `-- name: CountMagicLinkTokensSince :one
+SELECT COUNT(*)
+FROM magic_link_tokens
+WHERE user_id = $1
+  AND created_at >= $2
+`
+
+type CountMagicLinkTokensSinceParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Since  time.Time `json:"since"`
+}
+
+func (q *Queries) CountMagicLinkTokensSince(ctx context.Context, arg CountMagicLinkTokensSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countMagicLinkTokensSince, arg.UserID, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createEmailVerificationToken = `-- name: CreateEmailVerificationToken :one
+INSERT INTO email_verification_tokens (
+    user_id,
+    email,
+    token_hash,
+    expires_at
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING id, user_id, email, token_hash, expires_at, consumed_at, created_at
+`
+
+type CreateEmailVerificationTokenParams struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	TokenHash string    `json:"token_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateEmailVerificationToken(ctx context.Context, arg CreateEmailVerificationTokenParams) (EmailVerificationToken, error) {
+	row := q.db.QueryRowContext(ctx, createEmailVerificationToken,
+		arg.UserID,
+		arg.Email,
+		arg.TokenHash,
+		arg.ExpiresAt,
+	)
+	var i EmailVerificationToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Email,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createMagicLinkToken = `-- name: CreateMagicLinkToken :one
+INSERT INTO magic_link_tokens (
+    user_id,
+    token_hash,
+    user_agent_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING id, user_id, token_hash, user_agent_hash, expires_at, consumed_at, created_at
+`
+
+type CreateMagicLinkTokenParams struct {
+	UserID        uuid.UUID      `json:"user_id"`
+	TokenHash     string         `json:"token_hash"`
+	UserAgentHash sql.NullString `json:"user_agent_hash"`
+	ExpiresAt     time.Time      `json:"expires_at"`
+}
+
+func (q *Queries) CreateMagicLinkToken(ctx context.Context, arg CreateMagicLinkTokenParams) (MagicLinkToken, error) {
+	row := q.db.QueryRowContext(ctx, createMagicLinkToken,
+		arg.UserID,
+		arg.TokenHash,
+		arg.UserAgentHash,
+		arg.ExpiresAt,
+	)
+	var i MagicLinkToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TokenHash,
+		&i.UserAgentHash,
+		&i.ExpiresAt,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createPasswordResetToken = `-- name: CreatePasswordResetToken :one
+INSERT INTO password_reset_tokens (
+    user_id,
+    token_hash,
+    expires_at
+) VALUES (
+    $1,
+    $2,
+    $3
+)
+RETURNING id, user_id, token_hash, expires_at, consumed_at, created_at
+`
+
+type CreatePasswordResetTokenParams struct {
+	UserID    uuid.UUID `json:"user_id"`
+	TokenHash string    `json:"token_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error) {
+	row := q.db.QueryRowContext(ctx, createPasswordResetToken, arg.UserID, arg.TokenHash, arg.ExpiresAt)
+	var i PasswordResetToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const createUserSession = `-- name: CreateUserSession :one
 
 INSERT INTO user_sessions (
@@ -21,15 +215,23 @@ INSERT INTO user_sessions (
     refresh_token_hash,
     user_agent,
     ip,
-    expires_at
+    expires_at,
+    family_id,
+    access_token_jti,
+    amr,
+    signed_in_at
 ) VALUES (
     $1,
     $2,
     $3,
     $4,
-    $5
+    $5,
+    $6,
+    $7,
+    $8,
+    $9
 )
-RETURNING id, user_id, refresh_token_hash, user_agent, ip, expires_at, created_at
+RETURNING id, user_id, refresh_token_hash, user_agent, ip, expires_at, created_at, family_id, consumed_at, access_token_jti, amr, signed_in_at
 `
 
 type CreateUserSessionParams struct {
@@ -38,6 +240,10 @@ type CreateUserSessionParams struct {
 	UserAgent        sql.NullString `json:"user_agent"`
 	Ip               pqtype.Inet    `json:"ip"`
 	ExpiresAt        time.Time      `json:"expires_at"`
+	FamilyID         uuid.UUID      `json:"family_id"`
+	AccessTokenJti   uuid.NullUUID  `json:"access_token_jti"`
+	Amr              []string       `json:"amr"`
+	SignedInAt       time.Time      `json:"signed_in_at"`
 }
 
 // User session management queries
@@ -48,6 +254,10 @@ func (q *Queries) CreateUserSession(ctx context.Context, arg CreateUserSessionPa
 		arg.UserAgent,
 		arg.Ip,
 		arg.ExpiresAt,
+		arg.FamilyID,
+		arg.AccessTokenJti,
+		pq.Array(arg.Amr),
+		arg.SignedInAt,
 	)
 	var i UserSession
 	err := row.Scan(
@@ -58,10 +268,83 @@ func (q *Queries) CreateUserSession(ctx context.Context, arg CreateUserSessionPa
 		&i.Ip,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.FamilyID,
+		&i.ConsumedAt,
+		&i.AccessTokenJti,
+		pq.Array(&i.Amr),
+		&i.SignedInAt,
 	)
 	return i, err
 }
 
+const deleteExpiredConsumedUserSessions = `-- name: DeleteExpiredConsumedUserSessions :exec
+DELETE FROM user_sessions
+WHERE family_id = $1
+  AND consumed_at IS NOT NULL
+  AND expires_at < $2
+`
+
+type DeleteExpiredConsumedUserSessionsParams struct {
+	FamilyID uuid.UUID `json:"family_id"`
+	Now      time.Time `json:"now"`
+}
+
+// Consumed rows are kept so replaying a rotated token is detected; once the token has expired
+// they are no longer needed.
+func (q *Queries) DeleteExpiredConsumedUserSessions(ctx context.Context, arg DeleteExpiredConsumedUserSessionsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredConsumedUserSessions, arg.FamilyID, arg.Now)
+	return err
+}
+
+const deleteOtherUserSessions = `-- name: DeleteOtherUserSessions :exec
+DELETE FROM user_sessions
+WHERE user_id = $1
+  AND family_id <> $2
+`
+
+type DeleteOtherUserSessionsParams struct {
+	UserID       uuid.UUID `json:"user_id"`
+	KeepFamilyID uuid.UUID `json:"keep_family_id"`
+}
+
+func (q *Queries) DeleteOtherUserSessions(ctx context.Context, arg DeleteOtherUserSessionsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteOtherUserSessions, arg.UserID, arg.KeepFamilyID)
+	return err
+}
+
+const deletePendingEmailVerificationTokens = `-- name: DeletePendingEmailVerificationTokens :exec
+DELETE FROM email_verification_tokens
+WHERE user_id = $1
+  AND consumed_at IS NULL
+`
+
+func (q *Queries) DeletePendingEmailVerificationTokens(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deletePendingEmailVerificationTokens, userID)
+	return err
+}
+
+const deletePendingMagicLinkTokens = `-- name: DeletePendingMagicLinkTokens :exec
+DELETE FROM magic_link_tokens
+WHERE user_id = $1
+  AND consumed_at IS NULL
+`
+
+func (q *Queries) DeletePendingMagicLinkTokens(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deletePendingMagicLinkTokens, userID)
+	return err
+}
+
+const deletePendingPasswordResetTokens = `-- name: DeletePendingPasswordResetTokens :exec
+DELETE FROM password_reset_tokens
+WHERE user_id = $1
+  AND consumed_at IS NULL
+`
+
+func (q *Queries) DeletePendingPasswordResetTokens(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deletePendingPasswordResetTokens, userID)
+	return err
+}
+
 const deleteUserSession = `-- name: DeleteUserSession :exec
 DELETE FROM user_sessions
 WHERE id = $1
@@ -72,6 +355,25 @@ func (q *Queries) DeleteUserSession(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const deleteUserSessionFamilyForUser = `-- name: DeleteUserSessionFamilyForUser :execrows
+DELETE FROM user_sessions
+WHERE family_id = $1
+  AND user_id = $2
+`
+
+type DeleteUserSessionFamilyForUserParams struct {
+	FamilyID uuid.UUID `json:"family_id"`
+	UserID   uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteUserSessionFamilyForUser(ctx context.Context, arg DeleteUserSessionFamilyForUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUserSessionFamilyForUser, arg.FamilyID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteUserSessionByHash = `-- name: DeleteUserSessionByHash :exec
 DELETE FROM user_sessions
 WHERE refresh_token_hash = $1
@@ -82,6 +384,16 @@ func (q *Queries) DeleteUserSessionByHash(ctx context.Context, refreshTokenHash
 	return err
 }
 
+const deleteUserSessionsByFamilyID = `-- name: DeleteUserSessionsByFamilyID :exec
+DELETE FROM user_sessions
+WHERE family_id = $1
+`
+
+func (q *Queries) DeleteUserSessionsByFamilyID(ctx context.Context, familyID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUserSessionsByFamilyID, familyID)
+	return err
+}
+
 const deleteUserSessionsByUserID = `-- name: DeleteUserSessionsByUserID :exec
 DELETE FROM user_sessions
 WHERE user_id = $1
@@ -92,8 +404,97 @@ func (q *Queries) DeleteUserSessionsByUserID(ctx context.Context, userID uuid.UU
 	return err
 }
 
+const getEmailVerificationTokenByHash = `-- name: GetEmailVerificationTokenByHash :one
+SELECT id, user_id, email, token_hash, expires_at, consumed_at, created_at
+FROM email_verification_tokens
+WHERE token_hash = $1
+LIMIT 1
+`
+
+func (q *Queries) GetEmailVerificationTokenByHash(ctx context.Context, tokenHash string) (EmailVerificationToken, error) {
+	row := q.db.QueryRowContext(ctx, getEmailVerificationTokenByHash, tokenHash)
+	var i EmailVerificationToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Email,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getMagicLinkTokenByHash = `-- name: GetMagicLinkTokenByHash :one
+SELECT id, user_id, token_hash, user_agent_hash, expires_at, consumed_at, created_at
+FROM magic_link_tokens
+WHERE token_hash = $1
+LIMIT 1
+`
+
+func (q *Queries) GetMagicLinkTokenByHash(ctx context.Context, tokenHash string) (MagicLinkToken, error) {
+	row := q.db.QueryRowContext(ctx, getMagicLinkTokenByHash, tokenHash)
+	var i MagicLinkToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TokenHash,
+		&i.UserAgentHash,
+		&i.ExpiresAt,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPasswordResetTokenByHash = `-- name: GetPasswordResetTokenByHash :one
+SELECT id, user_id, token_hash, expires_at, consumed_at, created_at
+FROM password_reset_tokens
+WHERE token_hash = $1
+LIMIT 1
+`
+
+func (q *Queries) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (PasswordResetToken, error) {
+	row := q.db.QueryRowContext(ctx, getPasswordResetTokenByHash, tokenHash)
+	var i PasswordResetToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPendingEmailChange = `-- name: GetPendingEmailChange :one
+SELECT t.email
+FROM email_verification_tokens t
+JOIN users u ON u.id = t.user_id
+WHERE t.user_id = $1
+  AND t.consumed_at IS NULL
+  AND t.expires_at > $2
+  AND t.email <> u.email
+ORDER BY t.created_at DESC
+LIMIT 1
+`
+
+type GetPendingEmailChangeParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Now    time.Time `json:"now"`
+}
+
+func (q *Queries) GetPendingEmailChange(ctx context.Context, arg GetPendingEmailChangeParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getPendingEmailChange, arg.UserID, arg.Now)
+	var email string
+	err := row.Scan(&email)
+	return email, err
+}
+
 const getUserSessionByHash = `-- name: GetUserSessionByHash :one
-SELECT id, user_id, refresh_token_hash, user_agent, ip, expires_at, created_at
+SELECT id, user_id, refresh_token_hash, user_agent, ip, expires_at, created_at, family_id, consumed_at, access_token_jti, amr, signed_in_at
 FROM user_sessions
 WHERE refresh_token_hash = $1
 LIMIT 1
@@ -110,6 +511,89 @@ func (q *Queries) GetUserSessionByHash(ctx context.Context, refreshTokenHash str
 		&i.Ip,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.FamilyID,
+		&i.ConsumedAt,
+		&i.AccessTokenJti,
+		pq.Array(&i.Amr),
+		&i.SignedInAt,
 	)
 	return i, err
 }
+
+const getUserSessionFamilySignedInAt = `-- name: GetUserSessionFamilySignedInAt :one
+SELECT signed_in_at
+FROM user_sessions
+WHERE family_id = $1
+  AND user_id = $2
+LIMIT 1
+`
+
+type GetUserSessionFamilySignedInAtParams struct {
+	FamilyID uuid.UUID `json:"family_id"`
+	UserID   uuid.UUID `json:"user_id"`
+}
+
+// Every row of a family carries the family's sign-in time.
+func (q *Queries) GetUserSessionFamilySignedInAt(ctx context.Context, arg GetUserSessionFamilySignedInAtParams) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getUserSessionFamilySignedInAt, arg.FamilyID, arg.UserID)
+	var signed_in_at time.Time
+	err := row.Scan(&signed_in_at)
+	return signed_in_at, err
+}
+
+const listActiveUserSessions = `-- name: ListActiveUserSessions :many
+SELECT
+    s.id,
+    s.family_id,
+    s.user_agent,
+    s.ip,
+    s.expires_at,
+    s.created_at AS last_used_at,
+    s.signed_in_at
+FROM user_sessions s
+WHERE s.user_id = $1
+  AND s.consumed_at IS NULL
+  AND s.expires_at > NOW()
+ORDER BY s.created_at DESC
+`
+
+type ListActiveUserSessionsRow struct {
+	ID         uuid.UUID      `json:"id"`
+	FamilyID   uuid.UUID      `json:"family_id"`
+	UserAgent  sql.NullString `json:"user_agent"`
+	Ip         pqtype.Inet    `json:"ip"`
+	ExpiresAt  time.Time      `json:"expires_at"`
+	LastUsedAt time.Time      `json:"last_used_at"`
+	SignedInAt time.Time      `json:"signed_in_at"`
+}
+
+func (q *Queries) ListActiveUserSessions(ctx context.Context, userID uuid.UUID) ([]ListActiveUserSessionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveUserSessions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListActiveUserSessionsRow
+	for rows.Next() {
+		var i ListActiveUserSessionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FamilyID,
+			&i.UserAgent,
+			&i.Ip,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.SignedInAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -14,6 +14,129 @@ import (
 	"github.com/lib/pq"
 )
 
+const countPublishedJobs = `-- name: CountPublishedJobs :one
+WITH search AS (
+    SELECT websearch_to_tsquery('english', $1::text) AS query
+)
+SELECT COUNT(*)
+FROM jobs j
+LEFT JOIN job_locations jl ON jl.id = j.location_id
+-- Great-circle distance in km by the haversine formula; NULL without a near point or
+-- without coordinates for the job's location.
+LEFT JOIN LATERAL (
+    SELECT 2 * 6371 * asin(LEAST(1, sqrt(
+        power(sin(radians(jl.latitude::float8 - $2::float8) / 2), 2)
+        + cos(radians($2::float8)) * cos(radians(jl.latitude::float8))
+        * power(sin(radians(jl.longitude::float8 - $3::float8) / 2), 2)
+    ))) AS distance_km
+) d ON true
+CROSS JOIN search s
+WHERE j.status = 'published'
+  AND (
+        s.query IS NULL
+        OR numnode(s.query) = 0
+        OR j.search_vector @@ s.query
+    )
+  AND (
+        $4::text[] IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM unnest($4::text[]) AS value
+            WHERE jl.country ILIKE '%' || value || '%'
+        )
+    )
+  AND (
+        $5::text[] IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM unnest($5::text[]) AS value
+            WHERE jl.region ILIKE '%' || value || '%'
+            OR jl.city ILIKE '%' || value || '%'
+        )
+    )
+  AND (
+        $6::text[] IS NULL
+        OR j.contract_type = ANY($6::text[])
+    )
+  AND (
+        $7::text[] IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM unnest($7::text[]) AS cat
+            WHERE (
+                cat = 'Vet' AND (j.title ILIKE '%Vet%' OR j.title ILIKE '%Surgeon%') AND j.title NOT ILIKE '%Nurse%'
+            ) OR (
+                cat = 'Nurse' AND (j.title ILIKE '%Nurse%' OR j.title ILIKE '%RVN%' OR j.title ILIKE '%SVN%')
+            )
+        )
+    )
+  AND (
+        $8::text[] IS NULL
+        OR j.work_pattern = ANY($8::text[])
+    )
+  -- Jobs without a currency are imported UK listings quoted in pounds.
+  AND (
+        $9::text IS NULL
+        OR COALESCE(j.currency, 'GBP') = $9::text
+    )
+  -- Salary bands match when they overlap the requested range.
+  AND (
+        $10::integer IS NULL
+        OR COALESCE(j.salary_max, j.salary_min) >= $10::integer
+    )
+  AND (
+        $11::integer IS NULL
+        OR COALESCE(j.salary_min, j.salary_max) <= $11::integer
+    )
+  AND (
+        $12::timestamptz IS NULL
+        OR j.posted_at >= $12::timestamptz
+    )
+  AND (
+        $13::float8 IS NULL
+        OR d.distance_km <= $13::float8
+    )
+`
+
+type CountPublishedJobsParams struct {
+	Search        sql.NullString  `json:"search"`
+	NearLatitude  sql.NullFloat64 `json:"near_latitude"`
+	NearLongitude sql.NullFloat64 `json:"near_longitude"`
+	Countries     []string        `json:"countries"`
+	Regions       []string        `json:"regions"`
+	ContractTypes []string        `json:"contract_types"`
+	Categories    []string        `json:"categories"`
+	WorkPatterns  []string        `json:"work_patterns"`
+	Currency      sql.NullString  `json:"currency"`
+	SalaryMin     sql.NullInt32   `json:"salary_min"`
+	SalaryMax     sql.NullInt32   `json:"salary_max"`
+	PostedAfter   sql.NullTime    `json:"posted_after"`
+	RadiusKm      sql.NullFloat64 `json:"radius_km"`
+}
+
+// Counts the jobs ListPublishedJobs pages through for the same filters. It is a separate query
+// so pages that skip the total do not pay for visiting every match.
+func (q *Queries) CountPublishedJobs(ctx context.Context, arg CountPublishedJobsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPublishedJobs,
+		arg.Search,
+		arg.NearLatitude,
+		arg.NearLongitude,
+		pq.Array(arg.Countries),
+		pq.Array(arg.Regions),
+		pq.Array(arg.ContractTypes),
+		pq.Array(arg.Categories),
+		pq.Array(arg.WorkPatterns),
+		arg.Currency,
+		arg.SalaryMin,
+		arg.SalaryMax,
+		arg.PostedAfter,
+		arg.RadiusKm,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createJob = `-- name: CreateJob :one
 INSERT INTO jobs (
     title,
@@ -48,7 +171,7 @@ INSERT INTO jobs (
     $14::timestamptz,
     $15::timestamptz
 )
-RETURNING id, title, slug, summary, description, location_id, contract_type, work_pattern, salary_min, salary_max, currency, status, source, source_ref, posted_at, expires_at, created_at, updated_at
+RETURNING id, title, slug, summary, description, location_id, contract_type, work_pattern, salary_min, salary_max, currency, status, source, source_ref, posted_at, expires_at, created_at, updated_at, search_vector
 `
 
 type CreateJobParams struct {
@@ -107,6 +230,7 @@ func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, erro
 		&i.ExpiresAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SearchVector,
 	)
 	return i, err
 }
@@ -241,119 +365,326 @@ func (q *Queries) GetJobBySlug(ctx context.Context, slug string) (GetJobBySlugRo
 	return i, err
 }
 
+const listJobLocationsMissingCoordinates = `-- name: ListJobLocationsMissingCoordinates :many
+SELECT id, country, region, city
+FROM job_locations
+WHERE latitude IS NULL OR longitude IS NULL
+ORDER BY id
+`
+
+type ListJobLocationsMissingCoordinatesRow struct {
+	ID      int64          `json:"id"`
+	Country string         `json:"country"`
+	Region  sql.NullString `json:"region"`
+	City    sql.NullString `json:"city"`
+}
+
+func (q *Queries) ListJobLocationsMissingCoordinates(ctx context.Context) ([]ListJobLocationsMissingCoordinatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listJobLocationsMissingCoordinates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListJobLocationsMissingCoordinatesRow
+	for rows.Next() {
+		var i ListJobLocationsMissingCoordinatesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Country,
+			&i.Region,
+			&i.City,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listPublishedJobs = `-- name: ListPublishedJobs :many
-SELECT
-    j.id,
-    j.title,
-    j.slug,
-    j.summary,
-    j.description,
-    j.location_id,
-    j.contract_type,
-    j.work_pattern,
-    j.salary_min,
-    j.salary_max,
-    j.currency,
-    j.status,
-    j.source,
-    j.source_ref,
-    j.posted_at,
-    j.expires_at,
-    j.created_at,
-    j.updated_at,
-    jl.country,
-    jl.region,
-    jl.city,
-    COUNT(*) OVER() AS total_count
-FROM jobs j
-LEFT JOIN job_locations jl ON jl.id = j.location_id
-WHERE j.status = 'published'
-  AND (
-        $1::text IS NULL
-        OR j.title ILIKE '%' || $1::text || '%'
-        OR j.summary ILIKE '%' || $1::text || '%'
-        OR jl.city ILIKE '%' || $1::text || '%'
-        OR jl.country ILIKE '%' || $1::text || '%'
-    )
-  AND (
-        $2::text[] IS NULL
-        OR EXISTS (
-            SELECT 1
-            FROM unnest($2::text[]) AS value
-            WHERE jl.country ILIKE '%' || value || '%'
+WITH search AS (
+    SELECT websearch_to_tsquery('english', $1::text) AS query
+), matches AS (
+    SELECT
+        j.id,
+        j.title,
+        j.slug,
+        j.summary,
+        j.description,
+        j.location_id,
+        j.contract_type,
+        j.work_pattern,
+        j.salary_min,
+        j.salary_max,
+        j.currency,
+        j.status,
+        j.source,
+        j.source_ref,
+        j.posted_at,
+        j.expires_at,
+        j.created_at,
+        j.updated_at,
+        jl.country,
+        jl.region,
+        jl.city,
+        d.distance_km,
+        COALESCE(CASE $2::text
+            WHEN 'relevance' THEN COALESCE(ts_rank_cd(j.search_vector, s.query), 0)::float8
+            WHEN 'salary_desc' THEN COALESCE(j.salary_max, j.salary_min)::float8
+            WHEN 'salary_asc' THEN -COALESCE(j.salary_min, j.salary_max)::float8
+            WHEN 'distance' THEN -d.distance_km
+            ELSE 0
+        END, '-infinity'::float8) AS sort_value,
+        COALESCE(j.posted_at, '-infinity'::timestamptz) AS sort_posted_at
+    FROM jobs j
+    LEFT JOIN job_locations jl ON jl.id = j.location_id
+    -- Great-circle distance in km by the haversine formula; NULL without a near point or
+    -- without coordinates for the job's location.
+    LEFT JOIN LATERAL (
+        SELECT 2 * 6371 * asin(LEAST(1, sqrt(
+            power(sin(radians(jl.latitude::float8 - $3::float8) / 2), 2)
+            + cos(radians($3::float8)) * cos(radians(jl.latitude::float8))
+            * power(sin(radians(jl.longitude::float8 - $4::float8) / 2), 2)
+        ))) AS distance_km
+    ) d ON true
+    CROSS JOIN search s
+    WHERE j.status = 'published'
+      AND (
+            s.query IS NULL
+            OR numnode(s.query) = 0
+            OR j.search_vector @@ s.query
         )
-    )
-  AND (
-        $3::text[] IS NULL
-        OR EXISTS (
-            SELECT 1
-            FROM unnest($3::text[]) AS value
-            WHERE jl.region ILIKE '%' || value || '%'
-            OR jl.city ILIKE '%' || value || '%'
+      AND (
+            $5::text[] IS NULL
+            OR EXISTS (
+                SELECT 1
+                FROM unnest($5::text[]) AS value
+                WHERE jl.country ILIKE '%' || value || '%'
+            )
+        )
+      AND (
+            $6::text[] IS NULL
+            OR EXISTS (
+                SELECT 1
+                FROM unnest($6::text[]) AS value
+                WHERE jl.region ILIKE '%' || value || '%'
+                OR jl.city ILIKE '%' || value || '%'
+            )
+        )
+      AND (
+            $7::text[] IS NULL
+            OR j.contract_type = ANY($7::text[])
+        )
+      AND (
+            $8::text[] IS NULL
+            OR EXISTS (
+                SELECT 1
+                FROM unnest($8::text[]) AS cat
+                WHERE (
+                    cat = 'Vet' AND (j.title ILIKE '%Vet%' OR j.title ILIKE '%Surgeon%') AND j.title NOT ILIKE '%Nurse%'
+                ) OR (
+                    cat = 'Nurse' AND (j.title ILIKE '%Nurse%' OR j.title ILIKE '%RVN%' OR j.title ILIKE '%SVN%')
+                )
+            )
+        )
+      AND (
+            $9::text[] IS NULL
+            OR j.work_pattern = ANY($9::text[])
+        )
+      -- Jobs without a currency are imported UK listings quoted in pounds.
+      AND (
+            $10::text IS NULL
+            OR COALESCE(j.currency, 'GBP') = $10::text
+        )
+      -- Salary bands match when they overlap the requested range.
+      AND (
+            $11::integer IS NULL
+            OR COALESCE(j.salary_max, j.salary_min) >= $11::integer
+        )
+      AND (
+            $12::integer IS NULL
+            OR COALESCE(j.salary_min, j.salary_max) <= $12::integer
+        )
+      AND (
+            $13::timestamptz IS NULL
+            OR j.posted_at >= $13::timestamptz
+        )
+      AND (
+            $14::float8 IS NULL
+            OR d.distance_km <= $14::float8
+        )
+)
+SELECT
+    m.id,
+    m.title,
+    m.slug,
+    m.summary,
+    m.description,
+    m.location_id,
+    m.contract_type,
+    m.work_pattern,
+    m.salary_min,
+    m.salary_max,
+    m.currency,
+    m.status,
+    m.source,
+    m.source_ref,
+    m.posted_at,
+    m.expires_at,
+    m.created_at,
+    m.updated_at,
+    m.country,
+    m.region,
+    m.city,
+    -- Matches are delimited by the private-use characters U+E000 and U+E001, stripped from the
+    -- text beforehand, and the caller swaps them for tags after HTML-escaping the headline.
+    ts_headline('english', translate(m.title, E'\uE000\uE001', ''), s.query,
+        E'HighlightAll=true, StartSel=\uE000, StopSel=\uE001') AS title_highlight,
+    ts_headline('english', translate(concat_ws(' ', m.summary, regexp_replace(m.description, '<[^>]*>', ' ', 'g')), E'\uE000\uE001', ''), s.query,
+        E'StartSel=\uE000, StopSel=\uE001, MaxFragments=2, MaxWords=30, MinWords=10, FragmentDelimiter=" … "') AS snippet,
+    m.distance_km,
+    m.sort_value
+FROM matches m
+CROSS JOIN search s
+WHERE $15::uuid IS NULL
+    -- Newest first leaves out sort_value, which is constant for that sort, so the comparison
+    -- follows the column order of idx_jobs_published_newest.
+    OR (
+        $2::text = 'newest'
+        AND (
+            (
+                (m.sort_posted_at, m.created_at, m.id) < (
+                    COALESCE($16::timestamptz, '-infinity'::timestamptz),
+                    $17::timestamptz,
+                    $15::uuid
+                ) AND NOT $18::bool
+            )
+            OR (
+                (m.sort_posted_at, m.created_at, m.id) > (
+                    COALESCE($16::timestamptz, '-infinity'::timestamptz),
+                    $17::timestamptz,
+                    $15::uuid
+                ) AND $18::bool
+            )
         )
     )
-  AND (
-        $4::text[] IS NULL
-        OR j.contract_type = ANY($4::text[])
-    )
-  AND (
-        $5::text[] IS NULL
-        OR EXISTS (
-            SELECT 1
-            FROM unnest($5::text[]) AS cat
-            WHERE (
-                cat = 'Vet' AND (j.title ILIKE '%Vet%' OR j.title ILIKE '%Surgeon%') AND j.title NOT ILIKE '%Nurse%'
-            ) OR (
-                cat = 'Nurse' AND (j.title ILIKE '%Nurse%' OR j.title ILIKE '%RVN%' OR j.title ILIKE '%SVN%')
+    OR (
+        $2::text <> 'newest'
+        AND (
+            (
+                (m.sort_value, m.sort_posted_at, m.created_at, m.id) < (
+                    COALESCE($19::float8, '-infinity'::float8),
+                    COALESCE($16::timestamptz, '-infinity'::timestamptz),
+                    $17::timestamptz,
+                    $15::uuid
+                ) AND NOT $18::bool
+            )
+            OR (
+                (m.sort_value, m.sort_posted_at, m.created_at, m.id) > (
+                    COALESCE($19::float8, '-infinity'::float8),
+                    COALESCE($16::timestamptz, '-infinity'::timestamptz),
+                    $17::timestamptz,
+                    $15::uuid
+                ) AND $18::bool
             )
         )
     )
-ORDER BY j.posted_at DESC NULLS LAST, j.created_at DESC
-LIMIT $7::int OFFSET $6::int
+ORDER BY
+    CASE WHEN $18::bool THEN m.sort_value END ASC,
+    CASE WHEN $18::bool THEN m.sort_posted_at END ASC,
+    CASE WHEN $18::bool THEN m.created_at END ASC,
+    CASE WHEN $18::bool THEN m.id END ASC,
+    m.sort_value DESC,
+    m.sort_posted_at DESC,
+    m.created_at DESC,
+    m.id DESC
+LIMIT $21::int OFFSET $20::int
 `
 
 type ListPublishedJobsParams struct {
-	Search        sql.NullString `json:"search"`
-	Countries     []string       `json:"countries"`
-	Regions       []string       `json:"regions"`
-	ContractTypes []string       `json:"contract_types"`
-	Categories    []string       `json:"categories"`
-	OffsetRows    int32          `json:"offset_rows"`
-	LimitRows     int32          `json:"limit_rows"`
+	Search          sql.NullString  `json:"search"`
+	Sort            string          `json:"sort"`
+	NearLatitude    sql.NullFloat64 `json:"near_latitude"`
+	NearLongitude   sql.NullFloat64 `json:"near_longitude"`
+	Countries       []string        `json:"countries"`
+	Regions         []string        `json:"regions"`
+	ContractTypes   []string        `json:"contract_types"`
+	Categories      []string        `json:"categories"`
+	WorkPatterns    []string        `json:"work_patterns"`
+	Currency        sql.NullString  `json:"currency"`
+	SalaryMin       sql.NullInt32   `json:"salary_min"`
+	SalaryMax       sql.NullInt32   `json:"salary_max"`
+	PostedAfter     sql.NullTime    `json:"posted_after"`
+	RadiusKm        sql.NullFloat64 `json:"radius_km"`
+	CursorID        uuid.NullUUID   `json:"cursor_id"`
+	CursorPostedAt  sql.NullTime    `json:"cursor_posted_at"`
+	CursorCreatedAt sql.NullTime    `json:"cursor_created_at"`
+	Backward        bool            `json:"backward"`
+	CursorSortValue sql.NullFloat64 `json:"cursor_sort_value"`
+	OffsetRows      int32           `json:"offset_rows"`
+	LimitRows       int32           `json:"limit_rows"`
 }
 
 type ListPublishedJobsRow struct {
-	ID           uuid.UUID      `json:"id"`
-	Title        string         `json:"title"`
-	Slug         string         `json:"slug"`
-	Summary      sql.NullString `json:"summary"`
-	Description  string         `json:"description"`
-	LocationID   sql.NullInt64  `json:"location_id"`
-	ContractType sql.NullString `json:"contract_type"`
-	WorkPattern  sql.NullString `json:"work_pattern"`
-	SalaryMin    sql.NullInt32  `json:"salary_min"`
-	SalaryMax    sql.NullInt32  `json:"salary_max"`
-	Currency     sql.NullString `json:"currency"`
-	Status       string         `json:"status"`
-	Source       sql.NullString `json:"source"`
-	SourceRef    sql.NullString `json:"source_ref"`
-	PostedAt     sql.NullTime   `json:"posted_at"`
-	ExpiresAt    sql.NullTime   `json:"expires_at"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	Country      sql.NullString `json:"country"`
-	Region       sql.NullString `json:"region"`
-	City         sql.NullString `json:"city"`
-	TotalCount   int64          `json:"total_count"`
+	ID             uuid.UUID       `json:"id"`
+	Title          string          `json:"title"`
+	Slug           string          `json:"slug"`
+	Summary        sql.NullString  `json:"summary"`
+	Description    string          `json:"description"`
+	LocationID     sql.NullInt64   `json:"location_id"`
+	ContractType   sql.NullString  `json:"contract_type"`
+	WorkPattern    sql.NullString  `json:"work_pattern"`
+	SalaryMin      sql.NullInt32   `json:"salary_min"`
+	SalaryMax      sql.NullInt32   `json:"salary_max"`
+	Currency       sql.NullString  `json:"currency"`
+	Status         string          `json:"status"`
+	Source         sql.NullString  `json:"source"`
+	SourceRef      sql.NullString  `json:"source_ref"`
+	PostedAt       sql.NullTime    `json:"posted_at"`
+	ExpiresAt      sql.NullTime    `json:"expires_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	Country        sql.NullString  `json:"country"`
+	Region         sql.NullString  `json:"region"`
+	City           sql.NullString  `json:"city"`
+	TitleHighlight sql.NullString  `json:"title_highlight"`
+	Snippet        sql.NullString  `json:"snippet"`
+	DistanceKm     sql.NullFloat64 `json:"distance_km"`
+	SortValue      float64         `json:"sort_value"`
 }
 
+// Jobs are ordered by (sort_value, posted_at, created_at, id), all descending, so a page can
+// start after (or, with backward set, before) the cursor row. sort_value depends on the sort:
+// the search relevance, the salary (negated when ascending), the negated distance from the
+// near point, or 0 when sorting by date; jobs without a salary or location sort last at
+// -infinity. CountPublishedJobs counts the same matches when a total is asked for.
 func (q *Queries) ListPublishedJobs(ctx context.Context, arg ListPublishedJobsParams) ([]ListPublishedJobsRow, error) {
 	rows, err := q.db.QueryContext(ctx, listPublishedJobs,
 		arg.Search,
+		arg.Sort,
+		arg.NearLatitude,
+		arg.NearLongitude,
 		pq.Array(arg.Countries),
 		pq.Array(arg.Regions),
 		pq.Array(arg.ContractTypes),
 		pq.Array(arg.Categories),
+		pq.Array(arg.WorkPatterns),
+		arg.Currency,
+		arg.SalaryMin,
+		arg.SalaryMax,
+		arg.PostedAfter,
+		arg.RadiusKm,
+		arg.CursorID,
+		arg.CursorPostedAt,
+		arg.CursorCreatedAt,
+		arg.Backward,
+		arg.CursorSortValue,
 		arg.OffsetRows,
 		arg.LimitRows,
 	)
@@ -386,7 +717,10 @@ func (q *Queries) ListPublishedJobs(ctx context.Context, arg ListPublishedJobsPa
 			&i.Country,
 			&i.Region,
 			&i.City,
-			&i.TotalCount,
+			&i.TitleHighlight,
+			&i.Snippet,
+			&i.DistanceKm,
+			&i.SortValue,
 		); err != nil {
 			return nil, err
 		}
@@ -400,3 +734,21 @@ func (q *Queries) ListPublishedJobs(ctx context.Context, arg ListPublishedJobsPa
 	}
 	return items, nil
 }
+
+const setJobLocationCoordinates = `-- name: SetJobLocationCoordinates :exec
+UPDATE job_locations
+SET latitude = $1::float8,
+    longitude = $2::float8
+WHERE id = $3
+`
+
+type SetJobLocationCoordinatesParams struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	ID        int64   `json:"id"`
+}
+
+func (q *Queries) SetJobLocationCoordinates(ctx context.Context, arg SetJobLocationCoordinatesParams) error {
+	_, err := q.db.ExecContext(ctx, setJobLocationCoordinates, arg.Latitude, arg.Longitude, arg.ID)
+	return err
+}
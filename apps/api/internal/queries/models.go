@@ -6,6 +6,7 @@ package queries
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,6 +25,19 @@ type Announcement struct {
 	UpdatedAt time.Time     `json:"updated_at"`
 }
 
+type ApiKey struct {
+	ID         uuid.UUID     `json:"id"`
+	Name       string        `json:"name"`
+	Prefix     string        `json:"prefix"`
+	SecretHash string        `json:"secret_hash"`
+	Scopes     []string      `json:"scopes"`
+	CreatedBy  uuid.NullUUID `json:"created_by"`
+	ExpiresAt  sql.NullTime  `json:"expires_at"`
+	LastUsedAt sql.NullTime  `json:"last_used_at"`
+	RevokedAt  sql.NullTime  `json:"revoked_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
 type ApplicationEvent struct {
 	ID            uuid.UUID      `json:"id"`
 	ApplicationID uuid.UUID      `json:"application_id"`
@@ -34,6 +48,52 @@ type ApplicationEvent struct {
 	CreatedAt     time.Time      `json:"created_at"`
 }
 
+type AuditEvent struct {
+	ID         uuid.UUID       `json:"id"`
+	ActorID    uuid.NullUUID   `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   uuid.NullUUID   `json:"target_id"`
+	Metadata   json.RawMessage `json:"metadata"`
+	Ip         pqtype.Inet     `json:"ip"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type EmailVerificationToken struct {
+	ID         uuid.UUID    `json:"id"`
+	UserID     uuid.UUID    `json:"user_id"`
+	Email      string       `json:"email"`
+	TokenHash  string       `json:"token_hash"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	ConsumedAt sql.NullTime `json:"consumed_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type ErasureRequest struct {
+	ID          uuid.UUID      `json:"id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	Status      string         `json:"status"`
+	Reason      sql.NullString `json:"reason"`
+	RequestedAt time.Time      `json:"requested_at"`
+	DueAt       time.Time      `json:"due_at"`
+	ReviewedBy  uuid.NullUUID  `json:"reviewed_by"`
+	ReviewNote  sql.NullString `json:"review_note"`
+	ReviewedAt  sql.NullTime   `json:"reviewed_at"`
+}
+
+type ImpersonationSession struct {
+	ID           uuid.UUID      `json:"id"`
+	ActorID      uuid.UUID      `json:"actor_id"`
+	TargetUserID uuid.UUID      `json:"target_user_id"`
+	Reason       string         `json:"reason"`
+	Jti          uuid.UUID      `json:"jti"`
+	Ip           pqtype.Inet    `json:"ip"`
+	UserAgent    sql.NullString `json:"user_agent"`
+	StartedAt    time.Time      `json:"started_at"`
+	ExpiresAt    time.Time      `json:"expires_at"`
+	EndedAt      sql.NullTime   `json:"ended_at"`
+}
+
 type IngestionJob struct {
 	ID           uuid.UUID             `json:"id"`
 	Source       string                `json:"source"`
@@ -63,12 +123,13 @@ type Job struct {
 	ExpiresAt    sql.NullTime   `json:"expires_at"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
+	SearchVector interface{}    `json:"search_vector"`
 }
 
 type JobApplication struct {
 	ID          uuid.UUID             `json:"id"`
 	JobID       uuid.UUID             `json:"job_id"`
-	UserID      uuid.UUID             `json:"user_id"`
+	UserID      uuid.NullUUID         `json:"user_id"`
 	Status      string                `json:"status"`
 	CoverLetter sql.NullString        `json:"cover_letter"`
 	Metadata    pqtype.NullRawMessage `json:"metadata"`
@@ -97,6 +158,62 @@ type JobTag struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type LoginThrottle struct {
+	Scope         string       `json:"scope"`
+	Key           string       `json:"key"`
+	Failures      int32        `json:"failures"`
+	LastFailureAt time.Time    `json:"last_failure_at"`
+	LockedUntil   sql.NullTime `json:"locked_until"`
+}
+
+type MagicLinkToken struct {
+	ID            uuid.UUID      `json:"id"`
+	UserID        uuid.UUID      `json:"user_id"`
+	TokenHash     string         `json:"token_hash"`
+	UserAgentHash sql.NullString `json:"user_agent_hash"`
+	ExpiresAt     time.Time      `json:"expires_at"`
+	ConsumedAt    sql.NullTime   `json:"consumed_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+type MfaChallenge struct {
+	ID         uuid.UUID    `json:"id"`
+	UserID     uuid.UUID    `json:"user_id"`
+	TokenHash  string       `json:"token_hash"`
+	Attempts   int32        `json:"attempts"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	ConsumedAt sql.NullTime `json:"consumed_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+	Amr        []string     `json:"amr"`
+}
+
+type MfaRecoveryCode struct {
+	ID        uuid.UUID    `json:"id"`
+	UserID    uuid.UUID    `json:"user_id"`
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type OidcAuthRequest struct {
+	ID           uuid.UUID `json:"id"`
+	Provider     string    `json:"provider"`
+	StateHash    string    `json:"state_hash"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type PasswordResetToken struct {
+	ID         uuid.UUID    `json:"id"`
+	UserID     uuid.UUID    `json:"user_id"`
+	TokenHash  string       `json:"token_hash"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	ConsumedAt sql.NullTime `json:"consumed_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
 type Resource struct {
 	ID          uuid.UUID      `json:"id"`
 	Title       string         `json:"title"`
@@ -111,13 +228,53 @@ type Resource struct {
 	UpdatedAt   time.Time      `json:"updated_at"`
 }
 
+type RevokedAccessToken struct {
+	Jti       uuid.UUID `json:"jti"`
+	UserID    uuid.UUID `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+type StaffInvitation struct {
+	ID             uuid.UUID     `json:"id"`
+	Email          string        `json:"email"`
+	Role           string        `json:"role"`
+	TokenHash      string        `json:"token_hash"`
+	InvitedBy      uuid.NullUUID `json:"invited_by"`
+	ExpiresAt      time.Time     `json:"expires_at"`
+	AcceptedAt     sql.NullTime  `json:"accepted_at"`
+	AcceptedUserID uuid.NullUUID `json:"accepted_user_id"`
+	RevokedAt      sql.NullTime  `json:"revoked_at"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
 type User struct {
-	ID           uuid.UUID    `json:"id"`
-	Email        string       `json:"email"`
-	PasswordHash string       `json:"password_hash"`
-	Role         string       `json:"role"`
-	Status       string       `json:"status"`
-	LastLoginAt  sql.NullTime `json:"last_login_at"`
+	ID               uuid.UUID    `json:"id"`
+	Email            string       `json:"email"`
+	PasswordHash     string       `json:"password_hash"`
+	Role             string       `json:"role"`
+	Status           string       `json:"status"`
+	LastLoginAt      sql.NullTime `json:"last_login_at"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+	TokensValidAfter sql.NullTime `json:"tokens_valid_after"`
+}
+
+type UserIdentity struct {
+	ID          uuid.UUID      `json:"id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	Provider    string         `json:"provider"`
+	Subject     string         `json:"subject"`
+	Email       sql.NullString `json:"email"`
+	LastLoginAt sql.NullTime   `json:"last_login_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+type UserMfa struct {
+	UserID       uuid.UUID    `json:"user_id"`
+	TotpSecret   string       `json:"totp_secret"`
+	ConfirmedAt  sql.NullTime `json:"confirmed_at"`
+	LastUsedStep int64        `json:"last_used_step"`
 	CreatedAt    time.Time    `json:"created_at"`
 	UpdatedAt    time.Time    `json:"updated_at"`
 }
@@ -145,4 +302,9 @@ type UserSession struct {
 	Ip               pqtype.Inet    `json:"ip"`
 	ExpiresAt        time.Time      `json:"expires_at"`
 	CreatedAt        time.Time      `json:"created_at"`
+	FamilyID         uuid.UUID      `json:"family_id"`
+	ConsumedAt       sql.NullTime   `json:"consumed_at"`
+	AccessTokenJti   uuid.NullUUID  `json:"access_token_jti"`
+	Amr              []string       `json:"amr"`
+	SignedInAt       time.Time      `json:"signed_in_at"`
 }
@@ -8,10 +8,24 @@ package queries
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+const countUsersByRole = `-- name: CountUsersByRole :one
+SELECT COUNT(*)
+FROM users
+WHERE role = $1
+`
+
+func (q *Queries) CountUsersByRole(ctx context.Context, role string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsersByRole, role)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
     email,
@@ -24,7 +38,7 @@ INSERT INTO users (
     COALESCE($3::text, 'seeker'),
     COALESCE($4::text, 'active')
 )
-RETURNING id, email, password_hash, role, status, last_login_at, created_at, updated_at
+RETURNING id, email, password_hash, role, status, last_login_at, created_at, updated_at, tokens_valid_after
 `
 
 type CreateUserParams struct {
@@ -51,12 +65,23 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.LastLoginAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TokensValidAfter,
 	)
 	return i, err
 }
 
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, id)
+	return err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, password_hash, role, status, last_login_at, created_at, updated_at
+SELECT id, email, password_hash, role, status, last_login_at, created_at, updated_at, tokens_valid_after
 FROM users
 WHERE email = $1
 LIMIT 1
@@ -74,12 +99,13 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.LastLoginAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TokensValidAfter,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, email, password_hash, role, status, last_login_at, created_at, updated_at
+SELECT id, email, password_hash, role, status, last_login_at, created_at, updated_at, tokens_valid_after
 FROM users
 WHERE id = $1
 LIMIT 1
@@ -97,6 +123,128 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.LastLoginAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TokensValidAfter,
+	)
+	return i, err
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT
+    id,
+    email,
+    role,
+    status,
+    last_login_at,
+    created_at,
+    updated_at,
+    COUNT(*) OVER() AS total_count
+FROM users
+WHERE ($1::text IS NULL OR email ILIKE '%' || $1::text || '%')
+  AND ($2::text IS NULL OR role = $2::text)
+  AND ($3::text IS NULL OR status = $3::text)
+ORDER BY created_at DESC, id
+LIMIT $5::int OFFSET $4::int
+`
+
+type ListUsersParams struct {
+	Search     sql.NullString `json:"search"`
+	Role       sql.NullString `json:"role"`
+	Status     sql.NullString `json:"status"`
+	OffsetRows int32          `json:"offset_rows"`
+	LimitRows  int32          `json:"limit_rows"`
+}
+
+type ListUsersRow struct {
+	ID          uuid.UUID    `json:"id"`
+	Email       string       `json:"email"`
+	Role        string       `json:"role"`
+	Status      string       `json:"status"`
+	LastLoginAt sql.NullTime `json:"last_login_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	TotalCount  int64        `json:"total_count"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUsers,
+		arg.Search,
+		arg.Role,
+		arg.Status,
+		arg.OffsetRows,
+		arg.LimitRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUsersRow
+	for rows.Next() {
+		var i ListUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Role,
+			&i.Status,
+			&i.LastLoginAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setUserTokensValidAfter = `-- name: SetUserTokensValidAfter :exec
+UPDATE users
+SET tokens_valid_after = GREATEST(COALESCE(tokens_valid_after, $1), $1)
+WHERE id = $2
+`
+
+type SetUserTokensValidAfterParams struct {
+	ValidAfter sql.NullTime `json:"valid_after"`
+	ID         uuid.UUID    `json:"id"`
+}
+
+func (q *Queries) SetUserTokensValidAfter(ctx context.Context, arg SetUserTokensValidAfterParams) error {
+	_, err := q.db.ExecContext(ctx, setUserTokensValidAfter, arg.ValidAfter, arg.ID)
+	return err
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :one
+UPDATE users
+SET email = $1,
+    updated_at = NOW()
+WHERE id = $2
+RETURNING id, email, password_hash, role, status, last_login_at, created_at, updated_at, tokens_valid_after
+`
+
+type UpdateUserEmailParams struct {
+	Email string    `json:"email"`
+	ID    uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserEmail, arg.Email, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.Role,
+		&i.Status,
+		&i.LastLoginAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TokensValidAfter,
 	)
 	return i, err
 }
@@ -112,3 +260,80 @@ func (q *Queries) UpdateUserLastLogin(ctx context.Context, id uuid.UUID) error {
 	_, err := q.db.ExecContext(ctx, updateUserLastLogin, id)
 	return err
 }
+
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE users
+SET password_hash = $1,
+    updated_at = NOW()
+WHERE id = $2
+`
+
+type UpdateUserPasswordParams struct {
+	PasswordHash string    `json:"password_hash"`
+	ID           uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserPassword, arg.PasswordHash, arg.ID)
+	return err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $1,
+    updated_at = NOW()
+WHERE id = $2
+RETURNING id, email, password_hash, role, status, last_login_at, created_at, updated_at, tokens_valid_after
+`
+
+type UpdateUserRoleParams struct {
+	Role string    `json:"role"`
+	ID   uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserRole, arg.Role, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.Role,
+		&i.Status,
+		&i.LastLoginAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TokensValidAfter,
+	)
+	return i, err
+}
+
+const updateUserStatus = `-- name: UpdateUserStatus :one
+UPDATE users
+SET status = $1,
+    updated_at = NOW()
+WHERE id = $2
+RETURNING id, email, password_hash, role, status, last_login_at, created_at, updated_at, tokens_valid_after
+`
+
+type UpdateUserStatusParams struct {
+	Status string    `json:"status"`
+	ID     uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserStatus, arg.Status, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.Role,
+		&i.Status,
+		&i.LastLoginAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TokensValidAfter,
+	)
+	return i, err
+}
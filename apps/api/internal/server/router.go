@@ -8,6 +8,9 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/rs/zerolog"
+
+	"github.com/synergyvets/platform/internal/auth"
+	"github.com/synergyvets/platform/internal/clientip"
 )
 
 func newRouter(cfg Config) chi.Router {
@@ -19,7 +22,7 @@ func newRouter(cfg Config) chi.Router {
 	}
 
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(cfg.ClientIP.Middleware)
 	r.Use(requestLogger(cfg.Logger))
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(60 * time.Second))
@@ -38,11 +41,22 @@ func newRouter(cfg Config) chi.Router {
 		_, _ = w.Write([]byte(`{"status":"ok"}`))
 	})
 
+	if cfg.AuthHandler != nil {
+		r.Route("/.well-known", cfg.AuthHandler.WellKnownRoutes)
+	}
+
 	r.Route("/api/v1", func(r chi.Router) {
 		if cfg.AuthHandler != nil {
 			r.Route("/auth", cfg.AuthHandler.Routes)
 		}
 
+		if cfg.AuthHandler != nil {
+			r.Route("/me", func(r chi.Router) {
+				r.Use(cfg.AuthHandler.RequireUser)
+				cfg.AuthHandler.MeRoutes(r)
+			})
+		}
+
 		r.Route("/public", func(r chi.Router) {
 			if cfg.PublicJobs != nil {
 				cfg.PublicJobs.RegisterRoutes(r)
@@ -53,10 +67,18 @@ func newRouter(cfg Config) chi.Router {
 			r.Get("/articles", notImplemented)
 		})
 
+		if cfg.AuthHandler != nil {
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(cfg.AuthHandler.RequirePermission(auth.PermStaffAccess))
+				cfg.AuthHandler.AdminRoutes(r)
+			})
+		}
+
 		r.Route("/staff", func(r chi.Router) {
 			if cfg.AuthHandler != nil {
-				r.Use(cfg.AuthHandler.RequireStaff)
-				r.Get("/announcements", notImplemented)
+				r.Use(cfg.AuthHandler.RequirePermission(auth.PermStaffAccess))
+				r.With(cfg.AuthHandler.RequirePermission(auth.PermAnnouncementsRead)).Get("/announcements", notImplemented)
+				cfg.AuthHandler.StaffRoutes(r)
 			} else {
 				r.Get("/announcements", unauthorized)
 			}
@@ -101,7 +123,7 @@ func requestLogger(logger zerolog.Logger) func(http.Handler) http.Handler {
 				Str("path", r.URL.Path).
 				Int("status", status).
 				Dur("duration", duration).
-				Str("remote_ip", r.RemoteAddr).
+				Str("remote_ip", clientip.FromRequest(r)).
 				Str("proto", r.Proto).
 				Str("agent", r.UserAgent()).
 				Msg("http_request")
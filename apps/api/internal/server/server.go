@@ -7,6 +7,7 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/synergyvets/platform/internal/auth"
+	"github.com/synergyvets/platform/internal/clientip"
 	"github.com/synergyvets/platform/internal/public/jobs"
 )
 
@@ -15,6 +16,7 @@ type Config struct {
 	Addr           string
 	AllowedOrigins []string
 	Logger         zerolog.Logger
+	ClientIP       *clientip.Resolver
 	AuthHandler    *auth.Handler
 	PublicJobs     *jobs.Handler
 }